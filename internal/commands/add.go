@@ -2,6 +2,9 @@ package commands
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
@@ -17,14 +20,22 @@ var (
 	addAction      string
 	addVerify      string
 	addResult      string
+	addFields      []string
+	addVerifyCmd   string
+	addRecur       string
+	addTemplate    string
+	addVars        []string
 )
 
 var addCmd = &cobra.Command{
 	Use:   "add <name>",
 	Short: "Add a new task",
-	Long:  `Add a new task with the specified name and optional description.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runAdd,
+	Long: `Add a new task with the specified name and optional description.
+
+With --template, create the whole subtree described by .clipm/templates/<name>.yaml
+instead, substituting --var values and wiring up the template's dependencies.`,
+	Args: cobra.RangeArgs(0, 1),
+	RunE: runAdd,
 }
 
 func init() {
@@ -34,12 +45,28 @@ func init() {
 	addCmd.Flags().StringVar(&addAction, "action", "", "What concrete work to perform")
 	addCmd.Flags().StringVar(&addVerify, "verify", "", "How to confirm the action succeeded")
 	addCmd.Flags().StringVar(&addResult, "result", "", "Template for what to report back")
-	addCmd.MarkFlagRequired("action")
-	addCmd.MarkFlagRequired("verify")
-	addCmd.MarkFlagRequired("result")
+	addCmd.Flags().StringVar(&addVerifyCmd, "verify-cmd", "", "Shell command that checks the work (run by 'clipm verify')")
+	addCmd.Flags().StringVar(&addRecur, "recur", "", "Recurrence rule: on-done, an interval like 1d or 2w, or a cron expression")
+	addCmd.Flags().StringArrayVar(&addFields, "field", nil, "Custom field as key=value (repeatable)")
+	addCmd.Flags().StringVar(&addTemplate, "template", "", "Create a subtree from a template in .clipm/templates")
+	addCmd.Flags().StringArrayVar(&addVars, "var", nil, "Template variable as key=value (repeatable)")
 }
 
 func runAdd(cmd *cobra.Command, args []string) error {
+	if addTemplate != "" {
+		return runAddTemplate(args)
+	}
+
+	if len(args) != 1 {
+		return models.Errorf(models.CodeInvalidArgument, "add requires a task name (or --template)")
+	}
+	if addAction == "" || addVerify == "" || addResult == "" {
+		return models.Errorf(models.CodeInvalidArgument, `required flag(s) "action", "verify", "result" not set`)
+	}
+	if len(addVars) > 0 {
+		return models.Errorf(models.CodeInvalidArgument, "--var can only be used with --template")
+	}
+
 	// Get task name
 	name := args[0]
 
@@ -54,18 +81,41 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	if addParent != "" {
 		normalizedParent := models.NormalizeTaskID(addParent)
 		if !models.IsValidTaskID(normalizedParent) {
-			return fmt.Errorf("invalid parent task ID: %s", addParent)
+			return models.Errorf(models.CodeInvalidID, "invalid parent task ID: %s", addParent).With("id", addParent)
 		}
 		parentTask, err := store.LoadTask(normalizedParent)
 		if err != nil {
-			return fmt.Errorf("parent task %s not found", addParent)
+			return models.Errorf(models.CodeNotFound, "parent task %s not found", addParent).With("id", addParent)
 		}
-		if parentTask.Status == models.StatusDone {
-			return fmt.Errorf("cannot add child to done task")
+		if models.IsClosed(parentTask.Status) {
+			return models.Errorf(models.CodeConflict, "cannot add child to %s task", parentTask.Status)
 		}
 		parent = &normalizedParent
 	}
 
+	// Parse custom fields against the project schema
+	cfg, err := store.LoadConfig()
+	if err != nil {
+		return err
+	}
+	fields, err := parseFieldFlags(cfg.Fields, addFields)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Fields.Validate(fields); err != nil {
+		return err
+	}
+
+	// Parse the recurrence rule, scheduling the first occurrence from now
+	now := time.Now()
+	var recurrence *models.Recurrence
+	if addRecur != "" {
+		recurrence, err = models.ParseRecurrence(addRecur, now)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Generate new task ID
 	taskID, err := store.GenerateTaskID()
 	if err != nil {
@@ -73,7 +123,6 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create task
-	now := time.Now()
 	task := &models.Task{
 		ID:          taskID,
 		Name:        name,
@@ -81,8 +130,11 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		Action:      addAction,
 		Verify:      addVerify,
 		Result:      addResult,
+		VerifyCmd:   addVerifyCmd,
 		Parent:      parent,
 		Status:      models.StatusTodo,
+		Fields:      fields,
+		Recurrence:  recurrence,
 		Created:     now,
 		Updated:     now,
 	}
@@ -92,12 +144,80 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if addPretty {
+	// Without --output or --pretty, add prints just the new ID for scripts
+	if outputFlag == "" && !addPretty {
+		fmt.Println(task.ID)
+		return nil
+	}
+	return render(os.Stdout, outputMode(addPretty), task, func() {
 		green := color.New(color.FgGreen)
 		green.Printf("Created task %s: %s\n", task.ID, task.Name)
-	} else {
-		fmt.Println(task.ID)
+	})
+}
+
+// runAddTemplate expands a template and creates its tasks in one transaction
+func runAddTemplate(args []string) error {
+	if len(args) > 0 {
+		return models.Errorf(models.CodeInvalidArgument, "cannot combine a task name with --template")
+	}
+	if addDescription != "" || addAction != "" || addVerify != "" || addResult != "" ||
+		addVerifyCmd != "" || addRecur != "" || len(addFields) > 0 {
+		return models.Errorf(models.CodeInvalidArgument, "--template cannot be combined with task flags; set them in the template instead")
+	}
+
+	vars := make(map[string]string)
+	for _, arg := range addVars {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return models.Errorf(models.CodeInvalidArgument, "invalid --var %q: expected key=value", arg)
+		}
+		vars[strings.TrimSpace(key)] = value
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	var parent *string
+	if addParent != "" {
+		normalizedParent := models.NormalizeTaskID(addParent)
+		if !models.IsValidTaskID(normalizedParent) {
+			return models.Errorf(models.CodeInvalidID, "invalid parent task ID: %s", addParent).With("id", addParent)
+		}
+		parent = &normalizedParent
+	}
+
+	tmpl, err := store.LoadTemplate(addTemplate)
+	if err != nil {
+		if err == storage.ErrTemplateNotFound {
+			return models.Errorf(models.CodeNotFound, "template %s not found in %s", addTemplate, filepath.Join(storage.ClipmDir, storage.TemplatesDir)).
+				With("name", addTemplate)
+		}
+		return err
+	}
+
+	nodes, err := tmpl.Expand(vars)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	result, err := store.CreatePlan(nodes, parent)
+	if err != nil {
+		return err
+	}
+
+	if outputMode(addPretty) == outputPretty {
+		green := color.New(color.FgGreen)
+		green.Printf("Created %d task(s) from template %s\n", len(result.Created), tmpl.Name)
+		for _, id := range result.Created {
+			task, err := store.LoadTask(id)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("  %s: %s\n", task.ID, task.Name)
+		}
+		return nil
+	}
+	return render(os.Stdout, outputMode(addPretty), result, nil)
 }
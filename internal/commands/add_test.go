@@ -267,3 +267,26 @@ func TestAddCommand_RequiresStructuredFlags(t *testing.T) {
 	assert.Error(t, err)
 	// Cobra reports missing required flags
 }
+
+func TestAddCommand_VerifyCmd(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { addVerifyCmd = "" }()
+
+	addDescription = ""
+	addParent = ""
+	addPretty = false
+	addAction = "do something"
+	addVerify = "go test passes"
+	addResult = "report something"
+	addVerifyCmd = "go test ./..."
+
+	require.NoError(t, runAdd(nil, []string{"Checked task"}))
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "go test ./...", tasks[0].VerifyCmd)
+}
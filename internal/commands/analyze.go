@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	analyzePretty bool
+	analyzeLimit  int
+)
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Find critical paths and bottlenecks in open work",
+	Long: `Analyze the dependencies between open tasks:
+
+  - the critical path to each open top-level task: the longest chain of tasks
+    that must finish first, following blockers and children
+  - bottlenecks: the tasks that transitively block the most work
+  - unowned blockers: unclaimed tasks that are ready to start and block others
+
+Chains are weighted by the "estimate" custom field when any open task has one,
+and by task count otherwise.`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyze,
+}
+
+func init() {
+	analyzeCmd.Flags().BoolVar(&analyzePretty, "pretty", false, "Pretty print output")
+	analyzeCmd.Flags().IntVar(&analyzeLimit, "limit", 5, "Maximum bottlenecks and unowned blockers to report (0 for all)")
+}
+
+func runAnalyze(cmd *cobra.Command, args []string) error {
+	if analyzeLimit < 0 {
+		return models.Errorf(models.CodeInvalidArgument, "--limit cannot be negative")
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	tasks, err := store.LoadAll()
+	if err != nil {
+		return err
+	}
+
+	analysis := models.Analyze(tasks)
+	if analyzeLimit > 0 {
+		if len(analysis.Bottlenecks) > analyzeLimit {
+			analysis.Bottlenecks = analysis.Bottlenecks[:analyzeLimit]
+		}
+		if len(analysis.UnownedBlockers) > analyzeLimit {
+			analysis.UnownedBlockers = analysis.UnownedBlockers[:analyzeLimit]
+		}
+	}
+
+	return render(os.Stdout, outputMode(analyzePretty), analysis, func() {
+		printAnalysis(analysis, tasks)
+	})
+}
+
+func printAnalysis(analysis *models.Analysis, tasks []models.Task) {
+	yellow := color.New(color.FgYellow)
+	white := color.New(color.FgWhite)
+	gray := color.New(color.FgHiBlack)
+
+	names := make(map[string]string, len(tasks))
+	for i := range tasks {
+		names[tasks[i].ID] = tasks[i].Name
+	}
+	unit := "task(s)"
+	if analysis.Weighted {
+		unit = "estimated"
+	}
+
+	yellow.Println("Critical paths:")
+	if len(analysis.CriticalPaths) == 0 {
+		gray.Println("  No open tasks")
+	}
+	for _, cp := range analysis.CriticalPaths {
+		white.Printf("  %s - %s: %s %s\n", cp.Root, names[cp.Root], formatWeight(cp.Weight), unit)
+		gray.Printf("    %s\n", strings.Join(cp.Path, " -> "))
+	}
+
+	fmt.Println()
+	yellow.Println("Bottlenecks:")
+	printBottlenecks(analysis.Bottlenecks, unit)
+
+	fmt.Println()
+	yellow.Println("Unowned blockers:")
+	printBottlenecks(analysis.UnownedBlockers, unit)
+}
+
+func printBottlenecks(list []models.Bottleneck, unit string) {
+	white := color.New(color.FgWhite)
+	gray := color.New(color.FgHiBlack)
+
+	if len(list) == 0 {
+		gray.Println("  None")
+		return
+	}
+	for _, b := range list {
+		white.Printf("  %s - %s (%s)", b.ID, b.Name, b.Status)
+		if b.Owner != nil {
+			gray.Printf(" @%s", *b.Owner)
+		}
+		gray.Printf(": blocks %d task(s), %s %s\n", b.Blocks, formatWeight(b.BlockedWork), unit)
+	}
+}
+
+func formatWeight(w float64) string {
+	return strconv.FormatFloat(w, 'f', -1, 64)
+}
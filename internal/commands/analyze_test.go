@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetAnalyzeFlags() {
+	analyzePretty = false
+	analyzeLimit = 5
+}
+
+func TestAnalyzeCommand(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetAnalyzeFlags()
+
+	resetAnalyzeFlags()
+	analyzePretty = true
+	require.NoError(t, runAnalyze(nil, nil))
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	createStructuredTask(t, store)
+
+	analyzePretty = false
+	require.NoError(t, runAnalyze(nil, nil))
+	analyzePretty = true
+	analyzeLimit = 0
+	require.NoError(t, runAnalyze(nil, nil))
+
+	analyzeLimit = -1
+	assert.ErrorContains(t, runAnalyze(nil, nil), "cannot be negative")
+}
@@ -0,0 +1,426 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	batchPretty bool
+	batchDryRun bool
+)
+
+// batchInput is where batch reads its operations from (replaced in tests)
+var batchInput io.Reader = os.Stdin
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Apply a stream of operations in one transaction",
+	Long: `Read JSON operations from stdin, one object per line, and apply them all in a
+single write. If any operation fails, nothing is changed.
+
+Operations: ` + strings.Join(batchOps, ", ") + `.
+An add operation can name its task with "ref"; later operations refer to it
+with {"$ref": "<name>"} wherever a task ID is expected.
+
+  {"op": "add", "ref": "api", "name": "Build API", "action": "...", "verify": "...", "result": "..."}
+  {"op": "add", "ref": "ui", "name": "Build UI", "parent": "abcd", "blockedBy": [{"$ref": "api"}], ...}
+  {"op": "claim", "id": {"$ref": "api"}, "agent": "agent-1"}`,
+	Args: cobra.NoArgs,
+	RunE: runBatch,
+}
+
+func init() {
+	batchCmd.Flags().BoolVar(&batchPretty, "pretty", false, "Pretty print output")
+	batchCmd.Flags().BoolVar(&batchDryRun, "dry-run", false, "Validate the operations without saving anything")
+}
+
+// Batch operation names
+const (
+	batchAdd      = "add"
+	batchStatus   = "status"
+	batchBlock    = "block"
+	batchUnblock  = "unblock"
+	batchParent   = "parent"
+	batchUnparent = "unparent"
+	batchNote     = "note"
+	batchClaim    = "claim"
+	batchUnclaim  = "unclaim"
+	batchLink     = "link"
+)
+
+var batchOps = []string{
+	batchAdd, batchStatus, batchBlock, batchUnblock, batchParent,
+	batchUnparent, batchNote, batchClaim, batchUnclaim, batchLink,
+}
+
+// batchRef is a task reference: an existing task ID, or {"$ref": "name"} for a
+// task created by an earlier add operation in the same batch
+type batchRef struct {
+	ID  string
+	Ref string
+}
+
+func (r *batchRef) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &r.ID)
+	}
+	var obj struct {
+		Ref string `json:"$ref"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&obj); err != nil || obj.Ref == "" {
+		return fmt.Errorf(`task reference must be an ID or {"$ref": "<name>"}`)
+	}
+	r.Ref = obj.Ref
+	return nil
+}
+
+// batchOp is one operation. Which fields apply depends on Op; see the command help.
+type batchOp struct {
+	Op string `json:"op"`
+	// ID is the task the operation applies to (the blocked task for block/unblock)
+	ID *batchRef `json:"id,omitempty"`
+
+	// add
+	Ref         string         `json:"ref,omitempty"`
+	Name        string         `json:"name,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Action      string         `json:"action,omitempty"`
+	Verify      string         `json:"verify,omitempty"`
+	Result      string         `json:"result,omitempty"`
+	VerifyCmd   string         `json:"verifyCmd,omitempty"`
+	Fields      map[string]any `json:"fields,omitempty"`
+	Checklist   []string       `json:"checklist,omitempty"`
+	BlockedBy   []batchRef     `json:"blockedBy,omitempty"`
+	// Parent is used by add and parent
+	Parent *batchRef `json:"parent,omitempty"`
+
+	// status
+	Status  string `json:"status,omitempty"`
+	Outcome string `json:"outcome,omitempty"`
+
+	// block, unblock
+	Blocker *batchRef `json:"blocker,omitempty"`
+
+	// note
+	Message string `json:"message,omitempty"`
+	Kind    string `json:"kind,omitempty"`
+	Author  string `json:"author,omitempty"`
+
+	// claim
+	Agent string `json:"agent,omitempty"`
+	Force bool   `json:"force,omitempty"`
+
+	// link
+	Type   string    `json:"type,omitempty"`
+	Target *batchRef `json:"target,omitempty"`
+}
+
+type batchResult struct {
+	// IDs maps each add operation's ref to the ID of the task it created
+	IDs map[string]string `json:"ids"`
+	// Created lists every new task ID in operation order
+	Created []string `json:"created"`
+	Applied int      `json:"applied"`
+	DryRun  bool     `json:"dryRun,omitempty"`
+}
+
+func runBatch(cmd *cobra.Command, args []string) error {
+	ops, err := readBatchOps(batchInput)
+	if err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		return models.Errorf(models.CodeInvalidArgument, "no operations on stdin")
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	result := &batchResult{IDs: make(map[string]string), Created: []string{}, DryRun: batchDryRun}
+	apply := func(tx *storage.Storage) error {
+		now := time.Now()
+		for i := range ops {
+			// Space out timestamps so created tasks keep their batch order
+			at := now.Add(time.Duration(i) * time.Microsecond)
+			if err := applyBatchOp(tx, &ops[i], result, at); err != nil {
+				return fmt.Errorf("operation %d (%s): %w", i+1, ops[i].Op, err)
+			}
+			result.Applied++
+		}
+		return nil
+	}
+	if batchDryRun {
+		err = store.DryRun(apply)
+	} else {
+		err = store.Transaction(apply)
+	}
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, outputMode(batchPretty), result, func() {
+		green := color.New(color.FgGreen)
+		if batchDryRun {
+			green.Printf("Dry run: %d operation(s) are valid\n", result.Applied)
+		} else {
+			green.Printf("Applied %d operation(s)\n", result.Applied)
+		}
+		refs := make([]string, 0, len(result.IDs))
+		for ref := range result.IDs {
+			refs = append(refs, ref)
+		}
+		sort.Strings(refs)
+		for _, ref := range refs {
+			fmt.Printf("  %s: %s\n", ref, result.IDs[ref])
+		}
+	})
+}
+
+// readBatchOps decodes a stream of JSON objects, one operation each
+func readBatchOps(r io.Reader) ([]batchOp, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var ops []batchOp
+	for {
+		var op batchOp
+		err := dec.Decode(&op)
+		if err == io.EOF {
+			return ops, nil
+		}
+		if err != nil {
+			return nil, models.Errorf(models.CodeInvalidArgument, "operation %d: invalid JSON: %w", len(ops)+1, err)
+		}
+		if !slices.Contains(batchOps, op.Op) {
+			return nil, models.Errorf(models.CodeInvalidArgument, "operation %d: unknown op %q. Must be one of: %s", len(ops)+1, op.Op, strings.Join(batchOps, ", "))
+		}
+		ops = append(ops, op)
+	}
+}
+
+// resolve turns a reference into the ID of an existing or newly created task
+func (r *batchResult) resolve(tx *storage.Storage, ref *batchRef, what string) (*models.Task, error) {
+	if ref == nil {
+		return nil, models.Errorf(models.CodeInvalidArgument, "%s is required", what)
+	}
+	id := models.NormalizeTaskID(ref.ID)
+	if ref.Ref != "" {
+		var ok bool
+		if id, ok = r.IDs[ref.Ref]; !ok {
+			return nil, models.Errorf(models.CodeInvalidArgument, "unknown $ref %q: no earlier add operation has that ref", ref.Ref)
+		}
+	} else if !models.IsValidTaskID(id) {
+		return nil, models.Errorf(models.CodeInvalidID, "invalid %s ID: %s", what, ref.ID).With("id", ref.ID)
+	}
+
+	task, err := tx.LoadTask(id)
+	if err != nil {
+		if err == storage.ErrTaskNotFound {
+			return nil, models.Errorf(models.CodeNotFound, "%s %s not found", what, id).With("id", id)
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+func applyBatchOp(tx *storage.Storage, op *batchOp, result *batchResult, now time.Time) error {
+	if op.Op == batchAdd {
+		return applyBatchAdd(tx, op, result, now)
+	}
+
+	task, err := result.resolve(tx, op.ID, "task")
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case batchStatus:
+		if !models.IsValidStatus(op.Status) {
+			return models.Errorf(models.CodeInvalidArgument, "invalid status %q. Must be: todo, in-progress, done, cancelled", op.Status)
+		}
+		_, err := applyStatus(tx, task, op.Status, op.Outcome, now)
+		return err
+
+	case batchBlock, batchUnblock:
+		blocker, err := result.resolve(tx, op.Blocker, "blocker task")
+		if err != nil {
+			return err
+		}
+		if op.Op == batchUnblock {
+			if !removeBlocker(task, blocker.ID) {
+				return models.Errorf(models.CodeNotFound, "task %s is not blocked by %s", task.ID, blocker.ID)
+			}
+		} else {
+			if blocker.ID == task.ID {
+				return models.Errorf(models.CodeInvalidArgument, "a task cannot block itself")
+			}
+			if err := validateBlock(tx, blocker, task, blocker.ID, task.ID); err != nil {
+				return err
+			}
+			task.BlockedBy = append(task.BlockedBy, blocker.ID)
+		}
+
+	case batchParent:
+		parent, err := result.resolve(tx, op.Parent, "parent task")
+		if err != nil {
+			return err
+		}
+		if parent.ID == task.ID {
+			return models.Errorf(models.CodeInvalidArgument, "cannot set task as its own parent")
+		}
+		if models.IsClosed(parent.Status) {
+			return models.Errorf(models.CodeConflict, "cannot set %s task %s as parent", parent.Status, parent.ID)
+		}
+		if wouldCreateCycle(tx, task.ID, parent.ID) {
+			return models.Errorf(models.CodeConflict, "cannot set parent - would create circular dependency")
+		}
+		task.SetParent(&parent.ID, storage.CurrentActor(), now)
+
+	case batchUnparent:
+		// Like the unparent command, a top-level task is left as it is
+		task.SetParent(nil, storage.CurrentActor(), now)
+
+	case batchNote:
+		if op.Message == "" {
+			return models.Errorf(models.CodeInvalidArgument, "note message cannot be empty")
+		}
+		kind := models.NoteObservation
+		if op.Kind != "" {
+			if err := validateNoteKind(op.Kind); err != nil {
+				return err
+			}
+			kind = op.Kind
+		}
+		author := op.Author
+		if author == "" {
+			author = storage.CurrentActor()
+		}
+		task.AddNote(models.Note{Content: op.Message, Kind: kind, Author: author, Timestamp: now})
+
+	case batchClaim:
+		if op.Agent == "" {
+			return models.Errorf(models.CodeInvalidArgument, "agent name cannot be empty")
+		}
+		if task.Owner != nil && *task.Owner != op.Agent && !op.Force {
+			return models.Errorf(models.CodeConflict, "task %s is already owned by %s (set force to override)", task.ID, *task.Owner).
+				With("id", task.ID).With("owner", *task.Owner)
+		}
+		agent := op.Agent
+		task.SetOwner(&agent, storage.CurrentActor(), now)
+
+	case batchUnclaim:
+		if task.Owner == nil {
+			return models.Errorf(models.CodeConflict, "task %s has no owner", task.ID)
+		}
+		task.SetOwner(nil, storage.CurrentActor(), now)
+
+	case batchLink:
+		target, err := result.resolve(tx, op.Target, "target task")
+		if err != nil {
+			return err
+		}
+		_, err = tx.AddLink(task.ID, op.Type, target.ID)
+		return err
+	}
+
+	task.Updated = now
+	return tx.SaveTask(task)
+}
+
+func applyBatchAdd(tx *storage.Storage, op *batchOp, result *batchResult, now time.Time) error {
+	if op.ID != nil {
+		return models.Errorf(models.CodeInvalidArgument, "add does not take an id; use ref to name the new task")
+	}
+	if strings.TrimSpace(op.Name) == "" {
+		return models.Errorf(models.CodeInvalidArgument, "name is required")
+	}
+	if op.Action == "" || op.Verify == "" || op.Result == "" {
+		return models.Errorf(models.CodeInvalidArgument, "action, verify and result are required")
+	}
+	if op.Ref != "" {
+		if _, dup := result.IDs[op.Ref]; dup {
+			return models.Errorf(models.CodeInvalidArgument, "duplicate ref %q", op.Ref)
+		}
+	}
+
+	var parent *string
+	if op.Parent != nil {
+		parentTask, err := result.resolve(tx, op.Parent, "parent task")
+		if err != nil {
+			return err
+		}
+		if models.IsClosed(parentTask.Status) {
+			return models.Errorf(models.CodeConflict, "cannot add child to %s task", parentTask.Status)
+		}
+		parent = &parentTask.ID
+	}
+
+	cfg, err := tx.LoadConfig()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Fields.Validate(op.Fields); err != nil {
+		return err
+	}
+
+	id, err := tx.GenerateTaskID()
+	if err != nil {
+		return err
+	}
+	task := &models.Task{
+		ID:          id,
+		Name:        op.Name,
+		Description: op.Description,
+		Action:      op.Action,
+		Verify:      op.Verify,
+		Result:      op.Result,
+		VerifyCmd:   op.VerifyCmd,
+		Parent:      parent,
+		Status:      models.StatusTodo,
+		Fields:      op.Fields,
+		Created:     now,
+		Updated:     now,
+	}
+	for _, item := range op.Checklist {
+		task.Checklist = append(task.Checklist, models.ChecklistItem{Text: item})
+	}
+
+	for i := range op.BlockedBy {
+		blocker, err := result.resolve(tx, &op.BlockedBy[i], "blocker task")
+		if err != nil {
+			return err
+		}
+		if models.IsClosed(blocker.Status) {
+			return models.Errorf(models.CodeConflict, "cannot block on completed task %s", blocker.ID)
+		}
+		if !slices.Contains(task.BlockedBy, blocker.ID) {
+			task.BlockedBy = append(task.BlockedBy, blocker.ID)
+		}
+	}
+
+	if err := tx.SaveTask(task); err != nil {
+		return err
+	}
+	if op.Ref != "" {
+		result.IDs[op.Ref] = id
+	}
+	result.Created = append(result.Created, id)
+	return nil
+}
@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetBatchFlags() {
+	batchPretty = false
+	batchDryRun = false
+}
+
+func runBatchInput(t *testing.T, input string) error {
+	t.Helper()
+	orig := batchInput
+	defer func() { batchInput = orig }()
+	batchInput = strings.NewReader(input)
+	return runBatch(nil, nil)
+}
+
+const batchPlan = `
+{"op": "add", "ref": "api", "name": "Build API", "action": "a", "verify": "v", "result": "r"}
+{"op": "add", "ref": "ui", "name": "Build UI", "parent": "aaaa", "blockedBy": [{"$ref": "api"}], "action": "a", "verify": "v", "result": "r", "checklist": ["renders"]}
+{"op": "claim", "id": {"$ref": "api"}, "agent": "agent-1"}
+{"op": "status", "id": {"$ref": "api"}, "status": "in-progress"}
+{"op": "note", "id": {"$ref": "ui"}, "message": "waiting on api", "kind": "handoff"}
+`
+
+func TestBatchCommand(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetBatchFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	createStructuredTask(t, store)
+
+	resetBatchFlags()
+	require.NoError(t, runBatchInput(t, batchPlan))
+
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+
+	var api, ui *models.Task
+	for i := range tasks {
+		switch tasks[i].Name {
+		case "Build API":
+			api = &tasks[i]
+		case "Build UI":
+			ui = &tasks[i]
+		}
+	}
+	require.NotNil(t, api)
+	require.NotNil(t, ui)
+
+	assert.Equal(t, models.StatusInProgress, api.Status)
+	require.NotNil(t, api.Owner)
+	assert.Equal(t, "agent-1", *api.Owner)
+
+	require.NotNil(t, ui.Parent)
+	assert.Equal(t, "aaaa", *ui.Parent)
+	assert.Equal(t, []string{api.ID}, ui.BlockedBy)
+	require.Len(t, ui.Checklist, 1)
+	require.Len(t, ui.Notes, 1)
+	assert.Equal(t, models.NoteHandoff, ui.Notes[0].Kind)
+	assert.True(t, ui.Created.After(api.Created))
+}
+
+func TestBatchCommand_Atomic(t *testing.T) {
+	tmpDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetBatchFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	createStructuredTask(t, store)
+	before, err := os.ReadFile(filepath.Join(tmpDir, storage.ClipmDir, storage.TasksFile))
+	require.NoError(t, err)
+
+	// The last operation fails, so none of the earlier ones are saved
+	resetBatchFlags()
+	err = runBatchInput(t, batchPlan+`{"op": "status", "id": {"$ref": "ui"}, "status": "in-progress"}`)
+	assert.ErrorContains(t, err, "operation 6 (status): cannot start task")
+
+	after, err := os.ReadFile(filepath.Join(tmpDir, storage.ClipmDir, storage.TasksFile))
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+
+	// A dry run validates without saving
+	batchDryRun = true
+	require.NoError(t, runBatchInput(t, batchPlan))
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+}
+
+func TestBatchCommand_Errors(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetBatchFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	createStructuredTask(t, store)
+
+	resetBatchFlags()
+	for _, tc := range []struct {
+		input string
+		err   string
+	}{
+		{``, "no operations"},
+		{`{"op": "fly"}`, `unknown op "fly"`},
+		{`{"op": "add", "nmae": "typo"}`, "invalid JSON"},
+		{`{"op": "note", "id": {"ref": "x"}, "message": "m"}`, "task reference must be"},
+		{`{"op": "add", "name": "x"}`, "action, verify and result are required"},
+		{`{"op": "claim", "id": {"$ref": "later"}, "agent": "a"}`, `unknown $ref "later"`},
+		{`{"op": "block", "id": "aaaa", "blocker": "zzzz"}`, "blocker task zzzz not found"},
+		{`{"op": "unblock", "id": "aaaa", "blocker": "aaaa"}`, "is not blocked by"},
+		{`{"op": "status", "id": "aaaa", "status": "done"}`, "requires --outcome"},
+		{`{"op": "add", "ref": "x", "name": "x", "action": "a", "verify": "v", "result": "r"}
+{"op": "add", "ref": "x", "name": "y", "action": "a", "verify": "v", "result": "r"}`, `duplicate ref "x"`},
+	} {
+		assert.ErrorContains(t, runBatchInput(t, tc.input), tc.err, tc.input)
+	}
+
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+}
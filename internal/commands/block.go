@@ -1,8 +1,7 @@
 package commands
 
 import (
-	"encoding/json"
-	"fmt"
+	"os"
 	"time"
 
 	"github.com/fatih/color"
@@ -52,28 +51,23 @@ func runBlock(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if blockPretty {
+	return render(os.Stdout, outputMode(blockPretty), blocked, func() {
 		green := color.New(color.FgGreen)
 		green.Printf("Task %s is now blocked by %s\n", blockedID, blockerID)
-	} else {
-		out, _ := json.Marshal(blocked)
-		fmt.Println(string(out))
-	}
-
-	return nil
+	})
 }
 
 func parseBlockArgs(args []string) (blockerID, blockedID string, err error) {
 	blockerID = models.NormalizeTaskID(args[0])
 	if !models.IsValidTaskID(blockerID) {
-		return "", "", fmt.Errorf("invalid blocker ID: %s", args[0])
+		return "", "", models.Errorf(models.CodeInvalidID, "invalid blocker ID: %s", args[0]).With("id", args[0])
 	}
 	blockedID = models.NormalizeTaskID(args[1])
 	if !models.IsValidTaskID(blockedID) {
-		return "", "", fmt.Errorf("invalid blocked ID: %s", args[1])
+		return "", "", models.Errorf(models.CodeInvalidID, "invalid blocked ID: %s", args[1]).With("id", args[1])
 	}
 	if blockerID == blockedID {
-		return "", "", fmt.Errorf("a task cannot block itself")
+		return "", "", models.Errorf(models.CodeInvalidArgument, "a task cannot block itself")
 	}
 	return blockerID, blockedID, nil
 }
@@ -82,7 +76,7 @@ func loadBlockTasks(store *storage.Storage, blockerID, blockedID string) (*model
 	blocker, err := store.LoadTask(blockerID)
 	if err != nil {
 		if err == storage.ErrTaskNotFound {
-			return nil, nil, fmt.Errorf("blocker task %s not found", blockerID)
+			return nil, nil, models.Errorf(models.CodeNotFound, "blocker task %s not found", blockerID).With("id", blockerID)
 		}
 		return nil, nil, err
 	}
@@ -90,7 +84,7 @@ func loadBlockTasks(store *storage.Storage, blockerID, blockedID string) (*model
 	blocked, err := store.LoadTask(blockedID)
 	if err != nil {
 		if err == storage.ErrTaskNotFound {
-			return nil, nil, fmt.Errorf("blocked task %s not found", blockedID)
+			return nil, nil, models.Errorf(models.CodeNotFound, "blocked task %s not found", blockedID).With("id", blockedID)
 		}
 		return nil, nil, err
 	}
@@ -98,8 +92,8 @@ func loadBlockTasks(store *storage.Storage, blockerID, blockedID string) (*model
 }
 
 func validateBlock(store *storage.Storage, blocker, blocked *models.Task, blockerID, blockedID string) error {
-	if blocker.Status == models.StatusDone {
-		return fmt.Errorf("cannot block on completed task %s", blockerID)
+	if models.IsClosed(blocker.Status) {
+		return models.Errorf(models.CodeConflict, "cannot block on completed task %s", blockerID)
 	}
 
 	hasCycle, err := store.WouldCreateCycle(blockerID, blockedID)
@@ -107,12 +101,12 @@ func validateBlock(store *storage.Storage, blocker, blocked *models.Task, blocke
 		return err
 	}
 	if hasCycle {
-		return fmt.Errorf("cannot add dependency: would create a cycle")
+		return models.Errorf(models.CodeConflict, "cannot add dependency: would create a cycle")
 	}
 
 	for _, id := range blocked.BlockedBy {
 		if id == blockerID {
-			return fmt.Errorf("task %s is already blocked by %s", blockedID, blockerID)
+			return models.Errorf(models.CodeConflict, "task %s is already blocked by %s", blockedID, blockerID)
 		}
 	}
 	return nil
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cancelPretty    bool
+	cancelReason    string
+	cancelRecursive bool
+)
+
+var cancelCmd = &cobra.Command{
+	Use:   "cancel <id>",
+	Short: "Cancel a task",
+	Long: `Close a task without doing it. A cancelled task no longer blocks other tasks,
+holds its parent open or counts towards progress. The reason, if given, is
+recorded as the task's outcome.
+
+With --recursive the task's open descendants are cancelled too, in one
+transaction. Descendants that are already done stay done.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCancel,
+}
+
+func init() {
+	cancelCmd.Flags().BoolVar(&cancelPretty, "pretty", false, "Pretty print output")
+	cancelCmd.Flags().StringVar(&cancelReason, "reason", "", "Why the task was cancelled")
+	cancelCmd.Flags().BoolVar(&cancelRecursive, "recursive", false, "Cancel the task's open descendants too")
+}
+
+func runCancel(cmd *cobra.Command, args []string) error {
+	id := models.NormalizeTaskID(args[0])
+	if !models.IsValidTaskID(id) {
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	task, err := store.LoadTask(id)
+	if err != nil {
+		if err == storage.ErrTaskNotFound {
+			return models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+		}
+		return err
+	}
+	if task.Status == models.StatusCancelled {
+		return models.Errorf(models.CodeConflict, "task %s is already cancelled", id).With("id", id)
+	}
+
+	now := time.Now()
+	if cancelRecursive {
+		var cancelled []models.Task
+		err = store.Transaction(func(tx *storage.Storage) error {
+			var err error
+			cancelled, _, err = applyStatusRecursive(tx, id, models.StatusCancelled, cancelReason, now)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		return render(os.Stdout, outputMode(cancelPretty), cancelled, func() {
+			green := color.New(color.FgGreen)
+			green.Printf("Cancelled %d task(s)\n", len(cancelled))
+			for i := range cancelled {
+				fmt.Printf("  %s  %s\n", cancelled[i].ID, cancelled[i].Name)
+			}
+		})
+	}
+
+	err = store.Transaction(func(tx *storage.Storage) error {
+		_, err := applyStatus(tx, task, models.StatusCancelled, cancelReason, now)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, outputMode(cancelPretty), task, func() {
+		green := color.New(color.FgGreen)
+		green.Printf("Cancelled task %s\n", id)
+	})
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetCancelFlags() {
+	cancelPretty = false
+	cancelReason = ""
+	cancelRecursive = false
+}
+
+func TestCancelCommand(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetCancelFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createStructuredTask(t, store)
+	now := time.Now()
+	parentID := task.ID
+	child := &models.Task{ID: "bbbb", Name: "Child", Parent: &parentID, Status: models.StatusTodo, Created: now, Updated: now}
+	require.NoError(t, store.SaveTask(child))
+	dependent := &models.Task{ID: "cccc", Name: "Dependent", BlockedBy: []string{"bbbb"}, Status: models.StatusTodo, Created: now, Updated: now}
+	require.NoError(t, store.SaveTask(dependent))
+
+	// Open children keep the parent open
+	resetCancelFlags()
+	err = runCancel(nil, []string{"aaaa"})
+	assert.Equal(t, models.CodeHasUndoneChildren, models.ErrorCodeOf(err))
+
+	// Structured tasks need no outcome to be cancelled
+	cancelReason = "out of scope"
+	require.NoError(t, runCancel(nil, []string{"BBBB"}))
+	child, err = store.LoadTask("bbbb")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, child.Status)
+	assert.Equal(t, "out of scope", child.Outcome)
+
+	// Dependents are unblocked
+	dependent, err = store.LoadTask("cccc")
+	require.NoError(t, err)
+	assert.Empty(t, dependent.BlockedBy)
+
+	resetCancelFlags()
+	cancelPretty = true
+	require.NoError(t, runCancel(nil, []string{"aaaa"}))
+	assert.ErrorContains(t, runCancel(nil, []string{"aaaa"}), "already cancelled")
+	assert.ErrorContains(t, runCancel(nil, []string{"zzzz"}), "task zzzz not found")
+	assert.ErrorContains(t, runCancel(nil, []string{"x"}), "invalid task ID")
+}
+
+func TestCancelCommand_Recursive(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetCancelFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	now := time.Now()
+	root, child := "aaaa", "bbbb"
+	for _, task := range []*models.Task{
+		{ID: "aaaa", Name: "Root", Status: models.StatusTodo},
+		{ID: "bbbb", Name: "Open child", Parent: &root, Status: models.StatusTodo},
+		{ID: "cccc", Name: "Done child", Parent: &root, Status: models.StatusDone},
+		{ID: "dddd", Name: "Grandchild", Parent: &child, Status: models.StatusInProgress},
+		{ID: "eeee", Name: "Dependent", BlockedBy: []string{"dddd"}, Status: models.StatusTodo},
+	} {
+		task.Created, task.Updated = now, now
+		require.NoError(t, store.SaveTask(task))
+	}
+
+	resetCancelFlags()
+	cancelRecursive = true
+	cancelReason = "dropped"
+	require.NoError(t, runCancel(nil, []string{"aaaa"}))
+
+	for _, id := range []string{"aaaa", "bbbb", "dddd"} {
+		task, err := store.LoadTask(id)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusCancelled, task.Status, id)
+		assert.Equal(t, "dropped", task.Outcome, id)
+	}
+	done, err := store.LoadTask("cccc")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusDone, done.Status)
+	dependent, err := store.LoadTask("eeee")
+	require.NoError(t, err)
+	assert.Empty(t, dependent.BlockedBy)
+
+	cancelPretty = true
+	assert.ErrorContains(t, runCancel(nil, []string{"aaaa"}), "already cancelled")
+}
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var checkPretty bool
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Manage a task's acceptance-criteria checklist",
+	Long:  `Add checklist items to a task and tick them off as each criterion is met.`,
+}
+
+var checkAddCmd = &cobra.Command{
+	Use:   "add <id> <item>...",
+	Short: "Add checklist items to a task",
+	Long:  `Append one checklist item per argument to the task's checklist.`,
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runCheckAdd,
+}
+
+var checkTickCmd = &cobra.Command{
+	Use:   "tick <id> <item-number>...",
+	Short: "Mark checklist items as done",
+	Long:  `Tick one or more checklist items, identified by their 1-based position.`,
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runCheckTick,
+}
+
+var checkUntickCmd = &cobra.Command{
+	Use:   "untick <id> <item-number>...",
+	Short: "Mark checklist items as not done",
+	Long:  `Untick one or more checklist items, identified by their 1-based position.`,
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runCheckUntick,
+}
+
+func init() {
+	checkCmd.PersistentFlags().BoolVar(&checkPretty, "pretty", false, "Pretty print output")
+	checkCmd.AddCommand(checkAddCmd)
+	checkCmd.AddCommand(checkTickCmd)
+	checkCmd.AddCommand(checkUntickCmd)
+}
+
+func runCheckAdd(cmd *cobra.Command, args []string) error {
+	for _, text := range args[1:] {
+		if strings.TrimSpace(text) == "" {
+			return models.Errorf(models.CodeInvalidArgument, "checklist item cannot be empty")
+		}
+	}
+
+	return updateChecklist(args[0], func(task *models.Task) (string, error) {
+		for _, text := range args[1:] {
+			task.Checklist = append(task.Checklist, models.ChecklistItem{Text: text})
+		}
+		return fmt.Sprintf("Added %d checklist item(s) to task %s", len(args)-1, task.ID), nil
+	})
+}
+
+func runCheckTick(cmd *cobra.Command, args []string) error {
+	return setChecklistItems(args, true)
+}
+
+func runCheckUntick(cmd *cobra.Command, args []string) error {
+	return setChecklistItems(args, false)
+}
+
+func setChecklistItems(args []string, done bool) error {
+	return updateChecklist(args[0], func(task *models.Task) (string, error) {
+		now := time.Now()
+		for _, arg := range args[1:] {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 1 || n > len(task.Checklist) {
+				return "", models.Errorf(models.CodeInvalidArgument, "invalid checklist item %q: task %s has %d item(s)", arg, task.ID, len(task.Checklist))
+			}
+			item := &task.Checklist[n-1]
+			item.Done = done
+			if done {
+				item.DoneAt = &now
+			} else {
+				item.DoneAt = nil
+			}
+		}
+		ticked, total := task.ChecklistProgress()
+		return fmt.Sprintf("Task %s checklist: %d/%d done", task.ID, ticked, total), nil
+	})
+}
+
+// updateChecklist loads a task, applies fn to it and saves it, printing the
+// task as JSON or fn's message with --pretty
+func updateChecklist(rawID string, fn func(task *models.Task) (string, error)) error {
+	id := models.NormalizeTaskID(rawID)
+	if !models.IsValidTaskID(id) {
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", rawID).With("id", rawID)
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	task, err := store.LoadTask(id)
+	if err != nil {
+		if err == storage.ErrTaskNotFound {
+			return models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+		}
+		return err
+	}
+
+	message, err := fn(task)
+	if err != nil {
+		return err
+	}
+	task.Updated = time.Now()
+
+	if err := store.SaveTask(task); err != nil {
+		return err
+	}
+
+	return render(os.Stdout, outputMode(checkPretty), task, func() {
+		green := color.New(color.FgGreen)
+		green.Println(message)
+	})
+}
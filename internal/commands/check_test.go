@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCommands(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	id := createTestTask(t, store, "Checked", models.StatusInProgress, nil)
+
+	checkPretty = false
+	require.NoError(t, runCheckAdd(nil, []string{id, "unit tests pass", "docs updated"}))
+	require.NoError(t, runCheckAdd(nil, []string{id, "changelog entry"}))
+
+	task, err := store.LoadTask(id)
+	require.NoError(t, err)
+	require.Len(t, task.Checklist, 3)
+	assert.Equal(t, "unit tests pass", task.Checklist[0].Text)
+	assert.Equal(t, "changelog entry", task.Checklist[2].Text)
+	assert.False(t, task.Checklist[0].Done)
+
+	require.NoError(t, runCheckTick(nil, []string{id, "1", "3"}))
+	task, err = store.LoadTask(id)
+	require.NoError(t, err)
+	done, total := task.ChecklistProgress()
+	assert.Equal(t, 2, done)
+	assert.Equal(t, 3, total)
+	assert.NotNil(t, task.Checklist[0].DoneAt)
+
+	checkPretty = true
+	require.NoError(t, runCheckUntick(nil, []string{id, "1"}))
+	task, err = store.LoadTask(id)
+	require.NoError(t, err)
+	assert.False(t, task.Checklist[0].Done)
+	assert.Nil(t, task.Checklist[0].DoneAt)
+	assert.True(t, task.Checklist[2].Done)
+	checkPretty = false
+}
+
+func TestCheckCommands_Errors(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	id := createTestTask(t, store, "Checked", models.StatusTodo, nil)
+
+	checkPretty = false
+	assert.ErrorContains(t, runCheckAdd(nil, []string{id, " "}), "cannot be empty")
+	assert.ErrorContains(t, runCheckAdd(nil, []string{"zzzz", "item"}), "not found")
+	assert.ErrorContains(t, runCheckAdd(nil, []string{"bad!", "item"}), "invalid task ID")
+
+	require.NoError(t, runCheckAdd(nil, []string{id, "only item"}))
+	assert.ErrorContains(t, runCheckTick(nil, []string{id, "2"}), "has 1 item(s)")
+	assert.ErrorContains(t, runCheckTick(nil, []string{id, "first"}), "invalid checklist item")
+	assert.ErrorContains(t, runCheckUntick(nil, []string{id, "0"}), "invalid checklist item")
+
+	// A failed tick leaves the task untouched
+	require.Error(t, runCheckTick(nil, []string{id, "1", "5"}))
+	task, err := store.LoadTask(id)
+	require.NoError(t, err)
+	assert.False(t, task.Checklist[0].Done)
+}
+
+func TestStatusDone_RequireChecklist(t *testing.T) {
+	tmpDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	id := createTestTask(t, store, "Checked", models.StatusInProgress, nil)
+
+	checkPretty = false
+	statusPretty = false
+	statusOutcome = ""
+	require.NoError(t, runCheckAdd(nil, []string{id, "a", "b"}))
+	require.NoError(t, runCheckTick(nil, []string{id, "1"}))
+
+	writeTestConfig(t, tmpDir, "requireChecklist: true\n")
+	assert.ErrorContains(t, runStatus(nil, []string{id, models.StatusDone}), "1 of 2 checklist items unticked")
+
+	require.NoError(t, runCheckTick(nil, []string{id, "2"}))
+	require.NoError(t, runStatus(nil, []string{id, models.StatusDone}))
+}
+
+func TestStatusDone_ChecklistAdvisoryByDefault(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	id := createTestTask(t, store, "Checked", models.StatusInProgress, nil)
+
+	checkPretty = false
+	statusPretty = false
+	statusOutcome = ""
+	require.NoError(t, runCheckAdd(nil, []string{id, "a"}))
+	require.NoError(t, runStatus(nil, []string{id, models.StatusDone}))
+}
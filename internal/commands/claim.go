@@ -1,8 +1,7 @@
 package commands
 
 import (
-	"encoding/json"
-	"fmt"
+	"os"
 	"time"
 
 	"github.com/fatih/color"
@@ -32,12 +31,12 @@ func init() {
 func runClaim(cmd *cobra.Command, args []string) error {
 	id := models.NormalizeTaskID(args[0])
 	if !models.IsValidTaskID(id) {
-		return fmt.Errorf("invalid task ID: %s", args[0])
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
 	}
 
 	agentName := args[1]
 	if agentName == "" {
-		return fmt.Errorf("agent name cannot be empty")
+		return models.Errorf(models.CodeInvalidArgument, "agent name cannot be empty")
 	}
 
 	store, err := storage.NewStorage()
@@ -48,30 +47,27 @@ func runClaim(cmd *cobra.Command, args []string) error {
 	task, err := store.LoadTask(id)
 	if err != nil {
 		if err == storage.ErrTaskNotFound {
-			return fmt.Errorf("task %s not found", id)
+			return models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
 		}
 		return err
 	}
 
 	// Check if already owned by different agent
 	if task.Owner != nil && *task.Owner != agentName && !claimForce {
-		return fmt.Errorf("task %s is already owned by %s (use --force to override)", id, *task.Owner)
+		return models.Errorf(models.CodeConflict, "task %s is already owned by %s (use --force to override)", id, *task.Owner).
+			With("id", id).With("owner", *task.Owner)
 	}
 
-	task.Owner = &agentName
-	task.Updated = time.Now()
+	now := time.Now()
+	task.SetOwner(&agentName, storage.CurrentActor(), now)
+	task.Updated = now
 
 	if err := store.SaveTask(task); err != nil {
 		return err
 	}
 
-	if claimPretty {
+	return render(os.Stdout, outputMode(claimPretty), task, func() {
 		green := color.New(color.FgGreen)
 		green.Printf("Task %s claimed by %s\n", id, agentName)
-	} else {
-		out, _ := json.Marshal(task)
-		fmt.Println(string(out))
-	}
-
-	return nil
+	})
 }
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	clonePretty       bool
+	cloneRecursive    bool
+	cloneParent       string
+	cloneResetStatus  bool
+	cloneKeepOwners   bool
+	cloneKeepNotes    bool
+	cloneKeepOutcomes bool
+)
+
+var cloneCmd = &cobra.Command{
+	Use:   "clone <id>",
+	Short: "Copy a task or a subtree",
+	Long: `Copy a task under a fresh ID, for example to re-run a checklist of work.
+With --recursive its descendants are copied too, keeping their order and the
+dependencies among them. Dependencies on tasks outside the copy are dropped.
+
+Copies keep the name, description, structured fields, verification command,
+custom fields, checklist and status. Owners, notes and outcomes are dropped
+unless --keep-owners, --keep-notes or --keep-outcomes is given. History,
+verifications and links are never copied. --reset-status makes every copy todo
+with an unticked checklist.
+
+The copy goes under the original's parent unless --parent is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runClone,
+}
+
+func init() {
+	cloneCmd.Flags().BoolVar(&clonePretty, "pretty", false, "Pretty print output")
+	cloneCmd.Flags().BoolVar(&cloneRecursive, "recursive", false, "Copy the task's descendants too")
+	cloneCmd.Flags().StringVar(&cloneParent, "parent", "", "Parent task ID for the copy")
+	cloneCmd.Flags().BoolVar(&cloneResetStatus, "reset-status", false, "Make every copy todo and untick checklists")
+	cloneCmd.Flags().BoolVar(&cloneKeepOwners, "keep-owners", false, "Copy owners")
+	cloneCmd.Flags().BoolVar(&cloneKeepNotes, "keep-notes", false, "Copy notes")
+	cloneCmd.Flags().BoolVar(&cloneKeepOutcomes, "keep-outcomes", false, "Copy outcomes")
+}
+
+func runClone(cmd *cobra.Command, args []string) error {
+	id := models.NormalizeTaskID(args[0])
+	if !models.IsValidTaskID(id) {
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
+	}
+	opts := storage.CloneOptions{
+		Recursive:    cloneRecursive,
+		ResetStatus:  cloneResetStatus,
+		KeepOwners:   cloneKeepOwners,
+		KeepNotes:    cloneKeepNotes,
+		KeepOutcomes: cloneKeepOutcomes,
+	}
+	if cloneParent != "" {
+		parentID := models.NormalizeTaskID(cloneParent)
+		if !models.IsValidTaskID(parentID) {
+			return models.Errorf(models.CodeInvalidID, "invalid parent ID: %s", cloneParent).With("id", cloneParent)
+		}
+		opts.Parent = &parentID
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	result, err := store.CloneTask(id, opts)
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, outputMode(clonePretty), result, func() {
+		green := color.New(color.FgGreen)
+		green.Printf("Cloned task %s as %s\n", id, result.Task.ID)
+		if len(result.Created) > 1 {
+			fmt.Printf("Created %d tasks\n", len(result.Created))
+		}
+	})
+}
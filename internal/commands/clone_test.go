@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetCloneFlags() {
+	clonePretty = false
+	cloneRecursive = false
+	cloneParent = ""
+	cloneResetStatus = false
+	cloneKeepOwners = false
+	cloneKeepNotes = false
+	cloneKeepOutcomes = false
+}
+
+func TestCloneCommand(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetCloneFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	now := time.Now()
+	parent := "aaaa"
+	require.NoError(t, store.SaveTask(&models.Task{ID: "aaaa", Name: "Release", Status: models.StatusDone, Created: now, Updated: now}))
+	require.NoError(t, store.SaveTask(&models.Task{ID: "bbbb", Name: "Tag", Parent: &parent, Status: models.StatusDone, Created: now, Updated: now}))
+	require.NoError(t, store.SaveTask(&models.Task{ID: "cccc", Name: "Next", Status: models.StatusTodo, Created: now, Updated: now}))
+
+	cloneRecursive = true
+	cloneResetStatus = true
+	cloneParent = "CCCC"
+	clonePretty = true
+	require.NoError(t, runClone(nil, []string{"aaaa"}))
+
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	require.Len(t, tasks, 5)
+	var copies []models.Task
+	for _, task := range tasks {
+		if task.Created.After(now) {
+			copies = append(copies, task)
+		}
+	}
+	require.Len(t, copies, 2)
+	for _, task := range copies {
+		assert.Equal(t, models.StatusTodo, task.Status)
+	}
+
+	resetCloneFlags()
+	cloneParent = "x"
+	assert.ErrorContains(t, runClone(nil, []string{"aaaa"}), "invalid parent ID")
+	resetCloneFlags()
+	assert.ErrorContains(t, runClone(nil, []string{"x"}), "invalid task ID")
+	assert.ErrorContains(t, runClone(nil, []string{"zzzz"}), "task zzzz not found")
+}
@@ -1,8 +1,9 @@
 package commands
 
 import (
-	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/simonspoon/clipm/internal/models"
@@ -10,30 +11,49 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var deletePretty bool
+var (
+	deletePretty    bool
+	deleteRecursive bool
+	deleteDryRun    bool
+)
 
 var deleteCmd = &cobra.Command{
 	Use:   "delete <id>",
 	Short: "Delete a task",
-	Long:  `Delete a task. Cannot delete tasks that have undone children.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runDelete,
+	Long: `Delete a task. Cannot delete tasks that have undone children unless
+--recursive is given, which deletes the task and all of its descendants in one
+transaction. --dry-run lists what would be deleted without deleting anything.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDelete,
 }
 
 func init() {
 	deleteCmd.Flags().BoolVar(&deletePretty, "pretty", false, "Pretty print output")
+	deleteCmd.Flags().BoolVar(&deleteRecursive, "recursive", false, "Delete the task's descendants too")
+	deleteCmd.Flags().BoolVar(&deleteDryRun, "dry-run", false, "List the tasks that would be deleted without deleting them")
 }
 
 type deleteResult struct {
-	Success bool   `json:"success"`
-	ID      string `json:"id"`
+	Success bool          `json:"success"`
+	ID      string        `json:"id"`
+	DryRun  bool          `json:"dryRun,omitempty"`
+	Deleted []deletedTask `json:"deleted"`
+}
+
+// deletedTask describes one task removed (or, with --dry-run, to be removed)
+type deletedTask struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Status string  `json:"status"`
+	Parent *string `json:"parent"`
+	depth  int
 }
 
 func runDelete(cmd *cobra.Command, args []string) error {
 	// Normalize and validate task ID
 	id := models.NormalizeTaskID(args[0])
 	if !models.IsValidTaskID(id) {
-		return fmt.Errorf("invalid task ID: %s", args[0])
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
 	}
 
 	// Load storage
@@ -42,51 +62,93 @@ func runDelete(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Load the task to verify it exists
-	_, err = store.LoadTask(id)
+	result := deleteResult{
+		Success: true,
+		ID:      id,
+		DryRun:  deleteDryRun,
+	}
+	err = store.Transaction(func(tx *storage.Storage) error {
+		var err error
+		result.Deleted, err = deleteTasks(tx, id, deleteRecursive, deleteDryRun)
+		return err
+	})
 	if err != nil {
-		if err == storage.ErrTaskNotFound {
-			return fmt.Errorf("task %s not found", id)
-		}
 		return err
 	}
 
-	// Check for undone children (recursive)
-	hasUndone, err := store.HasUndoneChildren(id)
+	return render(os.Stdout, outputMode(deletePretty), result, func() {
+		green := color.New(color.FgGreen)
+		if !deleteRecursive && !deleteDryRun {
+			green.Printf("Deleted task %s\n", id)
+			return
+		}
+		if deleteDryRun {
+			fmt.Printf("Would delete %d task(s):\n", len(result.Deleted))
+		} else {
+			green.Printf("Deleted %d task(s):\n", len(result.Deleted))
+		}
+		for _, t := range result.Deleted {
+			fmt.Printf("  %s%s  %s  [%s]\n", strings.Repeat("  ", t.depth), t.ID, t.Name, t.Status)
+		}
+	})
+}
+
+// deleteTasks deletes a task, or with recursive the task and its descendants,
+// and returns the deleted tasks in tree order. Without recursive, closed
+// children are orphaned. With dryRun nothing is changed.
+func deleteTasks(store *storage.Storage, id string, recursive, dryRun bool) ([]deletedTask, error) {
+	tasks, err := store.LoadAll()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if hasUndone {
-		return fmt.Errorf("cannot delete task: has undone children")
+	if findTask(tasks, id) == nil {
+		return nil, models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
 	}
 
-	// Orphan any children before deleting
-	if err := store.OrphanChildren(id); err != nil {
-		return err
-	}
+	var deleted []deletedTask
+	walkSubtree(tasks, id, func(task *models.Task, depth int) {
+		if recursive || depth == 0 {
+			deleted = append(deleted, deletedTask{ID: task.ID, Name: task.Name, Status: task.Status, Parent: task.Parent, depth: depth})
+		}
+	})
 
-	// Remove from all BlockedBy lists (mirrors done behavior in status.go)
-	if err := store.RemoveFromAllBlockedBy(id); err != nil {
-		return err
+	if !recursive {
+		// Check for undone children (recursive)
+		hasUndone, err := store.HasUndoneChildren(id)
+		if err != nil {
+			return nil, err
+		}
+		if hasUndone {
+			return nil, models.Errorf(models.CodeHasUndoneChildren, "cannot delete task: has undone children (use --recursive to delete them too)").With("id", id)
+		}
 	}
-
-	// Delete the task
-	if err := store.DeleteTask(id); err != nil {
-		return err
+	if dryRun {
+		return deleted, nil
 	}
 
-	result := deleteResult{
-		Success: true,
-		ID:      id,
+	if !recursive {
+		// Orphan any children before deleting
+		if err := store.OrphanChildren(id); err != nil {
+			return nil, err
+		}
 	}
 
-	if deletePretty {
-		green := color.New(color.FgGreen)
-		green.Printf("Deleted task %s\n", id)
-	} else {
-		out, _ := json.Marshal(result)
-		fmt.Println(string(out))
+	ids := make([]string, len(deleted))
+	for i, t := range deleted {
+		ids[i] = t.ID
+		// Remove from all BlockedBy lists (mirrors done behavior in status.go)
+		if err := store.RemoveFromAllBlockedBy(t.ID); err != nil {
+			return nil, err
+		}
+		// Drop links pointing at the task
+		if err := store.RemoveLinksTo(t.ID); err != nil {
+			return nil, err
+		}
 	}
 
-	return nil
+	// Delete the tasks
+	if err := store.DeleteTasks(ids); err != nil {
+		return nil, err
+	}
+	return deleted, nil
 }
@@ -280,3 +280,81 @@ func TestDeleteCommand_PrettyOutput(t *testing.T) {
 	err = runDelete(nil, []string{task.ID})
 	require.NoError(t, err)
 }
+
+func TestDeleteCommand_Recursive(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { deletePretty, deleteRecursive, deleteDryRun = false, false, false }()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	now := time.Now()
+	root, child := "aaaa", "bbbb"
+	for _, task := range []*models.Task{
+		{ID: "aaaa", Name: "Root", Status: models.StatusTodo},
+		{ID: "bbbb", Name: "Child", Parent: &root, Status: models.StatusTodo},
+		{ID: "cccc", Name: "Grandchild", Parent: &child, Status: models.StatusDone},
+		{ID: "dddd", Name: "Outside", BlockedBy: []string{"cccc"}, Links: []models.Link{{Type: models.LinkRelatesTo, Target: "bbbb"}}, Status: models.StatusTodo},
+	} {
+		task.Created, task.Updated = now, now
+		require.NoError(t, store.SaveTask(task))
+	}
+
+	// A dry run lists the subtree and changes nothing
+	deletePretty = false
+	deleteRecursive = true
+	deleteDryRun = true
+	require.NoError(t, runDelete(nil, []string{"aaaa"}))
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 4)
+
+	deletePretty = true
+	deleteDryRun = false
+	require.NoError(t, runDelete(nil, []string{"aaaa"}))
+	tasks, err = store.LoadAll()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+
+	// References to deleted tasks are cleaned up
+	outside := tasks[0]
+	assert.Equal(t, "dddd", outside.ID)
+	assert.Empty(t, outside.BlockedBy)
+	assert.Empty(t, outside.Links)
+}
+
+func TestDeleteTasks_Preview(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	now := time.Now()
+	root, child := "aaaa", "bbbb"
+	for _, task := range []*models.Task{
+		{ID: "aaaa", Name: "Root", Status: models.StatusTodo, Created: now},
+		{ID: "cccc", Name: "Second", Parent: &root, Status: models.StatusTodo, Created: now.Add(2 * time.Millisecond)},
+		{ID: "bbbb", Name: "First", Parent: &root, Status: models.StatusTodo, Created: now.Add(time.Millisecond)},
+		{ID: "dddd", Name: "Grandchild", Parent: &child, Status: models.StatusTodo, Created: now.Add(3 * time.Millisecond)},
+	} {
+		task.Updated = task.Created
+		require.NoError(t, store.SaveTask(task))
+	}
+
+	deleted, err := deleteTasks(store, "aaaa", true, true)
+	require.NoError(t, err)
+	var ids []string
+	var depths []int
+	for _, d := range deleted {
+		ids = append(ids, d.ID)
+		depths = append(depths, d.depth)
+	}
+	assert.Equal(t, []string{"aaaa", "bbbb", "dddd", "cccc"}, ids)
+	assert.Equal(t, []int{0, 1, 2, 1}, depths)
+
+	// Without --recursive, open descendants refuse the delete
+	_, err = deleteTasks(store, "aaaa", false, true)
+	assert.ErrorContains(t, err, "use --recursive")
+}
@@ -0,0 +1,421 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	editName        string
+	editDescription string
+	editAction      string
+	editVerify      string
+	editResult      string
+	editOutcome     string
+	editVerifyCmd   string
+	editRecur       string
+	editFields      []string
+	editUnsetFields []string
+	editEditor      bool
+	editPatch       bool
+	editPretty      bool
+)
+
+// editInput is where --patch reads the merge patch from (replaced in tests)
+var editInput io.Reader = os.Stdin
+
+var editCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Edit a task's fields",
+	Long: `Update a task's name, description, structured fields, outcome, verification command,
+recurrence rule or custom fields.
+
+Use per-field flags for quick changes, --editor to edit the task as YAML in $EDITOR,
+or --patch to apply an RFC 7396 JSON merge patch read from stdin, e.g.:
+
+  echo '{"name": "New name", "description": null}' | clipm edit abcd --patch
+
+ID, status, parent, owner, dependencies and notes are changed with their own commands.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEdit,
+}
+
+func init() {
+	editCmd.Flags().StringVar(&editName, "name", "", "New task name")
+	editCmd.Flags().StringVarP(&editDescription, "description", "d", "", "New task description")
+	editCmd.Flags().StringVar(&editAction, "action", "", "New action")
+	editCmd.Flags().StringVar(&editVerify, "verify", "", "New verification")
+	editCmd.Flags().StringVar(&editResult, "result", "", "New result template")
+	editCmd.Flags().StringVar(&editOutcome, "outcome", "", "New outcome")
+	editCmd.Flags().StringVar(&editVerifyCmd, "verify-cmd", "", "New verification command")
+	editCmd.Flags().StringVar(&editRecur, "recur", "", "New recurrence rule (on-done, interval like 1d, or cron); \"none\" removes it")
+	editCmd.Flags().StringArrayVar(&editFields, "field", nil, "Set custom field as key=value (repeatable)")
+	editCmd.Flags().StringArrayVar(&editUnsetFields, "unset-field", nil, "Remove custom field (repeatable)")
+	editCmd.Flags().BoolVar(&editEditor, "editor", false, "Edit the task as YAML in $EDITOR")
+	editCmd.Flags().BoolVar(&editPatch, "patch", false, "Apply a JSON merge patch read from stdin")
+	editCmd.Flags().BoolVar(&editPretty, "pretty", false, "Pretty print output")
+}
+
+// taskEdit is the editable subset of a task, as shown in the editor and patched by --patch
+type taskEdit struct {
+	Name        string         `json:"name" yaml:"name"`
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Action      string         `json:"action,omitempty" yaml:"action,omitempty"`
+	Verify      string         `json:"verify,omitempty" yaml:"verify,omitempty"`
+	Result      string         `json:"result,omitempty" yaml:"result,omitempty"`
+	Outcome     string         `json:"outcome,omitempty" yaml:"outcome,omitempty"`
+	VerifyCmd   string         `json:"verifyCmd,omitempty" yaml:"verifyCmd,omitempty"`
+	Recur       string         `json:"recur,omitempty" yaml:"recur,omitempty"`
+	Fields      map[string]any `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	id := models.NormalizeTaskID(args[0])
+	if !models.IsValidTaskID(id) {
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
+	}
+
+	if err := validateEditFlags(); err != nil {
+		return err
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	task, err := store.LoadTask(id)
+	if err != nil {
+		if err == storage.ErrTaskNotFound {
+			return models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+		}
+		return err
+	}
+
+	cfg, err := store.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	current := taskEditFrom(task)
+	var edited *taskEdit
+	switch {
+	case editEditor:
+		edited, err = editInEditor(current)
+	case editPatch:
+		edited, err = applyMergePatch(current, editInput)
+	default:
+		edited, err = applyEditFlags(current, cfg.Fields)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := validateTaskEdit(task, edited, cfg.Fields); err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(current, edited) {
+		return render(os.Stdout, outputMode(editPretty), task, func() {
+			fmt.Printf("No changes to task %s\n", task.ID)
+		})
+	}
+
+	if err := edited.applyTo(task, time.Now()); err != nil {
+		return err
+	}
+	task.Updated = time.Now()
+
+	if err := store.SaveTask(task); err != nil {
+		return err
+	}
+
+	return render(os.Stdout, outputMode(editPretty), task, func() {
+		green := color.New(color.FgGreen)
+		green.Printf("Updated task %s\n", task.ID)
+	})
+}
+
+func validateEditFlags() error {
+	hasFieldFlags := editName != "" || editDescription != "" || editAction != "" ||
+		editVerify != "" || editResult != "" || editOutcome != "" || editVerifyCmd != "" ||
+		editRecur != "" || len(editFields) > 0 || len(editUnsetFields) > 0
+
+	if editEditor && editPatch {
+		return models.Errorf(models.CodeInvalidArgument, "--editor and --patch are mutually exclusive")
+	}
+	if (editEditor || editPatch) && hasFieldFlags {
+		return models.Errorf(models.CodeInvalidArgument, "field flags cannot be combined with --editor or --patch")
+	}
+	if !editEditor && !editPatch && !hasFieldFlags {
+		return models.Errorf(models.CodeInvalidArgument, "nothing to edit: pass field flags, --editor or --patch")
+	}
+	return nil
+}
+
+func taskEditFrom(task *models.Task) *taskEdit {
+	edit := &taskEdit{
+		Name:        task.Name,
+		Description: task.Description,
+		Action:      task.Action,
+		Verify:      task.Verify,
+		Result:      task.Result,
+		Outcome:     task.Outcome,
+		VerifyCmd:   task.VerifyCmd,
+	}
+	if task.Recurrence != nil {
+		edit.Recur = task.Recurrence.Spec
+	}
+	if len(task.Fields) > 0 {
+		edit.Fields = make(map[string]any, len(task.Fields))
+		for k, v := range task.Fields {
+			edit.Fields[k] = v
+		}
+	}
+	return edit
+}
+
+// applyTo copies the edit onto task. A changed recurrence rule is rescheduled from now.
+func (e *taskEdit) applyTo(task *models.Task, now time.Time) error {
+	current := ""
+	if task.Recurrence != nil {
+		current = task.Recurrence.Spec
+	}
+	if e.Recur != current {
+		if e.Recur == "" {
+			task.Recurrence = nil
+		} else {
+			rule, err := models.ParseRecurrence(e.Recur, now)
+			if err != nil {
+				return err
+			}
+			task.Recurrence = rule
+		}
+	}
+
+	task.Name = e.Name
+	task.Description = e.Description
+	task.Action = e.Action
+	task.Verify = e.Verify
+	task.Result = e.Result
+	task.Outcome = e.Outcome
+	task.VerifyCmd = e.VerifyCmd
+	task.Fields = e.Fields
+	return nil
+}
+
+func applyEditFlags(current *taskEdit, schema models.FieldSchema) (*taskEdit, error) {
+	edited := *current
+	if editName != "" {
+		edited.Name = editName
+	}
+	if editDescription != "" {
+		edited.Description = editDescription
+	}
+	if editAction != "" {
+		edited.Action = editAction
+	}
+	if editVerify != "" {
+		edited.Verify = editVerify
+	}
+	if editResult != "" {
+		edited.Result = editResult
+	}
+	if editOutcome != "" {
+		edited.Outcome = editOutcome
+	}
+	if editVerifyCmd != "" {
+		edited.VerifyCmd = editVerifyCmd
+	}
+	if editRecur == "none" {
+		edited.Recur = ""
+	} else if editRecur != "" {
+		edited.Recur = editRecur
+	}
+
+	fields, err := parseFieldFlags(schema, editFields)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 || len(editUnsetFields) > 0 {
+		merged := make(map[string]any, len(current.Fields)+len(fields))
+		for k, v := range current.Fields {
+			merged[k] = v
+		}
+		for k, v := range fields {
+			merged[k] = v
+		}
+		for _, k := range editUnsetFields {
+			if _, ok := merged[k]; !ok {
+				return nil, models.Errorf(models.CodeInvalidArgument, "task has no field %q", k)
+			}
+			delete(merged, k)
+		}
+		if len(merged) == 0 {
+			merged = nil
+		}
+		edited.Fields = merged
+	}
+	return &edited, nil
+}
+
+func validateTaskEdit(task *models.Task, edited *taskEdit, schema models.FieldSchema) error {
+	if strings.TrimSpace(edited.Name) == "" {
+		return models.Errorf(models.CodeInvalidArgument, "task name cannot be empty")
+	}
+	if task.HasStructuredFields() && (edited.Action == "" || edited.Verify == "" || edited.Result == "") {
+		return models.Errorf(models.CodeConflict, "structured task %s must keep action, verify and result", task.ID)
+	}
+	return schema.Validate(edited.Fields)
+}
+
+// editInEditor writes the task as YAML to a temp file, opens it in the user's
+// editor and parses the saved result
+func editInEditor(current *taskEdit) (*taskEdit, error) {
+	data, err := yaml.Marshal(current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task: %w", err)
+	}
+
+	f, err := os.CreateTemp("", "clipm-edit-*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	path := f.Name()
+	defer os.Remove(path)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	// Run through the shell so editors with arguments (e.g. "code --wait") work
+	editorCmd := exec.Command("sh", "-c", editorCommand()+` "$1"`, "sh", path) // #nosec G204 -- editor is chosen by the user
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor failed: %w", err)
+	}
+
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read edited task: %w", err)
+	}
+
+	var edited taskEdit
+	dec := yaml.NewDecoder(bytes.NewReader(saved))
+	dec.KnownFields(true)
+	if err := dec.Decode(&edited); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, models.Errorf(models.CodeInvalidArgument, "edited task is empty")
+		}
+		return nil, models.Errorf(models.CodeInvalidArgument, "invalid task YAML: %w", err)
+	}
+	if len(edited.Fields) == 0 {
+		edited.Fields = nil
+	}
+	return &edited, nil
+}
+
+func editorCommand() string {
+	if editor := os.Getenv("VISUAL"); editor != "" {
+		return editor
+	}
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	return "vi"
+}
+
+// applyMergePatch applies an RFC 7396 JSON merge patch to the editable task
+func applyMergePatch(current *taskEdit, r io.Reader) (*taskEdit, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patch: %w", err)
+	}
+
+	var patch map[string]any
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, models.Errorf(models.CodeInvalidArgument, "invalid merge patch: must be a JSON object: %w", err)
+	}
+
+	editable := map[string]bool{
+		"name": true, "description": true, "action": true, "verify": true,
+		"result": true, "outcome": true, "verifyCmd": true, "recur": true, "fields": true,
+	}
+	keys := make([]string, 0, len(patch))
+	for k := range patch {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !editable[k] {
+			return nil, models.Errorf(models.CodeInvalidArgument, "field %q cannot be changed with edit", k)
+		}
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	var target any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+
+	merged, err := json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return nil, err
+	}
+
+	var edited taskEdit
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&edited); err != nil {
+		return nil, models.Errorf(models.CodeInvalidArgument, "invalid merge patch: %w", err)
+	}
+	if len(edited.Fields) == 0 {
+		edited.Fields = nil
+	}
+	return &edited, nil
+}
+
+// mergePatch implements RFC 7396: objects merge recursively, null removes a
+// member, and any other value replaces the target
+func mergePatch(target, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = make(map[string]any)
+	}
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+		} else {
+			targetObj[k] = mergePatch(targetObj[k], v)
+		}
+	}
+	return targetObj
+}
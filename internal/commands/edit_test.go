@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetEditFlags() {
+	editName = ""
+	editDescription = ""
+	editAction = ""
+	editVerify = ""
+	editResult = ""
+	editOutcome = ""
+	editVerifyCmd = ""
+	editRecur = ""
+	editFields = nil
+	editUnsetFields = nil
+	editEditor = false
+	editPatch = false
+	editPretty = false
+}
+
+func createStructuredTask(t *testing.T, store *storage.Storage) *models.Task {
+	now := time.Now()
+	task := &models.Task{
+		ID:          "aaaa",
+		Name:        "Original",
+		Description: "Old description",
+		Action:      "do it",
+		Verify:      "check it",
+		Result:      "report it",
+		Status:      models.StatusTodo,
+		Created:     now,
+		Updated:     now,
+	}
+	require.NoError(t, store.SaveTask(task))
+	return task
+}
+
+func TestEditCommand_Flags(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetEditFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createStructuredTask(t, store)
+
+	resetEditFlags()
+	editName = "Renamed"
+	editAction = "do it better"
+	editFields = []string{"ticket=ENG-7"}
+
+	require.NoError(t, runEdit(nil, []string{"AAAA"}))
+
+	updated, err := store.LoadTask(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.Name)
+	assert.Equal(t, "Old description", updated.Description)
+	assert.Equal(t, "do it better", updated.Action)
+	assert.Equal(t, "check it", updated.Verify)
+	assert.Equal(t, "ENG-7", updated.Fields["ticket"])
+	assert.True(t, updated.Updated.After(task.Updated))
+
+	resetEditFlags()
+	editUnsetFields = []string{"ticket"}
+	require.NoError(t, runEdit(nil, []string{task.ID}))
+	updated, err = store.LoadTask(task.ID)
+	require.NoError(t, err)
+	assert.Empty(t, updated.Fields)
+
+	resetEditFlags()
+	editUnsetFields = []string{"ticket"}
+	assert.ErrorContains(t, runEdit(nil, []string{task.ID}), "has no field")
+}
+
+func TestEditCommand_Validation(t *testing.T) {
+	tmpDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetEditFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createStructuredTask(t, store)
+
+	resetEditFlags()
+	assert.ErrorContains(t, runEdit(nil, []string{task.ID}), "nothing to edit")
+
+	resetEditFlags()
+	editEditor = true
+	editPatch = true
+	assert.ErrorContains(t, runEdit(nil, []string{task.ID}), "mutually exclusive")
+
+	resetEditFlags()
+	editPatch = true
+	editName = "x"
+	assert.ErrorContains(t, runEdit(nil, []string{task.ID}), "cannot be combined")
+
+	resetEditFlags()
+	editName = "x"
+	assert.ErrorContains(t, runEdit(nil, []string{"zzzz"}), "not found")
+	assert.ErrorContains(t, runEdit(nil, []string{"bad-id"}), "invalid task ID")
+
+	writeTestConfig(t, tmpDir, testFieldSchema)
+	resetEditFlags()
+	editFields = []string{"risk=extreme"}
+	assert.ErrorContains(t, runEdit(nil, []string{task.ID}), "must be one of")
+}
+
+func TestEditCommand_Patch(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetEditFlags()
+	origInput := editInput
+	defer func() { editInput = origInput }()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createStructuredTask(t, store)
+
+	resetEditFlags()
+	editPatch = true
+	editInput = strings.NewReader(`{"name": "Patched", "description": null, "fields": {"component": "api"}}`)
+	require.NoError(t, runEdit(nil, []string{task.ID}))
+
+	updated, err := store.LoadTask(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Patched", updated.Name)
+	assert.Empty(t, updated.Description)
+	assert.Equal(t, "do it", updated.Action)
+	assert.Equal(t, map[string]any{"component": "api"}, updated.Fields)
+
+	// Nested objects merge; null removes a single field
+	editInput = strings.NewReader(`{"fields": {"component": null, "team": "core"}}`)
+	require.NoError(t, runEdit(nil, []string{task.ID}))
+	updated, err = store.LoadTask(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"team": "core"}, updated.Fields)
+
+	editInput = strings.NewReader(`{"status": "done"}`)
+	assert.ErrorContains(t, runEdit(nil, []string{task.ID}), "cannot be changed with edit")
+
+	editInput = strings.NewReader(`{"action": null}`)
+	assert.ErrorContains(t, runEdit(nil, []string{task.ID}), "must keep action, verify and result")
+
+	editInput = strings.NewReader(`[1, 2]`)
+	assert.ErrorContains(t, runEdit(nil, []string{task.ID}), "must be a JSON object")
+}
+
+func TestEditCommand_Editor(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetEditFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createStructuredTask(t, store)
+
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", `sed -i -e 's/^name: .*/name: From editor/'`)
+
+	resetEditFlags()
+	editEditor = true
+	require.NoError(t, runEdit(nil, []string{task.ID}))
+
+	updated, err := store.LoadTask(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "From editor", updated.Name)
+	assert.Equal(t, "Old description", updated.Description)
+
+	// Invalid YAML keys are rejected and nothing is saved
+	t.Setenv("EDITOR", `sed -i -e 's/^name:/title:/'`)
+	assert.ErrorContains(t, runEdit(nil, []string{task.ID}), "invalid task YAML")
+	updated, err = store.LoadTask(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "From editor", updated.Name)
+}
+
+func TestMergePatch(t *testing.T) {
+	target := map[string]any{"a": "b", "c": map[string]any{"d": "e", "f": "g"}}
+	patch := map[string]any{"a": "z", "c": map[string]any{"f": nil}}
+	assert.Equal(t, map[string]any{"a": "z", "c": map[string]any{"d": "e"}}, mergePatch(target, patch))
+
+	// Non-object patches replace the target
+	assert.Equal(t, []any{"x"}, mergePatch(map[string]any{"a": 1}, []any{"x"}))
+}
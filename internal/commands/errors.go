@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// exitCodes maps each error code to the process exit status
+var exitCodes = map[models.ErrorCode]int{
+	models.CodeInternal:           1,
+	models.CodeInvalidArgument:    2,
+	models.CodeInvalidID:          3,
+	models.CodeNotFound:           4,
+	models.CodeBlocked:            5,
+	models.CodeHasUndoneChildren:  6,
+	models.CodeConflict:           7,
+	models.CodeLocked:             8,
+	models.CodeNotInProject:       9,
+	models.CodeVerificationFailed: 10,
+}
+
+// errorResponse is what a failed command prints to stderr when its output is
+// JSON, NDJSON or YAML
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code    models.ErrorCode `json:"code"`
+	Message string           `json:"message"`
+	Details map[string]any   `json:"details,omitempty"`
+}
+
+// commandStarted is set once cobra has accepted a command's flags and
+// arguments. Uncoded errors before that are usage errors.
+var commandStarted bool
+
+// reportError prints err for the command that failed and returns the exit
+// code for it
+func reportError(w io.Writer, cmd *cobra.Command, err error) int {
+	usage := false
+	if !commandStarted && models.ErrorCodeOf(err) == models.CodeInternal {
+		err = models.Errorf(models.CodeInvalidArgument, "%w", err)
+		usage = true
+	}
+	code := models.ErrorCodeOf(err)
+
+	mode := outputJSON
+	if validateOutputFlag() == nil && cmd != nil {
+		pretty, _ := cmd.Flags().GetBool("pretty")
+		mode = outputMode(pretty)
+	}
+
+	switch mode {
+	case outputJSON, outputNDJSON, outputYAML:
+		resp := errorResponse{Error: errorBody{Code: code, Message: err.Error(), Details: models.ErrorDetailsOf(err)}}
+		if render(w, mode, resp, nil) == nil {
+			break
+		}
+		fallthrough
+	default:
+		fmt.Fprintf(w, "Error: %s\n", err)
+		if usage && cmd != nil {
+			fmt.Fprintf(w, "\n%s", cmd.UsageString())
+		}
+	}
+
+	if exit, ok := exitCodes[code]; ok {
+		return exit
+	}
+	return exitCodes[models.CodeInternal]
+}
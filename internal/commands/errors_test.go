@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetErrorState() {
+	commandStarted = false
+	outputFlag = ""
+	statusPretty = false
+}
+
+func TestReportError_JSON(t *testing.T) {
+	defer resetErrorState()
+	commandStarted = true
+
+	var buf bytes.Buffer
+	err := models.Errorf(models.CodeBlocked, "cannot start task abcd").With("id", "abcd")
+	assert.Equal(t, 5, reportError(&buf, statusCmd, err))
+
+	var resp errorResponse
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
+	assert.Equal(t, models.CodeBlocked, resp.Error.Code)
+	assert.Equal(t, "cannot start task abcd", resp.Error.Message)
+	assert.Equal(t, map[string]any{"id": "abcd"}, resp.Error.Details)
+}
+
+func TestReportError_ExitCodes(t *testing.T) {
+	defer resetErrorState()
+	commandStarted = true
+
+	var buf bytes.Buffer
+	assert.Equal(t, 1, reportError(&buf, statusCmd, errors.New("boom")))
+	assert.Equal(t, 4, reportError(&buf, statusCmd, storage.ErrTaskNotFound))
+	assert.Equal(t, 8, reportError(&buf, statusCmd, storage.ErrLocked))
+	assert.Equal(t, 9, reportError(&buf, statusCmd, storage.ErrNotInProject))
+
+	// Every code has its own exit status
+	seen := map[int]bool{}
+	for _, exit := range exitCodes {
+		assert.False(t, seen[exit], "exit code %d used twice", exit)
+		seen[exit] = true
+	}
+}
+
+func TestReportError_Pretty(t *testing.T) {
+	defer resetErrorState()
+	commandStarted = true
+	statusPretty = true
+
+	var buf bytes.Buffer
+	assert.Equal(t, 6, reportError(&buf, statusCmd, models.Errorf(models.CodeHasUndoneChildren, "has undone children")))
+	assert.Equal(t, "Error: has undone children\n", buf.String())
+
+	// --output overrides --pretty
+	outputFlag = outputYAML
+	buf.Reset()
+	reportError(&buf, statusCmd, models.Errorf(models.CodeHasUndoneChildren, "has undone children"))
+	assert.Equal(t, "error:\n  code: has_undone_children\n  message: has undone children\n", buf.String())
+}
+
+func TestReportError_Usage(t *testing.T) {
+	defer resetErrorState()
+
+	// Uncoded errors before the command runs come from cobra's flag and
+	// argument checks
+	var buf bytes.Buffer
+	assert.Equal(t, 2, reportError(&buf, statusCmd, errors.New("accepts 2 arg(s), received 0")))
+	assert.Contains(t, buf.String(), `"code":"invalid_argument"`)
+
+	statusPretty = true
+	buf.Reset()
+	reportError(&buf, statusCmd, errors.New("accepts 2 arg(s), received 0"))
+	assert.Contains(t, buf.String(), "Error: accepts 2 arg(s), received 0\n\nUsage:")
+}
+
+func TestReportError_AddMissingStructuredFields(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetErrorState()
+	defer resetAddFlags()
+
+	resetAddFlags()
+	commandStarted = true
+	err := runAdd(nil, []string{"Task"})
+	require.Error(t, err)
+
+	// Missing required flags are usage errors, as they were under cobra
+	var buf bytes.Buffer
+	assert.Equal(t, 2, reportError(&buf, addCmd, err))
+	var resp errorResponse
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
+	assert.Equal(t, models.CodeInvalidArgument, resp.Error.Code)
+	assert.Contains(t, resp.Error.Message, `"action", "verify", "result" not set`)
+}
@@ -0,0 +1,240 @@
+package commands
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat   string
+	exportSubtree  string
+	exportShowAll  bool
+	exportOutcomes bool
+	exportNotes    bool
+	exportWhere    string
+)
+
+// Export formats
+const (
+	exportMarkdown  = "markdown"
+	exportCSV       = "csv"
+	exportChecklist = "checklist"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export tasks as Markdown, CSV or a GitHub checklist",
+	Long: `Print the task tree in a format for pasting into documents or committing
+alongside code: markdown (nested list), csv (one row per task) or checklist
+(GitHub task list). Output depends only on the tasks, so exporting an unchanged
+store gives identical output.`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", exportMarkdown, "Output format: markdown, csv, checklist")
+	exportCmd.Flags().StringVar(&exportSubtree, "subtree", "", "Only export this task and its descendants")
+	exportCmd.Flags().BoolVar(&exportShowAll, "show-all", false, "Include all tasks including completed")
+	exportCmd.Flags().BoolVar(&exportOutcomes, "outcomes", false, "Include task outcomes")
+	exportCmd.Flags().BoolVar(&exportNotes, "notes", false, "Include task notes")
+	exportCmd.Flags().StringVar(&exportWhere, "where", "", "Only include tasks matching a query, e.g. \"status:todo owner:none\"")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	switch exportFormat {
+	case exportMarkdown, exportCSV, exportChecklist:
+	default:
+		return models.Errorf(models.CodeInvalidArgument, "invalid format %q. Must be: markdown, csv, checklist", exportFormat)
+	}
+	if outputFlag != "" {
+		return models.Errorf(models.CodeInvalidArgument, "export does not support --output; use --format")
+	}
+
+	var query *models.Query
+	if exportWhere != "" {
+		var err error
+		if query, err = models.ParseQuery(exportWhere); err != nil {
+			return err
+		}
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	tasks, err := store.LoadAll()
+	if err != nil {
+		return err
+	}
+
+	var root *models.Task
+	if exportSubtree != "" {
+		id := models.NormalizeTaskID(exportSubtree)
+		if !models.IsValidTaskID(id) {
+			return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", exportSubtree).With("id", exportSubtree)
+		}
+		if root = findTask(tasks, id); root == nil {
+			return models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+		}
+	}
+
+	all := tasks
+	if !exportShowAll {
+		tasks = filterCompletedTasks(tasks)
+	}
+	if query != nil {
+		// Ancestors are kept so matches are exported in their place in the tree
+		tasks = withAncestors(filterWhere(tasks, all, query), all)
+	}
+
+	taskMap := make(map[string]models.Task)
+	for i := range tasks {
+		taskMap[tasks[i].ID] = tasks[i]
+	}
+
+	// The subtree root is exported even when it would otherwise be hidden
+	var roots []models.Task
+	if root != nil {
+		roots = []models.Task{*root}
+	} else {
+		for i := range tasks {
+			if tasks[i].Parent == nil {
+				roots = append(roots, tasks[i])
+			}
+		}
+		models.SortByRank(roots)
+	}
+
+	return writeExport(os.Stdout, exportFormat, roots, taskMap)
+}
+
+func findTask(tasks []models.Task, id string) *models.Task {
+	for i := range tasks {
+		if tasks[i].ID == id {
+			return &tasks[i]
+		}
+	}
+	return nil
+}
+
+// writeExport writes roots and their descendants in the given format
+func writeExport(w io.Writer, format string, roots []models.Task, taskMap map[string]models.Task) error {
+	if format == exportCSV {
+		return writeExportCSV(w, roots, taskMap)
+	}
+
+	var b strings.Builder
+	walkTree(roots, taskMap, func(task *models.Task, depth int) {
+		indent := strings.Repeat("  ", depth)
+		if format == exportChecklist {
+			// Cancelled tasks are ticked and struck through, so they read as
+			// neither open nor done in a GitHub task list
+			mark, name := " ", oneLine(task.Name)
+			switch task.Status {
+			case models.StatusDone:
+				mark = "x"
+			case models.StatusCancelled:
+				mark, name = "x", "~~"+name+"~~"
+			}
+			fmt.Fprintf(&b, "%s- [%s] %s (`%s`)\n", indent, mark, name, task.ID)
+		} else {
+			fmt.Fprintf(&b, "%s- **%s** (`%s`) - %s", indent, oneLine(task.Name), task.ID, task.Status)
+			if task.Owner != nil {
+				fmt.Fprintf(&b, ", @%s", *task.Owner)
+			}
+			b.WriteString("\n")
+		}
+
+		if exportOutcomes && task.Outcome != "" {
+			fmt.Fprintf(&b, "%s  - Outcome: %s\n", indent, oneLine(task.Outcome))
+		}
+		if exportNotes {
+			for _, note := range task.Notes {
+				fmt.Fprintf(&b, "%s  - %s\n", indent, formatExportNote(&note))
+			}
+		}
+	})
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeExportCSV(w io.Writer, roots []models.Task, taskMap map[string]models.Task) error {
+	header := []string{"id", "name", "status", "parent", "depth", "owner", "blockedBy", "created", "updated"}
+	if exportOutcomes {
+		header = append(header, "outcome")
+	}
+	if exportNotes {
+		header = append(header, "notes")
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	var writeErr error
+	walkTree(roots, taskMap, func(task *models.Task, depth int) {
+		parent, owner := "", ""
+		if task.Parent != nil {
+			parent = *task.Parent
+		}
+		if task.Owner != nil {
+			owner = *task.Owner
+		}
+		row := []string{
+			task.ID,
+			task.Name,
+			task.Status,
+			parent,
+			fmt.Sprint(depth),
+			owner,
+			strings.Join(task.BlockedBy, " "),
+			task.Created.UTC().Format(time.RFC3339),
+			task.Updated.UTC().Format(time.RFC3339),
+		}
+		if exportOutcomes {
+			row = append(row, task.Outcome)
+		}
+		if exportNotes {
+			notes := make([]string, len(task.Notes))
+			for i := range task.Notes {
+				notes[i] = formatExportNote(&task.Notes[i])
+			}
+			row = append(row, strings.Join(notes, "\n"))
+		}
+		if err := cw.Write(row); err != nil && writeErr == nil {
+			writeErr = err
+		}
+	})
+	if writeErr != nil {
+		return writeErr
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatExportNote renders a note as "date kind (author): content" in UTC
+func formatExportNote(note *models.Note) string {
+	s := note.Timestamp.UTC().Format("2006-01-02 15:04") + " " + note.EffectiveKind()
+	if note.Author != "" {
+		s += " (" + note.Author + ")"
+	}
+	return s + ": " + oneLine(note.Content)
+}
+
+// oneLine collapses line breaks so multi-line text stays inside one list item
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
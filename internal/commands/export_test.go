@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetExportFlags() {
+	exportFormat = exportMarkdown
+	exportSubtree = ""
+	exportShowAll = false
+	exportOutcomes = false
+	exportNotes = false
+}
+
+func exportFixture() ([]models.Task, map[string]models.Task) {
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	root := "aaaa"
+	owner := "agent-1"
+	tasks := []models.Task{
+		{ID: "aaaa", Name: "Auth system", Status: models.StatusInProgress, Owner: &owner, Created: created, Updated: created},
+		{ID: "cccc", Name: "Token refresh", Parent: &root, Status: models.StatusTodo, BlockedBy: []string{"bbbb"}, Created: created.Add(time.Minute), Updated: created},
+		{ID: "bbbb", Name: "Login\nhandler", Parent: &root, Status: models.StatusDone, Outcome: "POST /login added", Created: created.Add(time.Minute), Updated: created,
+			Notes: []models.Note{{ID: 1, Content: "Reused the session store", Kind: models.NoteDecision, Author: "agent-1", Timestamp: created}}},
+	}
+	taskMap := make(map[string]models.Task)
+	for i := range tasks {
+		taskMap[tasks[i].ID] = tasks[i]
+	}
+	return tasks[:1], taskMap
+}
+
+func TestWriteExport_Markdown(t *testing.T) {
+	defer resetExportFlags()
+	roots, taskMap := exportFixture()
+
+	resetExportFlags()
+	var buf bytes.Buffer
+	require.NoError(t, writeExport(&buf, exportMarkdown, roots, taskMap))
+	// Siblings created at the same time are ordered by ID
+	assert.Equal(t, "- **Auth system** (`aaaa`) - in-progress, @agent-1\n"+
+		"  - **Login handler** (`bbbb`) - done\n"+
+		"  - **Token refresh** (`cccc`) - todo\n", buf.String())
+
+	exportOutcomes = true
+	exportNotes = true
+	buf.Reset()
+	require.NoError(t, writeExport(&buf, exportMarkdown, roots, taskMap))
+	assert.Contains(t, buf.String(), "  - **Login handler** (`bbbb`) - done\n"+
+		"    - Outcome: POST /login added\n"+
+		"    - 2026-03-01 09:00 decision (agent-1): Reused the session store\n")
+}
+
+func TestWriteExport_Checklist(t *testing.T) {
+	defer resetExportFlags()
+	roots, taskMap := exportFixture()
+
+	resetExportFlags()
+	var buf bytes.Buffer
+	require.NoError(t, writeExport(&buf, exportChecklist, roots, taskMap))
+	assert.Equal(t, "- [ ] Auth system (`aaaa`)\n"+
+		"  - [x] Login handler (`bbbb`)\n"+
+		"  - [ ] Token refresh (`cccc`)\n", buf.String())
+
+	// Cancelled tasks are struck through rather than left open
+	cancelled := taskMap["cccc"]
+	cancelled.Status = models.StatusCancelled
+	taskMap["cccc"] = cancelled
+	buf.Reset()
+	require.NoError(t, writeExport(&buf, exportChecklist, roots, taskMap))
+	assert.Contains(t, buf.String(), "  - [x] ~~Token refresh~~ (`cccc`)\n")
+}
+
+func TestWriteExport_CSV(t *testing.T) {
+	defer resetExportFlags()
+	roots, taskMap := exportFixture()
+
+	resetExportFlags()
+	exportOutcomes = true
+	var buf bytes.Buffer
+	require.NoError(t, writeExport(&buf, exportCSV, roots, taskMap))
+	assert.Equal(t, "id,name,status,parent,depth,owner,blockedBy,created,updated,outcome\n"+
+		"aaaa,Auth system,in-progress,,0,agent-1,,2026-03-01T09:00:00Z,2026-03-01T09:00:00Z,\n"+
+		"bbbb,\"Login\nhandler\",done,aaaa,1,,,2026-03-01T09:01:00Z,2026-03-01T09:00:00Z,POST /login added\n"+
+		"cccc,Token refresh,todo,aaaa,1,,bbbb,2026-03-01T09:01:00Z,2026-03-01T09:00:00Z,\n", buf.String())
+}
+
+func TestExportCommand(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetExportFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createStructuredTask(t, store)
+
+	resetExportFlags()
+	require.NoError(t, runExport(nil, nil))
+
+	exportSubtree = task.ID
+	exportFormat = exportCSV
+	require.NoError(t, runExport(nil, nil))
+
+	exportSubtree = "zzzz"
+	assert.ErrorContains(t, runExport(nil, nil), "not found")
+
+	resetExportFlags()
+	exportFormat = "pdf"
+	assert.ErrorContains(t, runExport(nil, nil), "invalid format")
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"sort"
+
+	"github.com/simonspoon/clipm/internal/models"
+)
+
+// parseFieldFlags converts repeated --field key=value arguments into typed
+// values using the project schema. Later assignments to the same key win.
+func parseFieldFlags(schema models.FieldSchema, assignments []string) (map[string]any, error) {
+	if len(assignments) == 0 {
+		return nil, nil
+	}
+	fields := make(map[string]any, len(assignments))
+	for _, arg := range assignments {
+		key, raw, err := models.ParseFieldAssignment(arg)
+		if err != nil {
+			return nil, err
+		}
+		value, err := schema.ParseValue(key, raw)
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = value
+	}
+	return fields, nil
+}
+
+// filterByFields keeps tasks whose custom fields match every key=value filter.
+// Values are compared in their command-line form, so "3" matches a numeric 3.
+func filterByFields(tasks []models.Task, filters []string) ([]models.Task, error) {
+	type match struct{ key, value string }
+	matches := make([]match, 0, len(filters))
+	for _, arg := range filters {
+		key, value, err := models.ParseFieldAssignment(arg)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match{key, value})
+	}
+
+	var filtered []models.Task
+	for i := range tasks {
+		ok := true
+		for _, m := range matches {
+			v, exists := tasks[i].Fields[m.key]
+			if !exists || models.FormatFieldValue(v) != m.value {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			filtered = append(filtered, tasks[i])
+		}
+	}
+	return filtered, nil
+}
+
+// sortedFieldNames returns the keys of a task's custom fields in sorted order
+func sortedFieldNames(fields map[string]any) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	path := filepath.Join(dir, storage.ClipmDir, storage.ConfigFile)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+const testFieldSchema = `fields:
+  ticket:
+    type: string
+  points:
+    type: number
+  risk:
+    type: enum
+    values: [low, high]
+    required: true
+`
+
+func TestParseFieldFlags(t *testing.T) {
+	schema := models.FieldSchema{
+		"points": {Type: models.FieldTypeNumber},
+		"risk":   {Type: models.FieldTypeEnum, Values: []string{"low", "high"}},
+	}
+
+	fields, err := parseFieldFlags(schema, []string{"points=3", "risk=low", "risk=high"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"points": float64(3), "risk": "high"}, fields)
+
+	fields, err = parseFieldFlags(schema, nil)
+	require.NoError(t, err)
+	assert.Nil(t, fields)
+
+	_, err = parseFieldFlags(schema, []string{"points"})
+	assert.ErrorContains(t, err, "expected key=value")
+
+	_, err = parseFieldFlags(schema, []string{"risk=medium"})
+	assert.ErrorContains(t, err, "must be one of")
+}
+
+func TestFilterByFields(t *testing.T) {
+	tasks := []models.Task{
+		{ID: "aaaa", Fields: map[string]any{"risk": "high", "points": float64(3)}},
+		{ID: "aaab", Fields: map[string]any{"risk": "low", "points": float64(3)}},
+		{ID: "aaac"},
+	}
+
+	filtered, err := filterByFields(tasks, []string{"points=3"})
+	require.NoError(t, err)
+	assert.Len(t, filtered, 2)
+
+	filtered, err = filterByFields(tasks, []string{"points=3", "risk=high"})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "aaaa", filtered[0].ID)
+
+	_, err = filterByFields(tasks, []string{"risk"})
+	assert.Error(t, err)
+}
+
+func TestAddCommand_WithFields(t *testing.T) {
+	tmpDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { addFields = nil }()
+
+	writeTestConfig(t, tmpDir, testFieldSchema)
+
+	addDescription = ""
+	addParent = ""
+	addPretty = false
+	addAction = "do something"
+	addVerify = "check something"
+	addResult = "report something"
+	addFields = []string{"ticket=ENG-42", "points=5", "risk=high"}
+
+	require.NoError(t, runAdd(nil, []string{"Typed task"}))
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "ENG-42", tasks[0].Fields["ticket"])
+	assert.Equal(t, float64(5), tasks[0].Fields["points"])
+	assert.Equal(t, "high", tasks[0].Fields["risk"])
+}
+
+func TestAddCommand_FieldValidation(t *testing.T) {
+	tmpDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { addFields = nil }()
+
+	writeTestConfig(t, tmpDir, testFieldSchema)
+
+	addDescription = ""
+	addParent = ""
+	addPretty = false
+	addAction = "do something"
+	addVerify = "check something"
+	addResult = "report something"
+
+	addFields = []string{"ticket=ENG-42"}
+	assert.ErrorContains(t, runAdd(nil, []string{"Missing risk"}), "missing required field \"risk\"")
+
+	addFields = []string{"risk=low", "component=api"}
+	assert.ErrorContains(t, runAdd(nil, []string{"Unknown field"}), "unknown field \"component\"")
+
+	addFields = []string{"risk=low", "points=lots"}
+	assert.ErrorContains(t, runAdd(nil, []string{"Bad number"}), "must be a number")
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
+func TestListCommand_FieldFilter(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { listFields = nil }()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	id := createTestTask(t, store, "Backend", models.StatusTodo, nil)
+	task, err := store.LoadTask(id)
+	require.NoError(t, err)
+	task.Fields = map[string]any{"component": "api"}
+	require.NoError(t, store.SaveTask(task))
+	createTestTask(t, store, "Frontend", models.StatusTodo, nil)
+
+	listStatus = ""
+	listOwner = ""
+	listUnclaimed = false
+	listBlocked = false
+	listUnblocked = false
+	listShowAll = false
+	listPretty = false
+	listFields = []string{"component=api"}
+
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	filtered, err := applyListFilters(tasks, store)
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "Backend", filtered[0].Name)
+
+	require.NoError(t, runList(nil, nil))
+}
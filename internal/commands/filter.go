@@ -1,6 +1,10 @@
 package commands
 
-import "github.com/simonspoon/clipm/internal/models"
+import (
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+)
 
 // filterCompletedTasks removes done tasks that are "fully resolved" from the display.
 // A done task is hidden if:
@@ -17,15 +21,15 @@ func filterCompletedTasks(tasks []models.Task) []models.Task {
 
 	var result []models.Task
 	for i := range tasks {
-		if tasks[i].Status != models.StatusDone {
-			// Always keep non-done tasks
+		if !models.IsClosed(tasks[i].Status) {
+			// Always keep open tasks
 			result = append(result, tasks[i])
 			continue
 		}
 
-		// Done task: keep only if it has a parent that is not done
+		// Closed task: keep only if it has a parent that is still open
 		if tasks[i].Parent != nil {
-			if parent, ok := byID[*tasks[i].Parent]; ok && parent.Status != models.StatusDone {
+			if parent, ok := byID[*tasks[i].Parent]; ok && !models.IsClosed(parent.Status) {
 				result = append(result, tasks[i])
 			}
 		}
@@ -33,3 +37,44 @@ func filterCompletedTasks(tasks []models.Task) []models.Task {
 
 	return result
 }
+
+// filterWhere returns the tasks matching a --where query. all is every task in
+// the store, so parents, ancestors and blockers resolve even when tasks is a
+// filtered subset.
+func filterWhere(tasks, all []models.Task, query *models.Query) []models.Task {
+	match := query.Matcher(all, time.Now())
+	var filtered []models.Task
+	for i := range tasks {
+		if match(&tasks[i]) {
+			filtered = append(filtered, tasks[i])
+		}
+	}
+	return filtered
+}
+
+// withAncestors adds the ancestors of each task, taken from all, so filtered
+// tasks can still be shown in their place in a tree
+func withAncestors(tasks, all []models.Task) []models.Task {
+	byID := make(map[string]models.Task, len(all))
+	for i := range all {
+		byID[all[i].ID] = all[i]
+	}
+	seen := make(map[string]bool, len(tasks))
+	for i := range tasks {
+		seen[tasks[i].ID] = true
+	}
+
+	result := append([]models.Task(nil), tasks...)
+	for i := range tasks {
+		for parent := tasks[i].Parent; parent != nil && !seen[*parent]; {
+			p, ok := byID[*parent]
+			if !ok {
+				break
+			}
+			seen[p.ID] = true
+			result = append(result, p)
+			parent = p.Parent
+		}
+	}
+	return result
+}
@@ -173,3 +173,29 @@ func TestTreeShowAll(t *testing.T) {
 	err = runTree(nil, []string{})
 	assert.NoError(t, err)
 }
+
+func TestFilterWhere_WithAncestors(t *testing.T) {
+	now := time.Now()
+	root, child := "aaaa", "aaab"
+	all := []models.Task{
+		{ID: "aaaa", Name: "Root", Status: models.StatusDone, Created: now, Updated: now},
+		{ID: "aaab", Name: "Child", Status: models.StatusTodo, Parent: &root, Created: now, Updated: now},
+		{ID: "aaac", Name: "Grandchild", Status: models.StatusTodo, Parent: &child, Fields: map[string]any{"tags": "backend"}, Created: now, Updated: now},
+		{ID: "aaad", Name: "Other", Status: models.StatusTodo, Created: now, Updated: now},
+	}
+
+	query, err := models.ParseQuery("tag:backend")
+	require.NoError(t, err)
+
+	// The done root is hidden, but is still resolved as an ancestor
+	visible := filterCompletedTasks(all)
+	matched := filterWhere(visible, all, query)
+	require.Len(t, matched, 1)
+	assert.Equal(t, "aaac", matched[0].ID)
+
+	var ids []string
+	for _, task := range withAncestors(matched, all) {
+		ids = append(ids, task.ID)
+	}
+	assert.Equal(t, []string{"aaac", "aaab", "aaaa"}, ids)
+}
@@ -0,0 +1,278 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphFormat      string
+	graphSubtree     string
+	graphIncludeDone bool
+)
+
+// Graph formats
+const (
+	graphDOT     = "dot"
+	graphMermaid = "mermaid"
+	graphJSON    = "json"
+)
+
+// Graph edge types
+const (
+	edgeParent = "parent"
+	edgeBlocks = "blocks"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Render task hierarchy and dependencies as DOT or Mermaid",
+	Long: `Print every task as a node coloured by status, with an edge from each parent
+to its children and from each blocker to the tasks it blocks. Tasks that block
+others are drawn with a heavier border, so bottlenecks stand out.
+
+Formats: dot (Graphviz), mermaid, json. --output prints the JSON graph in
+another encoding, such as yaml. Done tasks are left out unless --include-done
+is set.`,
+	Args: cobra.NoArgs,
+	RunE: runGraph,
+}
+
+func init() {
+	graphCmd.Flags().StringVar(&graphFormat, "format", graphDOT, "Output format: dot, mermaid, json")
+	graphCmd.Flags().StringVar(&graphSubtree, "subtree", "", "Only graph this task and its descendants")
+	graphCmd.Flags().BoolVar(&graphIncludeDone, "include-done", false, "Include done and cancelled tasks")
+}
+
+type graphNode struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Status string  `json:"status"`
+	Owner  *string `json:"owner,omitempty"`
+	// Blocks counts the tasks in the graph that this task blocks directly
+	Blocks int `json:"blocks"`
+}
+
+type graphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+}
+
+type taskGraph struct {
+	Nodes []graphNode `json:"nodes"`
+	Edges []graphEdge `json:"edges"`
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	switch graphFormat {
+	case graphDOT, graphMermaid, graphJSON:
+	default:
+		return models.Errorf(models.CodeInvalidArgument, "invalid format %q. Must be: dot, mermaid, json", graphFormat)
+	}
+	format := graphFormat
+	if outputFlag != "" {
+		if cmd != nil && cmd.Flags().Changed("format") && graphFormat != graphJSON {
+			return models.Errorf(models.CodeInvalidArgument, "--output only applies to --format json")
+		}
+		format = graphJSON
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	tasks, err := store.LoadAll()
+	if err != nil {
+		return err
+	}
+
+	if graphSubtree != "" {
+		id := models.NormalizeTaskID(graphSubtree)
+		if !models.IsValidTaskID(id) {
+			return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", graphSubtree).With("id", graphSubtree)
+		}
+		if findTask(tasks, id) == nil {
+			return models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+		}
+		tasks = subtreeTasks(tasks, id)
+	}
+
+	if !graphIncludeDone {
+		var open []models.Task
+		for i := range tasks {
+			if !models.IsClosed(tasks[i].Status) {
+				open = append(open, tasks[i])
+			}
+		}
+		tasks = open
+	}
+
+	graph := buildGraph(tasks)
+	switch format {
+	case graphDOT:
+		writeDOT(os.Stdout, graph)
+	case graphMermaid:
+		writeMermaid(os.Stdout, graph)
+	default:
+		return render(os.Stdout, outputMode(false), graph, nil)
+	}
+	return nil
+}
+
+// subtreeTasks returns the task with the given ID and all of its descendants
+func subtreeTasks(tasks []models.Task, rootID string) []models.Task {
+	keep := map[string]bool{rootID: true}
+	for changed := true; changed; {
+		changed = false
+		for i := range tasks {
+			if !keep[tasks[i].ID] && tasks[i].Parent != nil && keep[*tasks[i].Parent] {
+				keep[tasks[i].ID] = true
+				changed = true
+			}
+		}
+	}
+
+	var subtree []models.Task
+	for i := range tasks {
+		if keep[tasks[i].ID] {
+			subtree = append(subtree, tasks[i])
+		}
+	}
+	return subtree
+}
+
+// buildGraph returns the nodes and edges among tasks in a stable order.
+// Edges to tasks outside the list are dropped.
+func buildGraph(tasks []models.Task) *taskGraph {
+	sorted := append([]models.Task(nil), tasks...)
+	models.SortByRank(sorted)
+
+	included := make(map[string]bool, len(sorted))
+	for i := range sorted {
+		included[sorted[i].ID] = true
+	}
+
+	graph := &taskGraph{Nodes: []graphNode{}, Edges: []graphEdge{}}
+	blocks := make(map[string]int)
+	for i := range sorted {
+		task := &sorted[i]
+		if task.Parent != nil && included[*task.Parent] {
+			graph.Edges = append(graph.Edges, graphEdge{From: *task.Parent, To: task.ID, Type: edgeParent})
+		}
+		for _, blocker := range task.BlockedBy {
+			if included[blocker] {
+				graph.Edges = append(graph.Edges, graphEdge{From: blocker, To: task.ID, Type: edgeBlocks})
+				blocks[blocker]++
+			}
+		}
+	}
+	for i := range sorted {
+		graph.Nodes = append(graph.Nodes, graphNode{
+			ID:     sorted[i].ID,
+			Name:   sorted[i].Name,
+			Status: sorted[i].Status,
+			Owner:  sorted[i].Owner,
+			Blocks: blocks[sorted[i].ID],
+		})
+	}
+
+	sort.SliceStable(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.Type != b.Type {
+			return a.Type > b.Type // parent edges first
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return graph
+}
+
+// graphColors are the fill colours for each status
+var graphColors = map[string]string{
+	models.StatusTodo:       "#cfe2ff",
+	models.StatusInProgress: "#fff3cd",
+	models.StatusDone:       "#d1e7dd",
+	models.StatusCancelled:  "#e2e3e5",
+}
+
+func writeDOT(w io.Writer, graph *taskGraph) {
+	fmt.Fprintln(w, "digraph clipm {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, `  node [shape=box, style="rounded,filled", fontname="Helvetica"];`)
+	for _, n := range graph.Nodes {
+		label := n.ID + `\n` + dotEscape(n.Name)
+		fmt.Fprintf(w, `  "%s" [label="%s", fillcolor="%s"`, n.ID, label, graphColors[n.Status])
+		if n.Blocks > 0 {
+			fmt.Fprintf(w, ", penwidth=%d", n.Blocks+1)
+		}
+		fmt.Fprintln(w, "];")
+	}
+	for _, e := range graph.Edges {
+		if e.Type == edgeParent {
+			fmt.Fprintf(w, "  \"%s\" -> \"%s\" [style=dashed, color=gray, arrowhead=none];\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(w, "  \"%s\" -> \"%s\" [color=red, label=\"blocks\"];\n", e.From, e.To)
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func dotEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func writeMermaid(w io.Writer, graph *taskGraph) {
+	fmt.Fprintln(w, "graph LR")
+	for _, n := range graph.Nodes {
+		fmt.Fprintf(w, "  %s[\"%s: %s\"]\n", n.ID, n.ID, mermaidEscape(n.Name))
+	}
+	for _, e := range graph.Edges {
+		if e.Type == edgeParent {
+			fmt.Fprintf(w, "  %s -.- %s\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(w, "  %s -->|blocks| %s\n", e.From, e.To)
+		}
+	}
+
+	for _, status := range []string{models.StatusTodo, models.StatusInProgress, models.StatusDone, models.StatusCancelled} {
+		var ids []string
+		for _, n := range graph.Nodes {
+			if n.Status == status {
+				ids = append(ids, n.ID)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		class := strings.ReplaceAll(status, "-", "")
+		fmt.Fprintf(w, "  classDef %s fill:%s\n", class, graphColors[status])
+		fmt.Fprintf(w, "  class %s %s\n", strings.Join(ids, ","), class)
+	}
+	var bottlenecks []string
+	for _, n := range graph.Nodes {
+		if n.Blocks > 0 {
+			bottlenecks = append(bottlenecks, n.ID)
+		}
+	}
+	if len(bottlenecks) > 0 {
+		fmt.Fprintln(w, "  classDef bottleneck stroke-width:3px")
+		fmt.Fprintf(w, "  class %s bottleneck\n", strings.Join(bottlenecks, ","))
+	}
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), `"`, "#quot;")
+}
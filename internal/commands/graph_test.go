@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetGraphFlags() {
+	graphFormat = graphDOT
+	graphSubtree = ""
+	graphIncludeDone = false
+}
+
+func graphFixture() []models.Task {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	root := "aaaa"
+	return []models.Task{
+		{ID: "aaaa", Name: `Auth "v2"`, Status: models.StatusInProgress, Created: now},
+		{ID: "bbbb", Name: "Login", Parent: &root, Status: models.StatusTodo, Created: now.Add(time.Second)},
+		{ID: "cccc", Name: "Refresh", Parent: &root, Status: models.StatusTodo, BlockedBy: []string{"bbbb"}, Created: now.Add(2 * time.Second)},
+		{ID: "dddd", Name: "Docs", Status: models.StatusTodo, BlockedBy: []string{"bbbb", "zzzz"}, Created: now.Add(3 * time.Second)},
+	}
+}
+
+func TestBuildGraph(t *testing.T) {
+	graph := buildGraph(graphFixture())
+	require.Len(t, graph.Nodes, 4)
+	assert.Equal(t, "aaaa", graph.Nodes[0].ID)
+	assert.Equal(t, 2, graph.Nodes[1].Blocks)
+	// The edge to the missing task zzzz is dropped
+	assert.Equal(t, []graphEdge{
+		{From: "aaaa", To: "bbbb", Type: edgeParent},
+		{From: "aaaa", To: "cccc", Type: edgeParent},
+		{From: "bbbb", To: "cccc", Type: edgeBlocks},
+		{From: "bbbb", To: "dddd", Type: edgeBlocks},
+	}, graph.Edges)
+
+	sub := subtreeTasks(graphFixture(), "aaaa")
+	assert.Len(t, sub, 3)
+}
+
+func TestWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	writeDOT(&buf, buildGraph(graphFixture()))
+	out := buf.String()
+	assert.Contains(t, out, "digraph clipm {\n")
+	assert.Contains(t, out, `"aaaa" [label="aaaa\nAuth \"v2\"", fillcolor="#fff3cd"];`)
+	assert.Contains(t, out, `"bbbb" [label="bbbb\nLogin", fillcolor="#cfe2ff", penwidth=3];`)
+	assert.Contains(t, out, `"aaaa" -> "bbbb" [style=dashed, color=gray, arrowhead=none];`)
+	assert.Contains(t, out, `"bbbb" -> "cccc" [color=red, label="blocks"];`)
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	writeMermaid(&buf, buildGraph(graphFixture()))
+	out := buf.String()
+	assert.Contains(t, out, "graph LR\n")
+	assert.Contains(t, out, `  aaaa["aaaa: Auth #quot;v2#quot;"]`)
+	assert.Contains(t, out, "  aaaa -.- bbbb\n")
+	assert.Contains(t, out, "  bbbb -->|blocks| dddd\n")
+	assert.Contains(t, out, "  class bbbb,cccc,dddd todo\n")
+	assert.Contains(t, out, "  class aaaa inprogress\n")
+	assert.Contains(t, out, "  class bbbb bottleneck\n")
+}
+
+func TestGraphCommand(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetGraphFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createStructuredTask(t, store)
+
+	resetGraphFlags()
+	require.NoError(t, runGraph(nil, nil))
+	graphFormat = graphMermaid
+	require.NoError(t, runGraph(nil, nil))
+	graphFormat = graphJSON
+	graphSubtree = task.ID
+	graphIncludeDone = true
+	require.NoError(t, runGraph(nil, nil))
+
+	graphSubtree = "zzzz"
+	assert.ErrorContains(t, runGraph(nil, nil), "not found")
+
+	resetGraphFlags()
+	graphFormat = "png"
+	assert.ErrorContains(t, runGraph(nil, nil), "invalid format")
+}
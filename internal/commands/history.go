@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var historyPretty bool
+
+var historyCmd = &cobra.Command{
+	Use:   "history <id>",
+	Short: "Show a task's status, owner and parent history",
+	Long: `Show every status, owner and parent change recorded on a task, with the actor
+(from CLIPM_AGENT) and time of each change, and how long the task spent in each status.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHistory,
+}
+
+func init() {
+	historyCmd.Flags().BoolVar(&historyPretty, "pretty", false, "Pretty print output")
+}
+
+type historyResult struct {
+	ID      string                `json:"id"`
+	Name    string                `json:"name"`
+	Status  string                `json:"status"`
+	Created time.Time             `json:"created"`
+	History []models.HistoryEntry `json:"history"`
+	// TimeInStatus is the number of seconds spent in each status
+	TimeInStatus map[string]int64 `json:"timeInStatus"`
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	id := models.NormalizeTaskID(args[0])
+	if !models.IsValidTaskID(id) {
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	task, err := store.LoadTask(id)
+	if err != nil {
+		if err == storage.ErrTaskNotFound {
+			return models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+		}
+		return err
+	}
+
+	durations := task.StatusDurations(time.Now())
+
+	if outputMode(historyPretty) == outputPretty {
+		bold := color.New(color.Bold)
+		bold.Printf("History of %s: %s\n", task.ID, task.Name)
+		printHistory(task)
+		fmt.Println()
+		for _, status := range []string{models.StatusTodo, models.StatusInProgress} {
+			if d, ok := durations[status]; ok {
+				fmt.Printf("Time in %s: %s\n", status, formatDuration(d))
+			}
+		}
+	} else {
+		result := historyResult{
+			ID:           task.ID,
+			Name:         task.Name,
+			Status:       task.Status,
+			Created:      task.Created,
+			History:      task.History,
+			TimeInStatus: make(map[string]int64, len(durations)),
+		}
+		if result.History == nil {
+			result.History = []models.HistoryEntry{}
+		}
+		for status, d := range durations {
+			result.TimeInStatus[status] = int64(d.Seconds())
+		}
+		return render(os.Stdout, outputMode(historyPretty), result, nil)
+	}
+
+	return nil
+}
+
+// printHistory prints the task's lifecycle as a timeline, starting at creation
+func printHistory(task *models.Task) {
+	gray := color.New(color.FgHiBlack)
+	white := color.New(color.FgWhite)
+
+	initial := task.Status
+	for _, h := range task.History {
+		if h.Field == models.HistoryStatus {
+			initial = h.From
+			break
+		}
+	}
+
+	gray.Printf("  [%s] ", task.Created.Format("2006-01-02 15:04"))
+	white.Printf("created as %s\n", initial)
+	for _, h := range task.History {
+		gray.Printf("  [%s] ", h.Timestamp.Format("2006-01-02 15:04"))
+		white.Printf("%s: %s -> %s", h.Field, historyValue(h.From), historyValue(h.To))
+		if h.Actor != "" {
+			gray.Printf(" (by %s)", h.Actor)
+		}
+		fmt.Println()
+	}
+}
+
+func historyValue(v string) string {
+	if v == "" {
+		return "none"
+	}
+	return v
+}
+
+// formatDuration renders a duration rounded to minutes, with days for long spans
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "<1m"
+	}
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	minutes := (d - hours*time.Hour) / time.Minute
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh%dm", days, hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryRecording(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() {
+		statusPretty, statusOutcome = false, ""
+		claimPretty, claimForce, unclaimPretty = false, false, false
+		parentPretty, unparentPretty, deletePretty = false, false, false
+		historyPretty, showPretty = false, false
+	}()
+	t.Setenv(storage.AgentEnv, "agent-1")
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	parent := createTestTask(t, store, "Parent", models.StatusTodo, nil)
+	id := createTestTask(t, store, "Child", models.StatusTodo, nil)
+
+	statusPretty, statusOutcome = false, ""
+	claimPretty, claimForce, unclaimPretty = false, false, false
+	parentPretty, unparentPretty, deletePretty = false, false, false
+
+	require.NoError(t, runClaim(nil, []string{id, "agent-1"}))
+	require.NoError(t, runStatus(nil, []string{id, "in-progress"}))
+	require.NoError(t, runStatus(nil, []string{id, "todo"}))
+	require.NoError(t, runParent(nil, []string{id, parent}))
+	require.NoError(t, runUnclaim(nil, []string{id}))
+	require.NoError(t, runUnparent(nil, []string{id}))
+
+	task, err := store.LoadTask(id)
+	require.NoError(t, err)
+	require.Len(t, task.History, 6)
+	assert.Equal(t, models.HistoryEntry{Field: "owner", From: "", To: "agent-1", Actor: "agent-1", Timestamp: task.History[0].Timestamp}, task.History[0])
+	assert.Equal(t, "status", task.History[1].Field)
+	assert.Equal(t, "in-progress", task.History[1].To)
+	assert.Equal(t, "todo", task.History[2].To)
+	assert.Equal(t, "parent", task.History[3].Field)
+	assert.Equal(t, parent, task.History[3].To)
+	assert.Equal(t, "", task.History[4].To)
+	assert.Equal(t, parent, task.History[5].From)
+
+	// Deleting a parent records the orphaned child's parent change
+	require.NoError(t, runParent(nil, []string{id, parent}))
+	require.NoError(t, runStatus(nil, []string{id, "done"}))
+	t.Setenv(storage.AgentEnv, "")
+	require.NoError(t, runDelete(nil, []string{parent}))
+	task, err = store.LoadTask(id)
+	require.NoError(t, err)
+	last := task.History[len(task.History)-1]
+	assert.Equal(t, "parent", last.Field)
+	assert.Equal(t, parent, last.From)
+	assert.Empty(t, last.Actor)
+
+	historyPretty = false
+	require.NoError(t, runHistory(nil, []string{id}))
+	historyPretty = true
+	require.NoError(t, runHistory(nil, []string{id}))
+	showPretty = true
+	require.NoError(t, runShow(nil, []string{id}))
+
+	assert.ErrorContains(t, runHistory(nil, []string{"zzzz"}), "not found")
+	assert.ErrorContains(t, runHistory(nil, []string{"bad!"}), "invalid task ID")
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "<1m", formatDuration(10*time.Second))
+	assert.Equal(t, "5m", formatDuration(5*time.Minute))
+	assert.Equal(t, "2h3m", formatDuration(2*time.Hour+3*time.Minute))
+	assert.Equal(t, "1d2h0m", formatDuration(26*time.Hour))
+}
@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importParent string
+	importDryRun bool
+	importPretty bool
+)
+
+// importInput is where import reads the plan from when the file is "-" (replaced in tests)
+var importInput io.Reader = os.Stdin
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Create a nested plan of tasks from YAML or JSON",
+	Long: `Create every task in a plan document in one transaction. Use - to read stdin.
+
+The document is a list of tasks, or a mapping with a "tasks" list. Each task
+takes the same keys as a template task (name, description, action, verify,
+result, verifyCmd, fields, checklist, children) plus owner and notes.
+blockedBy entries name another task's key or an existing task ID.
+
+With --dry-run nothing is saved and the tree that would be created is printed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importParent, "parent", "", "Create the plan's top-level tasks under this task")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Validate the plan and print the resulting tree without saving")
+	importCmd.Flags().BoolVar(&importPretty, "pretty", false, "Pretty print output")
+}
+
+type importResult struct {
+	*storage.PlanResult
+	DryRun bool `json:"dryRun,omitempty"`
+	// Tree is the plan as it would be created, for --dry-run
+	Tree []treeNode `json:"tree,omitempty"`
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	var data []byte
+	var err error
+	if args[0] == "-" {
+		data, err = io.ReadAll(importInput)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read plan: %w", err)
+	}
+
+	nodes, err := storage.ParsePlan(data)
+	if err != nil {
+		return err
+	}
+
+	var parent *string
+	if importParent != "" {
+		parentID := models.NormalizeTaskID(importParent)
+		if !models.IsValidTaskID(parentID) {
+			return models.Errorf(models.CodeInvalidID, "invalid parent task ID: %s", importParent).With("id", importParent)
+		}
+		parent = &parentID
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	result := &importResult{DryRun: importDryRun}
+	var tasks []models.Task
+	if importDryRun {
+		err = store.DryRun(func(tx *storage.Storage) error {
+			var err error
+			if result.PlanResult, err = tx.CreatePlan(nodes, parent); err != nil {
+				return err
+			}
+			tasks, err = tx.LoadAll()
+			return err
+		})
+	} else {
+		result.PlanResult, err = store.CreatePlan(nodes, parent)
+	}
+	if err != nil {
+		return err
+	}
+
+	if importDryRun {
+		roots, taskMap := planTree(result.PlanResult, tasks)
+		progress := models.ComputeProgress(tasks)
+		if outputMode(importPretty) == outputPretty {
+			fmt.Printf("Dry run: would create %d task(s)\n", len(result.Created))
+			for i := range roots {
+				printTaskTree(os.Stdout, &roots[i], taskMap, progress, "", i == len(roots)-1)
+			}
+			return nil
+		}
+		for i := range roots {
+			result.Tree = append(result.Tree, buildTreeNode(roots[i], taskMap, progress))
+		}
+	}
+
+	return render(os.Stdout, outputMode(importPretty), result, func() {
+		green := color.New(color.FgGreen)
+		green.Printf("Imported %d task(s)\n", len(result.Created))
+		keys := make([]string, 0, len(result.IDs))
+		for key := range result.IDs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("  %s: %s\n", key, result.IDs[key])
+		}
+	})
+}
+
+// planTree returns the top-level tasks a plan created and a map of every created task
+func planTree(plan *storage.PlanResult, tasks []models.Task) ([]models.Task, map[string]models.Task) {
+	created := make(map[string]bool, len(plan.Created))
+	for _, id := range plan.Created {
+		created[id] = true
+	}
+
+	taskMap := make(map[string]models.Task)
+	for i := range tasks {
+		if created[tasks[i].ID] {
+			taskMap[tasks[i].ID] = tasks[i]
+		}
+	}
+
+	var roots []models.Task
+	for _, id := range plan.Created {
+		task := taskMap[id]
+		if task.Parent == nil || !created[*task.Parent] {
+			roots = append(roots, task)
+		}
+	}
+	return roots, taskMap
+}
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetImportFlags() {
+	importParent = ""
+	importDryRun = false
+	importPretty = false
+}
+
+const importPlan = `
+tasks:
+  - key: feature
+    name: Feature
+    action: Ship the feature
+    verify: Demo works
+    result: Release notes
+    owner: agent-1
+    children:
+      - key: design
+        name: Design
+        action: Write the design
+        verify: Design reviewed
+        result: Link to the design
+        notes: [Sketch first]
+      - key: build
+        name: Build
+        action: Build it
+        verify: Tests pass
+        result: Merged PR
+        blockedBy: [design]
+`
+
+func TestImportCommand(t *testing.T) {
+	tmpDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetImportFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	parent := createStructuredTask(t, store)
+
+	path := filepath.Join(tmpDir, "plan.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(importPlan), 0644))
+
+	resetImportFlags()
+	importParent = strings.ToUpper(parent.ID)
+	require.NoError(t, runImport(nil, []string{path}))
+
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	require.Len(t, tasks, 4)
+
+	byName := make(map[string]models.Task)
+	for _, task := range tasks {
+		byName[task.Name] = task
+	}
+	feature := byName["Feature"]
+	require.NotNil(t, feature.Parent)
+	assert.Equal(t, parent.ID, *feature.Parent)
+	require.NotNil(t, feature.Owner)
+	assert.Equal(t, "agent-1", *feature.Owner)
+	require.Len(t, byName["Design"].Notes, 1)
+	assert.Equal(t, []string{byName["Design"].ID}, byName["Build"].BlockedBy)
+}
+
+func TestImportCommand_DryRun(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetImportFlags()
+	orig := importInput
+	defer func() { importInput = orig }()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	resetImportFlags()
+	importDryRun = true
+	importInput = strings.NewReader(importPlan)
+	require.NoError(t, runImport(nil, []string{"-"}))
+
+	importPretty = true
+	importInput = strings.NewReader(importPlan)
+	require.NoError(t, runImport(nil, []string{"-"}))
+
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
+func TestImportCommand_Errors(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetImportFlags()
+	orig := importInput
+	defer func() { importInput = orig }()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	resetImportFlags()
+	assert.ErrorContains(t, runImport(nil, []string{"missing.yaml"}), "failed to read plan")
+
+	importInput = strings.NewReader(`[{name: A, key: a, action: x, verify: y, result: z, blockedBy: [b]}, {name: B, key: b, action: x, verify: y, result: z, blockedBy: [a]}]`)
+	assert.ErrorContains(t, runImport(nil, []string{"-"}), "would create a cycle")
+
+	importInput = strings.NewReader(`[{name: A, key: a, action: x, verify: y, result: z, children: [{name: B, action: x, result: z}]}]`)
+	err = runImport(nil, []string{"-"})
+	assert.ErrorContains(t, err, `plan task "a" / "B": action, verify and result are required`)
+	assert.Equal(t, models.CodeInvalidArgument, models.ErrorCodeOf(err))
+
+	importInput = strings.NewReader(`[{name: A, action: x, verify: y, result: z}]`)
+	importParent = "zzzz"
+	assert.ErrorContains(t, runImport(nil, []string{"-"}), "parent task zzzz not found")
+
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 
@@ -48,13 +47,8 @@ func runInit(cmd *cobra.Command, args []string) error {
 		Path:    cwd,
 	}
 
-	if initPretty {
+	return render(os.Stdout, outputMode(initPretty), result, func() {
 		green := color.New(color.FgGreen)
 		green.Printf("Initialized clipm in %s\n", cwd)
-	} else {
-		out, _ := json.Marshal(result)
-		fmt.Println(string(out))
-	}
-
-	return nil
+	})
 }
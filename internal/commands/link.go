@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	linkPretty   bool
+	unlinkPretty bool
+	unlinkType   string
+)
+
+var linkCmd = &cobra.Command{
+	Use:   "link <id> <type> <target-id>",
+	Short: "Link a task to another task",
+	Long: `Record a typed relation from one task to another. Types: ` + strings.Join(models.LinkTypes, ", ") + `.
+
+  clipm link abcd duplicates efgh   abcd is a duplicate of efgh: abcd is cancelled
+                                    and its notes move to efgh
+  clipm link abcd supersedes efgh   abcd replaces efgh: efgh is cancelled
+
+Tasks blocked by a task closed this way are re-pointed to the task that replaces it.
+Links do not affect scheduling; use 'block' for dependencies.`,
+	Args: cobra.ExactArgs(3),
+	RunE: runLink,
+}
+
+var unlinkCmd = &cobra.Command{
+	Use:   "unlink <id> <target-id>",
+	Short: "Remove links between two tasks",
+	Long:  `Remove the links between two tasks, in either direction. Tasks closed by a link are not reopened.`,
+	Args:  cobra.ExactArgs(2),
+	RunE:  runUnlink,
+}
+
+func init() {
+	linkCmd.Flags().BoolVar(&linkPretty, "pretty", false, "Pretty print output")
+	unlinkCmd.Flags().BoolVar(&unlinkPretty, "pretty", false, "Pretty print output")
+	unlinkCmd.Flags().StringVar(&unlinkType, "type", "", "Only remove links of this type")
+}
+
+func runLink(cmd *cobra.Command, args []string) error {
+	sourceID, targetID, err := parseLinkArgs(args[0], args[2])
+	if err != nil {
+		return err
+	}
+	linkType := args[1]
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	result, err := store.AddLink(sourceID, linkType, targetID)
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, outputMode(linkPretty), result, func() {
+		green := color.New(color.FgGreen)
+		green.Printf("Task %s %s %s\n", sourceID, linkType, targetID)
+		if result.Closed != "" {
+			fmt.Printf("Cancelled task %s\n", result.Closed)
+		}
+		if result.MovedNotes > 0 {
+			fmt.Printf("Moved %d note(s) to %s\n", result.MovedNotes, targetID)
+		}
+		if len(result.Repointed) > 0 {
+			fmt.Printf("Re-pointed dependencies of %s\n", strings.Join(result.Repointed, ", "))
+		}
+	})
+}
+
+type unlinkResult struct {
+	Removed int `json:"removed"`
+}
+
+func runUnlink(cmd *cobra.Command, args []string) error {
+	aID, bID, err := parseLinkArgs(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	if unlinkType != "" && !models.IsValidLinkType(unlinkType) {
+		return models.Errorf(models.CodeInvalidArgument, "invalid link type %q. Must be one of: %s", unlinkType, strings.Join(models.LinkTypes, ", "))
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	removed, err := store.RemoveLink(aID, bID, unlinkType)
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, outputMode(unlinkPretty), unlinkResult{Removed: removed}, func() {
+		green := color.New(color.FgGreen)
+		green.Printf("Removed %d link(s) between %s and %s\n", removed, aID, bID)
+	})
+}
+
+func parseLinkArgs(rawA, rawB string) (string, string, error) {
+	a := models.NormalizeTaskID(rawA)
+	if !models.IsValidTaskID(a) {
+		return "", "", models.Errorf(models.CodeInvalidID, "invalid task ID: %s", rawA).With("id", rawA)
+	}
+	b := models.NormalizeTaskID(rawB)
+	if !models.IsValidTaskID(b) {
+		return "", "", models.Errorf(models.CodeInvalidID, "invalid task ID: %s", rawB).With("id", rawB)
+	}
+	if a == b {
+		return "", "", models.Errorf(models.CodeInvalidArgument, "a task cannot link to itself")
+	}
+	return a, b, nil
+}
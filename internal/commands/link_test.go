@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkCommands(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() {
+		linkPretty = false
+		unlinkPretty = false
+		unlinkType = ""
+		showPretty = false
+		deletePretty = false
+	}()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	a := createTestTask(t, store, "Login broken", models.StatusTodo, nil)
+	b := createTestTask(t, store, "Auth outage", models.StatusInProgress, nil)
+	c := createTestTask(t, store, "Postmortem", models.StatusTodo, nil)
+
+	linkPretty = false
+	require.NoError(t, runLink(nil, []string{a, "duplicates", b}))
+	linkPretty = true
+	require.NoError(t, runLink(nil, []string{c, "follows-up", b}))
+
+	task, err := store.LoadTask(a)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, task.Status)
+
+	// show lists both directions
+	showPretty = false
+	require.NoError(t, runShow(nil, []string{b}))
+	showPretty = true
+	require.NoError(t, runShow(nil, []string{b}))
+	require.NoError(t, runShow(nil, []string{a}))
+
+	unlinkType = "relates-to"
+	assert.ErrorContains(t, runUnlink(nil, []string{c, b}), "no link")
+	unlinkType = "bogus"
+	assert.ErrorContains(t, runUnlink(nil, []string{c, b}), "invalid link type")
+	unlinkType = ""
+	require.NoError(t, runUnlink(nil, []string{b, c}))
+	task, err = store.LoadTask(c)
+	require.NoError(t, err)
+	assert.Empty(t, task.Links)
+
+	// Deleting a task removes links pointing at it
+	deletePretty = false
+	require.NoError(t, runDelete(nil, []string{b}))
+	task, err = store.LoadTask(a)
+	require.NoError(t, err)
+	assert.Empty(t, task.Links)
+
+	assert.ErrorContains(t, runLink(nil, []string{"bad!", "relates-to", c}), "invalid task ID")
+	assert.ErrorContains(t, runLink(nil, []string{c, "relates-to", c}), "itself")
+	assert.ErrorContains(t, runLink(nil, []string{c, "blocks", a}), "invalid link type")
+}
@@ -1,9 +1,8 @@
 package commands
 
 import (
-	"encoding/json"
 	"fmt"
-	"sort"
+	"os"
 
 	"github.com/fatih/color"
 	"github.com/simonspoon/clipm/internal/models"
@@ -19,29 +18,42 @@ var (
 	listBlocked   bool
 	listUnblocked bool
 	listShowAll   bool
+	listFields    []string
+	listWhere     string
+	listOutput    taskOutput
 )
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List tasks",
-	Long:  `List tasks with optional filtering by status, owner, or blocked state.`,
-	RunE:  runList,
+	Long: `List tasks with optional filtering by status, owner, blocked state, or custom fields.
+
+--where takes a query such as 'status:todo owner:none tag:backend updated:>24h
+NOT ancestor:abcd'. Terms are key:value pairs combined with AND (implicit),
+OR, NOT and parentheses.`,
+	RunE: runList,
 }
 
 func init() {
-	listCmd.Flags().StringVarP(&listStatus, "status", "s", "", "Filter by status (todo|in-progress|done)")
+	listCmd.Flags().StringVarP(&listStatus, "status", "s", "", "Filter by status (todo|in-progress|done|cancelled)")
 	listCmd.Flags().BoolVar(&listPretty, "pretty", false, "Pretty print output")
 	listCmd.Flags().StringVar(&listOwner, "owner", "", "Filter to tasks owned by this agent")
 	listCmd.Flags().BoolVar(&listUnclaimed, "unclaimed", false, "Filter to tasks with no owner")
 	listCmd.Flags().BoolVar(&listBlocked, "blocked", false, "Show only blocked tasks")
 	listCmd.Flags().BoolVar(&listUnblocked, "unblocked", false, "Show only unblocked tasks")
 	listCmd.Flags().BoolVar(&listShowAll, "show-all", false, "Show all tasks including completed")
+	listCmd.Flags().StringArrayVar(&listFields, "field", nil, "Filter by custom field as key=value (repeatable)")
+	addTaskOutputFlags(listCmd, &listOutput)
+	listCmd.Flags().StringVar(&listWhere, "where", "", "Only include tasks matching a query, e.g. \"status:todo owner:none\"")
 }
 
 func runList(cmd *cobra.Command, args []string) error {
 	if err := validateListFlags(); err != nil {
 		return err
 	}
+	if err := listOutput.prepare(outputMode(listPretty)); err != nil {
+		return err
+	}
 
 	store, err := storage.NewStorage()
 	if err != nil {
@@ -58,34 +70,30 @@ func runList(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	sort.Slice(tasks, func(i, j int) bool {
-		return tasks[i].Created.Before(tasks[j].Created)
-	})
+	models.SortByRank(tasks)
 
-	if listPretty {
+	if listOutput.mode == outputPretty {
 		printTasksPretty(tasks)
-	} else {
-		out, _ := json.Marshal(tasks)
-		fmt.Println(string(out))
+		return nil
 	}
-
-	return nil
+	return listOutput.writeTasks(os.Stdout, tasks)
 }
 
 func validateListFlags() error {
 	if listOwner != "" && listUnclaimed {
-		return fmt.Errorf("--owner and --unclaimed are mutually exclusive")
+		return models.Errorf(models.CodeInvalidArgument, "--owner and --unclaimed are mutually exclusive")
 	}
 	if listBlocked && listUnblocked {
-		return fmt.Errorf("--blocked and --unblocked are mutually exclusive")
+		return models.Errorf(models.CodeInvalidArgument, "--blocked and --unblocked are mutually exclusive")
 	}
 	if listStatus != "" && !models.IsValidStatus(listStatus) {
-		return fmt.Errorf("invalid status %q. Must be: todo, in-progress, done", listStatus)
+		return models.Errorf(models.CodeInvalidArgument, "invalid status %q. Must be: todo, in-progress, done, cancelled", listStatus)
 	}
 	return nil
 }
 
 func applyListFilters(tasks []models.Task, store *storage.Storage) ([]models.Task, error) {
+	all := tasks
 	if listStatus != "" {
 		tasks = filterTasksByStatus(tasks, listStatus)
 	}
@@ -109,6 +117,20 @@ func applyListFilters(tasks []models.Task, store *storage.Storage) ([]models.Tas
 			return nil, err
 		}
 	}
+	if len(listFields) > 0 {
+		var err error
+		tasks, err = filterByFields(tasks, listFields)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if listWhere != "" {
+		query, err := models.ParseQuery(listWhere)
+		if err != nil {
+			return nil, err
+		}
+		tasks = filterWhere(tasks, all, query)
+	}
 	if !listShowAll {
 		tasks = filterCompletedTasks(tasks)
 	}
@@ -176,6 +198,7 @@ func printTasksPretty(tasks []models.Task) {
 		models.StatusTodo,
 		models.StatusInProgress,
 		models.StatusDone,
+		models.StatusCancelled,
 	}
 
 	// Colors
@@ -183,6 +206,7 @@ func printTasksPretty(tasks []models.Task) {
 		models.StatusTodo:       color.New(color.FgWhite),
 		models.StatusInProgress: color.New(color.FgYellow),
 		models.StatusDone:       color.New(color.FgGreen),
+		models.StatusCancelled:  color.New(color.FgHiBlack),
 	}
 
 	for _, status := range statuses {
@@ -140,3 +140,34 @@ func TestListPrettyOutput(t *testing.T) {
 	err = runList(nil, []string{})
 	require.NoError(t, err)
 }
+
+func TestListWhere(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { listWhere = "" }()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	parent := createTestTask(t, store, "Parent", models.StatusInProgress, nil)
+	child := createTestTask(t, store, "Child", models.StatusTodo, &parent)
+	createTestTask(t, store, "Other", models.StatusTodo, nil)
+
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+
+	listWhere = "status:todo NOT ancestor:" + parent
+	filtered, err := applyListFilters(tasks, store)
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "Other", filtered[0].Name)
+
+	listWhere = "ancestor:" + parent + " OR id:" + parent
+	filtered, err = applyListFilters(tasks, store)
+	require.NoError(t, err)
+	require.Len(t, filtered, 2)
+	assert.ElementsMatch(t, []string{parent, child}, []string{filtered[0].ID, filtered[1].ID})
+
+	listWhere = "stauts:todo"
+	err = runList(nil, []string{})
+	assert.ErrorContains(t, err, `did you mean "status"`)
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var mergePretty bool
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge <src-id> <dst-id>",
+	Short: "Merge one task into another",
+	Long: `Fold a task into another that covers the same work. The source's children,
+notes and blockers move to the destination, tasks waiting on the source wait on
+the destination instead, and the source is cancelled with a duplicates link to
+the destination.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMerge,
+}
+
+func init() {
+	mergeCmd.Flags().BoolVar(&mergePretty, "pretty", false, "Pretty print output")
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	srcID := models.NormalizeTaskID(args[0])
+	if !models.IsValidTaskID(srcID) {
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
+	}
+	dstID := models.NormalizeTaskID(args[1])
+	if !models.IsValidTaskID(dstID) {
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[1]).With("id", args[1])
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	result, err := store.MergeTasks(srcID, dstID)
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, outputMode(mergePretty), result, func() {
+		green := color.New(color.FgGreen)
+		green.Printf("Merged task %s into %s\n", srcID, dstID)
+		if len(result.MovedChildren) > 0 {
+			fmt.Printf("Moved children %s to %s\n", strings.Join(result.MovedChildren, ", "), dstID)
+		}
+		if result.MovedNotes > 0 {
+			fmt.Printf("Moved %d note(s) to %s\n", result.MovedNotes, dstID)
+		}
+		if len(result.Blockers) > 0 {
+			fmt.Printf("%s now waits on %s\n", dstID, strings.Join(result.Blockers, ", "))
+		}
+		if len(result.Repointed) > 0 {
+			fmt.Printf("Re-pointed dependencies of %s\n", strings.Join(result.Repointed, ", "))
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeCommand(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { mergePretty = false }()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	now := time.Now()
+	for _, id := range []string{"aaaa", "bbbb"} {
+		require.NoError(t, store.SaveTask(&models.Task{ID: id, Name: id, Status: models.StatusTodo, Created: now, Updated: now}))
+	}
+
+	mergePretty = true
+	require.NoError(t, runMerge(nil, []string{"AAAA", "bbbb"}))
+	src, err := store.LoadTask("aaaa")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, src.Status)
+
+	assert.ErrorContains(t, runMerge(nil, []string{"aaaa", "bbbb"}), "cannot merge cancelled task")
+	assert.ErrorContains(t, runMerge(nil, []string{"bbbb", "bbbb"}), "into itself")
+	assert.ErrorContains(t, runMerge(nil, []string{"x", "bbbb"}), "invalid task ID")
+	assert.ErrorContains(t, runMerge(nil, []string{"bbbb", "x"}), "invalid task ID")
+}
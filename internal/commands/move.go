@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	movePretty bool
+	moveBefore string
+	moveAfter  string
+	moveTop    bool
+	moveBottom bool
+)
+
+var moveCmd = &cobra.Command{
+	Use:   "move <id>",
+	Short: "Reorder a task among its siblings",
+	Long: `Move a task within its parent: to the top or bottom, or just before or after
+a sibling. The order decides which task next suggests and how tree and list
+show siblings. Tasks that were never moved follow moved ones, oldest first.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMove,
+}
+
+func init() {
+	moveCmd.Flags().BoolVar(&movePretty, "pretty", false, "Pretty print output")
+	moveCmd.Flags().StringVar(&moveBefore, "before", "", "Place the task just before this sibling")
+	moveCmd.Flags().StringVar(&moveAfter, "after", "", "Place the task just after this sibling")
+	moveCmd.Flags().BoolVar(&moveTop, "top", false, "Place the task first among its siblings")
+	moveCmd.Flags().BoolVar(&moveBottom, "bottom", false, "Place the task last among its siblings")
+}
+
+func runMove(cmd *cobra.Command, args []string) error {
+	id := models.NormalizeTaskID(args[0])
+	if !models.IsValidTaskID(id) {
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
+	}
+
+	var position, anchor string
+	chosen := 0
+	if moveBefore != "" {
+		position, anchor = storage.MoveBefore, moveBefore
+		chosen++
+	}
+	if moveAfter != "" {
+		position, anchor = storage.MoveAfter, moveAfter
+		chosen++
+	}
+	if moveTop {
+		position = storage.MoveTop
+		chosen++
+	}
+	if moveBottom {
+		position = storage.MoveBottom
+		chosen++
+	}
+	if chosen != 1 {
+		return models.Errorf(models.CodeInvalidArgument, "specify exactly one of --before, --after, --top or --bottom")
+	}
+	if anchor != "" {
+		anchor = models.NormalizeTaskID(anchor)
+		if !models.IsValidTaskID(anchor) {
+			return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", anchor).With("id", anchor)
+		}
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	task, err := store.MoveTask(id, position, anchor)
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, outputMode(movePretty), task, func() {
+		green := color.New(color.FgGreen)
+		switch position {
+		case storage.MoveTop, storage.MoveBottom:
+			green.Printf("Moved task %s to the %s\n", id, position)
+		default:
+			green.Printf("Moved task %s %s %s\n", id, position, anchor)
+		}
+	})
+}
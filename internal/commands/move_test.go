@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetMoveFlags() {
+	movePretty = false
+	moveBefore = ""
+	moveAfter = ""
+	moveTop = false
+	moveBottom = false
+}
+
+func TestMoveCommand(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetMoveFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	now := time.Now()
+	for i, id := range []string{"aaaa", "bbbb", "cccc"} {
+		created := now.Add(time.Duration(i) * time.Millisecond)
+		require.NoError(t, store.SaveTask(&models.Task{ID: id, Name: id, Status: models.StatusTodo, Created: created, Updated: created}))
+	}
+
+	resetMoveFlags()
+	moveTop = true
+	require.NoError(t, runMove(nil, []string{"CCCC"}))
+
+	resetMoveFlags()
+	movePretty = true
+	moveAfter = "aaaa"
+	require.NoError(t, runMove(nil, []string{"bbbb"}))
+
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	models.SortByRank(tasks)
+	assert.Equal(t, "cccc", tasks[0].ID)
+	assert.Equal(t, "aaaa", tasks[1].ID)
+	assert.Equal(t, "bbbb", tasks[2].ID)
+
+	resetMoveFlags()
+	assert.ErrorContains(t, runMove(nil, []string{"aaaa"}), "exactly one")
+	moveTop, moveBottom = true, true
+	assert.ErrorContains(t, runMove(nil, []string{"aaaa"}), "exactly one")
+
+	resetMoveFlags()
+	moveBefore = "bad"
+	assert.ErrorContains(t, runMove(nil, []string{"aaaa"}), "invalid task ID")
+	assert.ErrorContains(t, runMove(nil, []string{"x"}), "invalid task ID")
+}
@@ -3,8 +3,12 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
 	"github.com/simonspoon/clipm/internal/storage"
 	"github.com/spf13/cobra"
 )
@@ -12,6 +16,8 @@ import (
 var (
 	nextPretty    bool
 	nextUnclaimed bool
+	nextWhere     string
+	nextOutput    taskOutput
 )
 
 var nextCmd = &cobra.Command{
@@ -22,31 +28,43 @@ var nextCmd = &cobra.Command{
 When in-progress tasks exist: returns todo children (then siblings) of the deepest in-progress task, walking up the hierarchy as needed.
 When no in-progress tasks: returns a list of root-level todo candidates.
 
-Blocked tasks are always skipped. Use --unclaimed to also skip tasks that have an owner.`,
+Blocked tasks are always skipped. Use --unclaimed to also skip tasks that have an owner,
+and --where to skip tasks that do not match a query.`,
 	RunE: runNext,
 }
 
 func init() {
 	nextCmd.Flags().BoolVar(&nextPretty, "pretty", false, "Pretty print output")
 	nextCmd.Flags().BoolVar(&nextUnclaimed, "unclaimed", false, "Skip tasks that have an owner")
+	addTaskOutputFlags(nextCmd, &nextOutput)
+	nextCmd.Flags().StringVar(&nextWhere, "where", "", "Only suggest tasks matching a query, e.g. \"tag:backend\"")
 }
 
 func runNext(cmd *cobra.Command, args []string) error {
+	if err := nextOutput.prepare(outputMode(nextPretty)); err != nil {
+		return err
+	}
+
 	// Load storage
 	store, err := storage.NewStorage()
 	if err != nil {
 		return err
 	}
 
+	keep, err := nextFilter(store)
+	if err != nil {
+		return err
+	}
+
 	// Get next task
-	result, err := store.GetNextTaskFiltered(nextUnclaimed)
+	result, err := store.GetNextTaskMatching(keep)
 	if err != nil {
 		return err
 	}
 
 	// Handle single task result
 	if result.Task != nil {
-		if nextPretty {
+		if nextOutput.mode == outputPretty {
 			cyan := color.New(color.FgCyan)
 			cyan.Printf("Next task: %s - %s\n", result.Task.ID, result.Task.Name)
 			if result.Task.Description != "" {
@@ -61,38 +79,88 @@ func runNext(cmd *cobra.Command, args []string) error {
 			if result.Task.Result != "" {
 				fmt.Printf("Result:      %s\n", result.Task.Result)
 			}
-		} else {
-			out, _ := json.Marshal(result)
-			fmt.Println(string(out))
+			return nil
 		}
-		return nil
+		return writeNextResult(os.Stdout, result)
 	}
 
 	// Handle candidates list
 	if len(result.Candidates) > 0 {
-		if nextPretty {
+		if nextOutput.mode == outputPretty {
 			yellow := color.New(color.FgYellow)
 			yellow.Println("No task in progress. Available candidates:")
 			for i := range result.Candidates {
 				fmt.Printf("  %d. %s - %s\n", i+1, result.Candidates[i].ID, result.Candidates[i].Name)
 			}
-		} else {
-			out, _ := json.Marshal(result)
-			fmt.Println(string(out))
+			return nil
 		}
-		return nil
+		return writeNextResult(os.Stdout, result)
 	}
 
 	// No tasks at all
-	if nextPretty {
+	if nextOutput.mode == outputPretty {
 		if result.BlockedCount > 0 {
 			fmt.Printf("No unblocked tasks. %d task(s) blocked.\n", result.BlockedCount)
 		} else {
 			fmt.Println("No tasks in queue")
 		}
-	} else {
-		out, _ := json.Marshal(result)
-		fmt.Println(string(out))
+		return nil
+	}
+	return writeNextResult(os.Stdout, result)
+}
+
+// nextFilter combines --unclaimed and --where into one predicate, or nil when
+// neither is set
+func nextFilter(store *storage.Storage) (func(*models.Task) bool, error) {
+	if nextWhere == "" {
+		if nextUnclaimed {
+			return func(t *models.Task) bool { return t.Owner == nil }, nil
+		}
+		return nil, nil
+	}
+
+	query, err := models.ParseQuery(nextWhere)
+	if err != nil {
+		return nil, err
+	}
+	all, err := store.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	match := query.Matcher(all, time.Now())
+	return func(t *models.Task) bool {
+		return (!nextUnclaimed || t.Owner == nil) && match(t)
+	}, nil
+}
+
+// writeNextResult prints a next result in the output encoding, with its tasks
+// projected to --fields, or runs the --format template on each suggested task
+func writeNextResult(w io.Writer, result *storage.NextResult) error {
+	if nextOutput.tmpl != nil {
+		if result.Task != nil {
+			return nextOutput.execute(w, result.Task)
+		}
+		return nextOutput.writeTasks(w, result.Candidates)
+	}
+	if len(nextOutput.keys) == 0 {
+		return nextOutput.render(w, result)
+	}
+
+	projected := struct {
+		Task         json.RawMessage   `json:"task,omitempty"`
+		Candidates   []json.RawMessage `json:"candidates,omitempty"`
+		BlockedCount int               `json:"blockedCount,omitempty"`
+	}{BlockedCount: result.BlockedCount}
+	var err error
+	if result.Task != nil {
+		if projected.Task, err = nextOutput.project(result.Task); err != nil {
+			return err
+		}
+	}
+	if len(result.Candidates) > 0 {
+		if projected.Candidates, err = nextOutput.projectAll(result.Candidates); err != nil {
+			return err
+		}
 	}
-	return nil
+	return nextOutput.render(w, projected)
 }
@@ -1,8 +1,10 @@
 package commands
 
 import (
-	"encoding/json"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
@@ -11,29 +13,79 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var notePretty bool
+var (
+	notePretty bool
+	noteKind   string
+	noteAuthor string
+)
 
 var noteCmd = &cobra.Command{
 	Use:   "note <id> <message>",
 	Short: "Add a note to a task",
-	Long:  `Append an observation or progress update to a task.`,
+	Long: `Append an observation or progress update to a task.
+
+Each note gets an ID, an author (--author, or CLIPM_AGENT when unset) and a kind:
+` + strings.Join(models.NoteKinds, ", ") + ` (default observation).
+Use 'note list', 'note edit' and 'note delete' to manage existing notes.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runNote,
+}
+
+var noteListCmd = &cobra.Command{
+	Use:   "list <id>",
+	Short: "List a task's notes",
+	Long:  `List a task's notes, optionally only those of one --kind or --author.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runNoteList,
+}
+
+var noteEditCmd = &cobra.Command{
+	Use:   "edit <id> <note-id> [message]",
+	Short: "Change a note's text or kind",
+	Long:  `Replace a note's text and/or change its --kind. The note keeps its ID, author and timestamp.`,
+	Args:  cobra.RangeArgs(2, 3),
+	RunE:  runNoteEdit,
+}
+
+var noteDeleteCmd = &cobra.Command{
+	Use:   "delete <id> <note-id>",
+	Short: "Delete a note",
 	Args:  cobra.ExactArgs(2),
-	RunE:  runNote,
+	RunE:  runNoteDelete,
 }
 
 func init() {
-	noteCmd.Flags().BoolVar(&notePretty, "pretty", false, "Pretty print output")
+	noteCmd.PersistentFlags().BoolVar(&notePretty, "pretty", false, "Pretty print output")
+	noteCmd.PersistentFlags().StringVar(&noteKind, "kind", "", "Note kind: "+strings.Join(models.NoteKinds, ", "))
+	noteCmd.Flags().StringVar(&noteAuthor, "author", "", "Note author (defaults to $CLIPM_AGENT)")
+	noteListCmd.Flags().StringVar(&noteAuthor, "author", "", "Only list notes by this author")
+	noteCmd.AddCommand(noteListCmd)
+	noteCmd.AddCommand(noteEditCmd)
+	noteCmd.AddCommand(noteDeleteCmd)
 }
 
 func runNote(cmd *cobra.Command, args []string) error {
 	id := models.NormalizeTaskID(args[0])
 	if !models.IsValidTaskID(id) {
-		return fmt.Errorf("invalid task ID: %s", args[0])
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
 	}
 
 	message := args[1]
 	if message == "" {
-		return fmt.Errorf("note message cannot be empty")
+		return models.Errorf(models.CodeInvalidArgument, "note message cannot be empty")
+	}
+
+	kind := models.NoteObservation
+	if noteKind != "" {
+		if err := validateNoteKind(noteKind); err != nil {
+			return err
+		}
+		kind = noteKind
+	}
+
+	author := noteAuthor
+	if author == "" {
+		author = storage.CurrentActor()
 	}
 
 	store, err := storage.NewStorage()
@@ -41,33 +93,206 @@ func runNote(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	task, err := store.LoadTask(id)
+	task, err := loadNoteTask(store, id)
 	if err != nil {
-		if err == storage.ErrTaskNotFound {
-			return fmt.Errorf("task %s not found", id)
-		}
 		return err
 	}
 
-	note := models.Note{
+	now := time.Now()
+	note := task.AddNote(models.Note{
 		Content:   message,
-		Timestamp: time.Now(),
+		Kind:      kind,
+		Author:    author,
+		Timestamp: now,
+	})
+	task.Updated = now
+
+	if err := store.SaveTask(task); err != nil {
+		return err
+	}
+
+	return render(os.Stdout, outputMode(notePretty), task, func() {
+		green := color.New(color.FgGreen)
+		green.Printf("Added note %d to task %s\n", note.ID, id)
+	})
+}
+
+func runNoteList(cmd *cobra.Command, args []string) error {
+	id := models.NormalizeTaskID(args[0])
+	if !models.IsValidTaskID(id) {
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
+	}
+	if noteKind != "" {
+		if err := validateNoteKind(noteKind); err != nil {
+			return err
+		}
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
 	}
 
-	task.Notes = append(task.Notes, note)
-	task.Updated = time.Now()
+	task, err := loadNoteTask(store, id)
+	if err != nil {
+		return err
+	}
+
+	notes := filterNotes(task.Notes, noteKind, noteAuthor)
+
+	return render(os.Stdout, outputMode(notePretty), notes, func() {
+		if len(notes) == 0 {
+			fmt.Println("No notes found")
+			return
+		}
+		for i := range notes {
+			printNote(&notes[i])
+		}
+	})
+}
+
+func runNoteEdit(cmd *cobra.Command, args []string) error {
+	if len(args) < 3 && noteKind == "" {
+		return models.Errorf(models.CodeInvalidArgument, "nothing to edit: pass a new message or --kind")
+	}
+	if len(args) == 3 && args[2] == "" {
+		return models.Errorf(models.CodeInvalidArgument, "note message cannot be empty")
+	}
+	if noteKind != "" {
+		if err := validateNoteKind(noteKind); err != nil {
+			return err
+		}
+	}
+
+	return updateNote(args[0], args[1], func(task *models.Task, note *models.Note, now time.Time) (any, string) {
+		if len(args) == 3 {
+			note.Content = args[2]
+		}
+		if noteKind != "" {
+			note.Kind = noteKind
+		}
+		note.Edited = &now
+		return note, fmt.Sprintf("Updated note %d on task %s", note.ID, task.ID)
+	})
+}
+
+// filterNotes returns the notes matching kind and author; empty values match all
+func filterNotes(notes []models.Note, kind, author string) []models.Note {
+	filtered := []models.Note{}
+	for _, note := range notes {
+		if kind != "" && note.EffectiveKind() != kind {
+			continue
+		}
+		if author != "" && note.Author != author {
+			continue
+		}
+		filtered = append(filtered, note)
+	}
+	return filtered
+}
+
+type noteDeleteResult struct {
+	Success bool   `json:"success"`
+	Task    string `json:"task"`
+	Note    int    `json:"note"`
+}
+
+func runNoteDelete(cmd *cobra.Command, args []string) error {
+	return updateNote(args[0], args[1], func(task *models.Task, note *models.Note, now time.Time) (any, string) {
+		noteID := note.ID
+		task.DeleteNote(noteID)
+		return noteDeleteResult{Success: true, Task: task.ID, Note: noteID},
+			fmt.Sprintf("Deleted note %d from task %s", noteID, task.ID)
+	})
+}
+
+// updateNote finds a note, applies fn and saves the task, printing fn's JSON
+// result or its message with --pretty
+func updateNote(rawID, rawNoteID string, fn func(task *models.Task, note *models.Note, now time.Time) (any, string)) error {
+	id := models.NormalizeTaskID(rawID)
+	if !models.IsValidTaskID(id) {
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", rawID).With("id", rawID)
+	}
+	noteID, err := strconv.Atoi(rawNoteID)
+	if err != nil || noteID < 1 {
+		return models.Errorf(models.CodeInvalidArgument, "invalid note ID: %s", rawNoteID)
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	task, err := loadNoteTask(store, id)
+	if err != nil {
+		return err
+	}
+
+	note := task.FindNote(noteID)
+	if note == nil {
+		return models.Errorf(models.CodeNotFound, "task %s has no note %d", id, noteID).With("id", id).With("noteId", noteID)
+	}
+
+	now := time.Now()
+	result, message := fn(task, note, now)
+	task.Updated = now
 
 	if err := store.SaveTask(task); err != nil {
 		return err
 	}
 
-	if notePretty {
+	return render(os.Stdout, outputMode(notePretty), result, func() {
 		green := color.New(color.FgGreen)
-		green.Printf("Added note to task %s\n", id)
-	} else {
-		out, _ := json.Marshal(task)
-		fmt.Println(string(out))
+		green.Println(message)
+	})
+}
+
+func loadNoteTask(store *storage.Storage, id string) (*models.Task, error) {
+	task, err := store.LoadTask(id)
+	if err != nil {
+		if err == storage.ErrTaskNotFound {
+			return nil, models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+		}
+		return nil, err
 	}
+	return task, nil
+}
 
+func validateNoteKind(kind string) error {
+	if !models.IsValidNoteKind(kind) {
+		return models.Errorf(models.CodeInvalidArgument, "invalid note kind %q. Must be one of: %s", kind, strings.Join(models.NoteKinds, ", "))
+	}
 	return nil
 }
+
+// printNote prints one note as "[time] #id kind (author): content"
+func printNote(note *models.Note) {
+	gray := color.New(color.FgHiBlack)
+	white := color.New(color.FgWhite)
+
+	gray.Printf("  [%s] #%d ", note.Timestamp.Format("2006-01-02 15:04"), note.ID)
+	kindColor(note.EffectiveKind()).Print(note.EffectiveKind())
+	if note.Author != "" {
+		gray.Printf(" (%s)", note.Author)
+	}
+	white.Printf(": %s", note.Content)
+	if note.Edited != nil {
+		gray.Print(" (edited)")
+	}
+	fmt.Println()
+}
+
+func kindColor(kind string) *color.Color {
+	switch kind {
+	case models.NoteDecision:
+		return color.New(color.FgGreen)
+	case models.NoteQuestion:
+		return color.New(color.FgYellow)
+	case models.NoteError:
+		return color.New(color.FgRed)
+	case models.NoteHandoff:
+		return color.New(color.FgMagenta)
+	default:
+		return color.New(color.FgCyan)
+	}
+}
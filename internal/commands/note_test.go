@@ -138,3 +138,124 @@ func TestNoteCommand_Pretty(t *testing.T) {
 	err = runNote(nil, []string{task.ID, "Test note"})
 	require.NoError(t, err)
 }
+
+func resetNoteFlags() {
+	notePretty = false
+	noteKind = ""
+	noteAuthor = ""
+}
+
+func TestNoteCommand_KindAndAuthor(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetNoteFlags()
+	t.Setenv(storage.AgentEnv, "agent-1")
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createStructuredTask(t, store)
+
+	resetNoteFlags()
+	require.NoError(t, runNote(nil, []string{task.ID, "Looked around"}))
+
+	noteKind = models.NoteDecision
+	noteAuthor = "reviewer"
+	require.NoError(t, runNote(nil, []string{task.ID, "Use the cache"}))
+
+	updated, err := store.LoadTask(task.ID)
+	require.NoError(t, err)
+	require.Len(t, updated.Notes, 2)
+	assert.Equal(t, 1, updated.Notes[0].ID)
+	assert.Equal(t, models.NoteObservation, updated.Notes[0].Kind)
+	assert.Equal(t, "agent-1", updated.Notes[0].Author)
+	assert.Equal(t, 2, updated.Notes[1].ID)
+	assert.Equal(t, models.NoteDecision, updated.Notes[1].Kind)
+	assert.Equal(t, "reviewer", updated.Notes[1].Author)
+
+	resetNoteFlags()
+	noteKind = "rumour"
+	assert.ErrorContains(t, runNote(nil, []string{task.ID, "x"}), "invalid note kind")
+}
+
+func TestNoteListCommand(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetNoteFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createStructuredTask(t, store)
+	task.AddNote(models.Note{Content: "a", Kind: models.NoteError, Author: "x", Timestamp: time.Now()})
+	task.AddNote(models.Note{Content: "b", Kind: models.NoteQuestion, Author: "y", Timestamp: time.Now()})
+	task.AddNote(models.Note{Content: "c", Author: "y", Timestamp: time.Now()})
+	require.NoError(t, store.SaveTask(task))
+
+	assert.Len(t, filterNotes(task.Notes, "", ""), 3)
+	assert.Len(t, filterNotes(task.Notes, "", "y"), 2)
+	// Notes without a kind are observations
+	observations := filterNotes(task.Notes, models.NoteObservation, "y")
+	require.Len(t, observations, 1)
+	assert.Equal(t, "c", observations[0].Content)
+	assert.Empty(t, filterNotes(task.Notes, models.NoteHandoff, ""))
+
+	resetNoteFlags()
+	require.NoError(t, runNoteList(nil, []string{task.ID}))
+	noteKind = "rumour"
+	assert.ErrorContains(t, runNoteList(nil, []string{task.ID}), "invalid note kind")
+	noteKind = models.NoteError
+	notePretty = true
+	require.NoError(t, runNoteList(nil, []string{task.ID}))
+}
+
+func TestNoteEditAndDeleteCommands(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetNoteFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createStructuredTask(t, store)
+	task.AddNote(models.Note{Content: "first", Author: "x", Timestamp: time.Now()})
+	task.AddNote(models.Note{Content: "second", Author: "x", Timestamp: time.Now()})
+	require.NoError(t, store.SaveTask(task))
+
+	resetNoteFlags()
+	noteKind = models.NoteDecision
+	require.NoError(t, runNoteEdit(nil, []string{task.ID, "2", "second, revised"}))
+
+	updated, err := store.LoadTask(task.ID)
+	require.NoError(t, err)
+	note := updated.FindNote(2)
+	require.NotNil(t, note)
+	assert.Equal(t, "second, revised", note.Content)
+	assert.Equal(t, models.NoteDecision, note.Kind)
+	assert.Equal(t, "x", note.Author)
+	assert.NotNil(t, note.Edited)
+
+	resetNoteFlags()
+	assert.ErrorContains(t, runNoteEdit(nil, []string{task.ID, "2"}), "nothing to edit")
+	assert.ErrorContains(t, runNoteEdit(nil, []string{task.ID, "9", "x"}), "has no note 9")
+	assert.ErrorContains(t, runNoteEdit(nil, []string{task.ID, "one", "x"}), "invalid note ID")
+
+	require.NoError(t, runNoteDelete(nil, []string{task.ID, "1"}))
+	updated, err = store.LoadTask(task.ID)
+	require.NoError(t, err)
+	require.Len(t, updated.Notes, 1)
+	assert.Equal(t, 2, updated.Notes[0].ID)
+
+	// New notes never take the ID of a remaining note
+	require.NoError(t, runNote(nil, []string{task.ID, "third"}))
+	updated, err = store.LoadTask(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, updated.Notes[1].ID)
+
+	assert.ErrorContains(t, runNoteDelete(nil, []string{task.ID, "1"}), "has no note 1")
+
+	// Deleting the newest note does not free its ID
+	require.NoError(t, runNoteDelete(nil, []string{task.ID, "3"}))
+	require.NoError(t, runNote(nil, []string{task.ID, "fourth"}))
+	updated, err = store.LoadTask(task.ID)
+	require.NoError(t, err)
+	require.Len(t, updated.Notes, 2)
+	assert.Equal(t, 4, updated.Notes[1].ID)
+}
@@ -0,0 +1,267 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// taskOutput holds the --fields and --format flags shared by the commands that
+// print tasks: list, show, next, status and watch
+type taskOutput struct {
+	fields string
+	format string
+
+	// Set by prepare
+	mode string
+	keys []string
+	tmpl *template.Template
+}
+
+func addTaskOutputFlags(cmd *cobra.Command, out *taskOutput) {
+	cmd.Flags().StringVar(&out.fields, "fields", "", "Only print these JSON fields, comma-separated (e.g. id,name,status)")
+	cmd.Flags().StringVar(&out.format, "format", "", "Print with a Go template instead of JSON (e.g. '{{.ID}} {{.Name}}')")
+}
+
+// prepare validates the flags for the given output mode and parses --fields
+// and --format. extra lists JSON keys the command prints alongside the task's
+// own.
+func (o *taskOutput) prepare(mode string, extra ...string) error {
+	o.mode, o.keys, o.tmpl = mode, nil, nil
+	if o.fields != "" && o.format != "" {
+		return models.Errorf(models.CodeInvalidArgument, "--fields and --format are mutually exclusive")
+	}
+	if o.format != "" && outputFlag != "" {
+		return models.Errorf(models.CodeInvalidArgument, "--format cannot be combined with --output")
+	}
+	if mode == outputPretty && (o.fields != "" || o.format != "") {
+		return models.Errorf(models.CodeInvalidArgument, "pretty output cannot be combined with --fields or --format")
+	}
+
+	if o.fields != "" {
+		known := append(taskJSONKeys(), extra...)
+		for _, key := range strings.Split(o.fields, ",") {
+			key = strings.TrimSpace(key)
+			if key == "" {
+				continue
+			}
+			if !slices.Contains(known, key) {
+				return models.Errorf(models.CodeInvalidArgument, "unknown field %q in --fields. Must be one of: %s", key, strings.Join(known, ", "))
+			}
+			o.keys = append(o.keys, key)
+		}
+		if len(o.keys) == 0 {
+			return models.Errorf(models.CodeInvalidArgument, "--fields cannot be empty")
+		}
+	}
+
+	if o.format != "" {
+		tmpl, err := template.New("format").Funcs(templateFuncs).Option("missingkey=error").Parse(o.format)
+		if err != nil {
+			return models.Errorf(models.CodeInvalidArgument, "invalid --format template: %w", err)
+		}
+		o.tmpl = tmpl
+	}
+	return nil
+}
+
+// project returns v as JSON with only the --fields keys, in the order given.
+// Keys v does not have are printed as null. Without --fields, v is returned
+// whole.
+func (o *taskOutput) project(v any) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(o.keys) == 0 {
+		return data, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		b.Write(name)
+		b.WriteByte(':')
+		if value, ok := all[key]; ok {
+			b.Write(value)
+		} else {
+			b.WriteString("null")
+		}
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// projectAll projects each task in a list
+func (o *taskOutput) projectAll(tasks []models.Task) ([]json.RawMessage, error) {
+	out := make([]json.RawMessage, 0, len(tasks))
+	for i := range tasks {
+		p, err := o.project(&tasks[i])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// execute runs the --format template on v, ending the output with a newline
+func (o *taskOutput) execute(w io.Writer, v any) error {
+	var b bytes.Buffer
+	if err := o.tmpl.Execute(&b, v); err != nil {
+		return models.Errorf(models.CodeInvalidArgument, "--format template: %w", err)
+	}
+	if b.Len() == 0 || b.Bytes()[b.Len()-1] != '\n' {
+		b.WriteByte('\n')
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// writeValue prints v with the --format template, or projected to --fields in
+// the output encoding
+func (o *taskOutput) writeValue(w io.Writer, v any) error {
+	if o.tmpl != nil {
+		return o.execute(w, v)
+	}
+	out, err := o.project(v)
+	if err != nil {
+		return err
+	}
+	return o.render(w, out)
+}
+
+// writeTasks prints a task list: one template execution per task with
+// --format, otherwise a list in the output encoding
+func (o *taskOutput) writeTasks(w io.Writer, tasks []models.Task) error {
+	if o.tmpl != nil {
+		for i := range tasks {
+			if err := o.execute(w, &tasks[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(o.keys) == 0 {
+		// A nil list stays null in JSON, as it always has been
+		if tasks == nil && o.mode != outputJSON {
+			tasks = []models.Task{}
+		}
+		return o.render(w, tasks)
+	}
+	out, err := o.projectAll(tasks)
+	if err != nil {
+		return err
+	}
+	return o.render(w, out)
+}
+
+// render prints v in the output encoding
+func (o *taskOutput) render(w io.Writer, v any) error {
+	return render(w, o.mode, v, nil)
+}
+
+// taskJSONKeys returns the JSON names of the fields of models.Task
+func taskJSONKeys() []string {
+	t := reflect.TypeOf(models.Task{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// templateFuncs are the helpers available to --format templates
+var templateFuncs = template.FuncMap{
+	// date formats a time with a Go layout in local time: {{.Created | date "2006-01-02"}}
+	"date": func(layout string, v any) string {
+		t, ok := templateTime(v)
+		if !ok {
+			return ""
+		}
+		return t.Local().Format(layout)
+	},
+	// ago is the time since t, rounded to the largest unit: 45s, 5m, 3h, 2d
+	"ago": func(v any) string {
+		t, ok := templateTime(v)
+		if !ok {
+			return ""
+		}
+		return formatAgo(time.Since(t))
+	},
+	// join joins a list with a separator: {{.BlockedBy | join ","}}
+	"join": func(sep string, v any) string {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return fmt.Sprint(v)
+		}
+		parts := make([]string, rv.Len())
+		for i := range parts {
+			parts[i] = fmt.Sprint(reflect.Indirect(rv.Index(i)).Interface())
+		}
+		return strings.Join(parts, sep)
+	},
+	// default returns def when v is nil, a nil pointer or empty: {{.Owner | default "-"}}
+	"default": func(def, v any) any {
+		rv := reflect.ValueOf(v)
+		if !rv.IsValid() || rv.IsZero() {
+			return def
+		}
+		if rv.Kind() == reflect.Pointer {
+			return rv.Elem().Interface()
+		}
+		if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.Len() == 0 {
+			return def
+		}
+		return v
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	// json renders any value as JSON
+	"json": func(v any) (string, error) {
+		out, err := json.Marshal(v)
+		return string(out), err
+	},
+}
+
+func templateTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, !t.IsZero()
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, false
+		}
+		return *t, true
+	}
+	return time.Time{}, false
+}
+
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
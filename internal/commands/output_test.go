@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func outputTasks() []models.Task {
+	created := time.Date(2026, 3, 1, 9, 30, 0, 0, time.Local)
+	owner := "agent-1"
+	return []models.Task{
+		{ID: "aaaa", Name: "First", Status: models.StatusTodo, Owner: &owner, BlockedBy: []string{"bbbb", "cccc"}, Created: created, Updated: created},
+		{ID: "bbbb", Name: "Second", Status: models.StatusDone, Created: created, Updated: created},
+	}
+}
+
+func TestTaskOutput_Prepare(t *testing.T) {
+	out := taskOutput{fields: "id, name"}
+	require.NoError(t, out.prepare(outputJSON))
+	assert.Equal(t, []string{"id", "name"}, out.keys)
+
+	out = taskOutput{fields: "id,progress"}
+	assert.ErrorContains(t, out.prepare(outputJSON), `unknown field "progress"`)
+	require.NoError(t, out.prepare(outputJSON, "progress"))
+
+	out = taskOutput{fields: " , "}
+	assert.ErrorContains(t, out.prepare(outputJSON), "cannot be empty")
+
+	out = taskOutput{fields: "id", format: "{{.ID}}"}
+	assert.ErrorContains(t, out.prepare(outputJSON), "mutually exclusive")
+
+	out = taskOutput{format: "{{.ID}}"}
+	assert.ErrorContains(t, out.prepare(outputPretty), "pretty output cannot be combined")
+
+	out = taskOutput{format: "{{.ID"}
+	assert.ErrorContains(t, out.prepare(outputJSON), "invalid --format template")
+}
+
+func TestTaskOutput_Fields(t *testing.T) {
+	out := taskOutput{fields: "owner,id,outcome"}
+	require.NoError(t, out.prepare(outputJSON))
+
+	var buf bytes.Buffer
+	require.NoError(t, out.writeTasks(&buf, outputTasks()))
+	// Keys keep the requested order, and missing ones are null
+	assert.Equal(t, `[{"owner":"agent-1","id":"aaaa","outcome":null},{"owner":null,"id":"bbbb","outcome":null}]`+"\n", buf.String())
+
+	buf.Reset()
+	tasks := outputTasks()
+	require.NoError(t, out.writeValue(&buf, &tasks[0]))
+	assert.Equal(t, `{"owner":"agent-1","id":"aaaa","outcome":null}`+"\n", buf.String())
+
+	// Without flags, output is unchanged
+	out = taskOutput{}
+	require.NoError(t, out.prepare(outputJSON))
+	buf.Reset()
+	require.NoError(t, out.writeTasks(&buf, nil))
+	assert.Equal(t, "null\n", buf.String())
+}
+
+func TestTaskOutput_Format(t *testing.T) {
+	out := taskOutput{format: `{{.ID}} {{.Name | upper}} {{.Owner | default "-"}} {{.Created | date "2006-01-02"}} [{{.BlockedBy | join ","}}]`}
+	require.NoError(t, out.prepare(outputJSON))
+
+	var buf bytes.Buffer
+	require.NoError(t, out.writeTasks(&buf, outputTasks()))
+	assert.Equal(t, "aaaa FIRST agent-1 2026-03-01 [bbbb,cccc]\nbbbb SECOND - 2026-03-01 []\n", buf.String())
+
+	out = taskOutput{format: "{{.Missing}}"}
+	require.NoError(t, out.prepare(outputJSON))
+	tasks := outputTasks()
+	assert.ErrorContains(t, out.writeValue(&buf, &tasks[0]), "can't evaluate field Missing")
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	assert.Equal(t, "3h", formatAgo(3*time.Hour+10*time.Minute))
+	assert.Equal(t, "2d", formatAgo(50*time.Hour))
+	assert.Equal(t, "45s", formatAgo(45*time.Second))
+
+	out := taskOutput{format: `{{json .Fields}} {{.Updated | ago}} {{.Recurrence | default "none"}}`}
+	require.NoError(t, out.prepare(outputJSON))
+	var buf bytes.Buffer
+	task := models.Task{Fields: map[string]any{"size": "m"}, Updated: time.Now().Add(-5 * time.Minute)}
+	require.NoError(t, out.writeValue(&buf, &task))
+	assert.Equal(t, `{"size":"m"} 5m none`+"\n", buf.String())
+}
+
+func TestWriteNextResult(t *testing.T) {
+	defer func() { nextOutput = taskOutput{} }()
+	tasks := outputTasks()
+
+	nextOutput = taskOutput{fields: "id"}
+	require.NoError(t, nextOutput.prepare(outputJSON))
+	var buf bytes.Buffer
+	require.NoError(t, writeNextResult(&buf, &storage.NextResult{Candidates: tasks}))
+	assert.Equal(t, `{"candidates":[{"id":"aaaa"},{"id":"bbbb"}]}`+"\n", buf.String())
+
+	nextOutput = taskOutput{format: "{{.Name}}"}
+	require.NoError(t, nextOutput.prepare(outputJSON))
+	buf.Reset()
+	require.NoError(t, writeNextResult(&buf, &storage.NextResult{Task: &tasks[1]}))
+	assert.Equal(t, "Second\n", buf.String())
+}
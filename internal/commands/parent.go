@@ -1,8 +1,7 @@
 package commands
 
 import (
-	"encoding/json"
-	"fmt"
+	"os"
 	"time"
 
 	"github.com/fatih/color"
@@ -29,16 +28,16 @@ func runParent(cmd *cobra.Command, args []string) error {
 	// Normalize and validate task IDs
 	childID := models.NormalizeTaskID(args[0])
 	if !models.IsValidTaskID(childID) {
-		return fmt.Errorf("invalid task ID: %s", args[0])
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
 	}
 	parentID := models.NormalizeTaskID(args[1])
 	if !models.IsValidTaskID(parentID) {
-		return fmt.Errorf("invalid parent ID: %s", args[1])
+		return models.Errorf(models.CodeInvalidID, "invalid parent ID: %s", args[1]).With("id", args[1])
 	}
 
 	// Can't parent to self
 	if childID == parentID {
-		return fmt.Errorf("cannot set task as its own parent")
+		return models.Errorf(models.CodeInvalidArgument, "cannot set task as its own parent")
 	}
 
 	// Load storage
@@ -50,43 +49,39 @@ func runParent(cmd *cobra.Command, args []string) error {
 	// Check child task exists
 	childTask, err := store.LoadTask(childID)
 	if err != nil {
-		return fmt.Errorf("task %s not found", childID)
+		return models.Errorf(models.CodeNotFound, "task %s not found", childID).With("id", childID)
 	}
 
 	// Check parent task exists
 	parentTask, err := store.LoadTask(parentID)
 	if err != nil {
-		return fmt.Errorf("parent task %s not found", parentID)
+		return models.Errorf(models.CodeNotFound, "parent task %s not found", parentID).With("id", parentID)
 	}
 
-	// Check parent is not done
-	if parentTask.Status == models.StatusDone {
-		return fmt.Errorf("cannot set done task %s as parent", parentID)
+	// Check parent is not closed
+	if models.IsClosed(parentTask.Status) {
+		return models.Errorf(models.CodeConflict, "cannot set %s task %s as parent", parentTask.Status, parentID)
 	}
 
 	// Check for circular dependencies
 	if wouldCreateCycle(store, childID, parentID) {
-		return fmt.Errorf("cannot set parent - would create circular dependency")
+		return models.Errorf(models.CodeConflict, "cannot set parent - would create circular dependency")
 	}
 
 	// Update parent and timestamp
-	childTask.Parent = &parentID
-	childTask.Updated = time.Now()
+	now := time.Now()
+	childTask.SetParent(&parentID, storage.CurrentActor(), now)
+	childTask.Updated = now
 
 	// Save the task
 	if err := store.SaveTask(childTask); err != nil {
 		return err
 	}
 
-	if parentPretty {
+	return render(os.Stdout, outputMode(parentPretty), childTask, func() {
 		green := color.New(color.FgGreen)
 		green.Printf("Task %s is now a child of %s\n", childID, parentID)
-	} else {
-		out, _ := json.Marshal(childTask)
-		fmt.Println(string(out))
-	}
-
-	return nil
+	})
 }
 
 // wouldCreateCycle checks if setting parentID as the parent of childID would create a cycle
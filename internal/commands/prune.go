@@ -1,8 +1,8 @@
 package commands
 
 import (
-	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/fatih/color"
 	"github.com/simonspoon/clipm/internal/models"
@@ -15,8 +15,9 @@ var prunePretty bool
 var pruneCmd = &cobra.Command{
 	Use:   "prune",
 	Short: "Delete all completed tasks",
-	Long:  `Delete all tasks with status 'done' that have no undone children. Safe operation - won't delete tasks with incomplete subtasks.`,
-	RunE:  runPrune,
+	Long: `Delete all tasks with status 'done' that have no undone children. Safe operation - won't delete tasks with incomplete subtasks
+or done recurring tasks whose next occurrence has not been created yet.`,
+	RunE: runPrune,
 }
 
 func init() {
@@ -41,10 +42,16 @@ func runPrune(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Find tasks that can be pruned (done and no undone children)
+	// Find tasks that can be pruned (closed and no undone children)
 	var toPrune []string
 	for i := range tasks {
-		if tasks[i].Status != models.StatusDone {
+		if !models.IsClosed(tasks[i].Status) {
+			continue
+		}
+
+		// Keep done recurring tasks until their next occurrence has been
+		// created; cancelled ones never recur
+		if tasks[i].Status == models.StatusDone && tasks[i].Recurrence.Pending() {
 			continue
 		}
 
@@ -62,20 +69,19 @@ func runPrune(cmd *cobra.Command, args []string) error {
 
 	if len(toPrune) == 0 {
 		result := pruneResult{Deleted: []string{}, Count: 0}
-		if prunePretty {
+		return render(os.Stdout, outputMode(prunePretty), result, func() {
 			fmt.Println("No completed tasks to prune")
-		} else {
-			out, _ := json.Marshal(result)
-			fmt.Println(string(out))
-		}
-		return nil
+		})
 	}
 
-	// Clean up BlockedBy references before deleting
+	// Clean up BlockedBy and link references before deleting
 	for _, id := range toPrune {
 		if err := store.RemoveFromAllBlockedBy(id); err != nil {
 			return err
 		}
+		if err := store.RemoveLinksTo(id); err != nil {
+			return err
+		}
 	}
 
 	// Delete the tasks
@@ -88,13 +94,8 @@ func runPrune(cmd *cobra.Command, args []string) error {
 		Count:   len(toPrune),
 	}
 
-	if prunePretty {
+	return render(os.Stdout, outputMode(prunePretty), result, func() {
 		green := color.New(color.FgGreen)
 		green.Printf("Pruned %d completed task(s)\n", len(toPrune))
-	} else {
-		out, _ := json.Marshal(result)
-		fmt.Println(string(out))
-	}
-
-	return nil
+	})
 }
@@ -195,3 +195,33 @@ func TestPruneCommand_Pretty(t *testing.T) {
 	err = runPrune(nil, nil)
 	require.NoError(t, err)
 }
+
+func TestPruneCommand_DeletesCancelledRecurringTasks(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	now := time.Now()
+	rule, err := models.ParseRecurrence("1d", now)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveTask(&models.Task{
+		ID:         "aaaa",
+		Name:       "Dropped Chore",
+		Status:     models.StatusCancelled,
+		Recurrence: rule,
+		Created:    now,
+		Updated:    now,
+	}))
+
+	prunePretty = false
+
+	err = runPrune(nil, nil)
+	require.NoError(t, err)
+
+	// A cancelled rule never spawns, so nothing holds the task back
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
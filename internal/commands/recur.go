@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var recurPretty bool
+
+var recurCmd = &cobra.Command{
+	Use:   "recur",
+	Short: "Manage recurring tasks",
+	Long: `Recurring tasks are created with 'clipm add --recur <spec>'. Marking one done creates
+the next instance when it is already due; scheduled occurrences are created by 'clipm recur run'.`,
+}
+
+var recurRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Create recurring task instances that are due",
+	Long: `Create the next instance of every completed recurring task whose next occurrence is due.
+Safe to run repeatedly, e.g. from cron: each occurrence is created only once.`,
+	Args: cobra.NoArgs,
+	RunE: runRecurRun,
+}
+
+func init() {
+	recurCmd.PersistentFlags().BoolVar(&recurPretty, "pretty", false, "Pretty print output")
+	recurCmd.AddCommand(recurRunCmd)
+}
+
+type recurRunResult struct {
+	Spawned []storage.RecurSpawn `json:"spawned"`
+	Count   int                  `json:"count"`
+}
+
+func runRecurRun(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	spawned, err := store.RunRecurrences(time.Now())
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, outputMode(recurPretty), recurRunResult{Spawned: spawned, Count: len(spawned)}, func() {
+		if len(spawned) == 0 {
+			fmt.Println("No recurring tasks due")
+			return
+		}
+		green := color.New(color.FgGreen)
+		green.Printf("Created %d recurring task(s)\n", len(spawned))
+		for _, s := range spawned {
+			fmt.Printf("  %s (from %s)\n", s.Task, s.From)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddCommand_Recur(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetAddFlags()
+
+	resetAddFlags()
+	addAction, addVerify, addResult = "rotate", "check", "report"
+	addRecur = "1w"
+	require.NoError(t, runAdd(nil, []string{"Rotate logs"}))
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.NotNil(t, tasks[0].Recurrence)
+	assert.Equal(t, "1w", tasks[0].Recurrence.Spec)
+	require.NotNil(t, tasks[0].Recurrence.Next)
+	assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), *tasks[0].Recurrence.Next, time.Minute)
+
+	addRecur = "whenever"
+	assert.ErrorContains(t, runAdd(nil, []string{"Bad"}), "invalid recurrence")
+}
+
+func TestStatusDone_SpawnsRecurrence(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { statusOutcome = ""; statusPretty = false }()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	id := createTestTask(t, store, "Triage issues", models.StatusInProgress, nil)
+	task, err := store.LoadTask(id)
+	require.NoError(t, err)
+	task.Recurrence, err = models.ParseRecurrence("on-done", time.Now())
+	require.NoError(t, err)
+	require.NoError(t, store.SaveTask(task))
+
+	statusOutcome = ""
+	statusPretty = false
+	require.NoError(t, runStatus(nil, []string{id, "done"}))
+
+	done, err := store.LoadTask(id)
+	require.NoError(t, err)
+	require.NotEmpty(t, done.Recurrence.Spawned)
+
+	next, err := store.LoadTask(done.Recurrence.Spawned)
+	require.NoError(t, err)
+	assert.Equal(t, "Triage issues", next.Name)
+	assert.Equal(t, models.StatusTodo, next.Status)
+	assert.True(t, next.Recurrence.Pending())
+
+	// Reopening and finishing again does not create a second copy
+	require.NoError(t, runStatus(nil, []string{id, "todo"}))
+	statusPretty = true
+	require.NoError(t, runStatus(nil, []string{id, "done"}))
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
+
+func TestStatusDone_ScheduledRecurrence(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { statusOutcome = ""; statusPretty = false; prunePretty = false; recurPretty = false }()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	id := createTestTask(t, store, "Bump dependencies", models.StatusTodo, nil)
+	task, err := store.LoadTask(id)
+	require.NoError(t, err)
+	task.Recurrence, err = models.ParseRecurrence("0 9 * * 1", time.Now())
+	require.NoError(t, err)
+	require.NoError(t, store.SaveTask(task))
+
+	statusOutcome = ""
+	statusPretty = true
+	require.NoError(t, runStatus(nil, []string{id, "done"}))
+
+	// Not due yet: nothing is created, and prune keeps the task
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	prunePretty = false
+	require.NoError(t, runPrune(nil, nil))
+	_, err = store.LoadTask(id)
+	require.NoError(t, err)
+
+	recurPretty = false
+	require.NoError(t, runRecurRun(nil, nil))
+	tasks, err = store.LoadAll()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	// Once due, recur run creates the next instance and the old one can be pruned
+	task, err = store.LoadTask(id)
+	require.NoError(t, err)
+	past := time.Now().Add(-time.Minute)
+	task.Recurrence.Next = &past
+	require.NoError(t, store.SaveTask(task))
+
+	recurPretty = true
+	require.NoError(t, runRecurRun(nil, nil))
+	tasks, err = store.LoadAll()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+
+	require.NoError(t, runPrune(nil, nil))
+	_, err = store.LoadTask(id)
+	assert.ErrorIs(t, err, storage.ErrTaskNotFound)
+}
+
+func TestEditCommand_Recur(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetEditFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createStructuredTask(t, store)
+
+	resetEditFlags()
+	editRecur = "@daily"
+	require.NoError(t, runEdit(nil, []string{task.ID}))
+	updated, err := store.LoadTask(task.ID)
+	require.NoError(t, err)
+	require.NotNil(t, updated.Recurrence)
+	assert.Equal(t, "@daily", updated.Recurrence.Spec)
+
+	editRecur = "bogus"
+	assert.ErrorContains(t, runEdit(nil, []string{task.ID}), "invalid recurrence")
+
+	editRecur = "none"
+	require.NoError(t, runEdit(nil, []string{task.ID}))
+	updated, err = store.LoadTask(task.ID)
+	require.NoError(t, err)
+	assert.Nil(t, updated.Recurrence)
+}
@@ -0,0 +1,407 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// Output encodings for --output
+const (
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+	outputYAML   = "yaml"
+	outputTable  = "table"
+	outputPretty = "pretty"
+)
+
+// outputFlag is the global --output flag; empty means the command's default
+var outputFlag string
+
+// tableTaskColumns are the columns a table shows for full task objects
+var tableTaskColumns = []string{"id", "status", "owner", "parent", "name"}
+
+// tableMaxColumns is how many columns a table shows before falling back to
+// tableTaskColumns for task-like rows
+const tableMaxColumns = 6
+
+func validateOutputFlag() error {
+	switch outputFlag {
+	case "", outputJSON, outputNDJSON, outputYAML, outputTable, outputPretty:
+		return nil
+	}
+	return models.Errorf(models.CodeInvalidArgument, "invalid output %q. Must be: json, ndjson, yaml, table, pretty", outputFlag)
+}
+
+// outputMode returns the encoding for a command: --output when given,
+// otherwise pretty when the command's --pretty flag is set, otherwise JSON
+func outputMode(pretty bool) string {
+	if outputFlag != "" {
+		return outputFlag
+	}
+	if pretty {
+		return outputPretty
+	}
+	return outputJSON
+}
+
+// render prints v in the given encoding. printPretty draws the command's
+// human-readable view for pretty output; commands without one pass nil and
+// get a table.
+func render(w io.Writer, mode string, v any, printPretty func()) error {
+	if mode == outputPretty && printPretty != nil {
+		printPretty()
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	switch mode {
+	case "", outputJSON:
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case outputNDJSON:
+		return writeNDJSON(w, data)
+	}
+
+	value, err := decodeOrdered(data)
+	if err != nil {
+		return err
+	}
+	switch mode {
+	case outputYAML:
+		return writeYAML(w, value)
+	case outputTable, outputPretty:
+		return writeTable(w, value, terminalWidth())
+	}
+	return models.Errorf(models.CodeInvalidArgument, "invalid output %q. Must be: json, ndjson, yaml, table, pretty", mode)
+}
+
+// writeNDJSON prints each element of a JSON array on its own line, or any
+// other value as a single line
+func writeNDJSON(w io.Writer, data []byte) error {
+	var items []json.RawMessage
+	if len(data) == 0 || data[0] != '[' || json.Unmarshal(data, &items) != nil {
+		_, err := fmt.Fprintln(w, string(data))
+		return err
+	}
+	for _, item := range items {
+		if _, err := fmt.Fprintln(w, string(item)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orderedObject is a JSON object that remembers its key order
+type orderedObject struct {
+	keys   []string
+	values map[string]any
+}
+
+// decodeOrdered decodes JSON keeping object key order. Objects become
+// *orderedObject, arrays []any, and numbers json.Number.
+func decodeOrdered(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeOrderedValue(dec)
+}
+
+func decodeOrderedValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := &orderedObject{values: make(map[string]any)}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyTok.(string)
+			value, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := obj.values[key]; !dup {
+				obj.keys = append(obj.keys, key)
+			}
+			obj.values[key] = value
+		}
+		_, err = dec.Token()
+		return obj, err
+	case json.Delim('['):
+		list := []any{}
+		for dec.More() {
+			value, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err = dec.Token()
+		return list, err
+	}
+	return tok, nil
+}
+
+func writeYAML(w io.Writer, value any) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(yamlNode(value)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// yamlNode converts a decodeOrdered value to a YAML node, keeping key order
+func yamlNode(value any) *yaml.Node {
+	switch v := value.(type) {
+	case *orderedObject:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, key := range v.keys {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, yamlNode(v.values[key]))
+		}
+		return node
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range v {
+			node.Content = append(node.Content, yamlNode(item))
+		}
+		return node
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(v)}
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fmt.Sprint(value)}
+}
+
+// writeTable prints a list of objects as rows with aligned columns, and a
+// single object as field/value rows. Columns are narrowed to fit width when
+// it is positive.
+func writeTable(w io.Writer, value any, width int) error {
+	var header []string
+	var rows [][]string
+
+	switch v := value.(type) {
+	case []any:
+		if len(v) == 0 {
+			_, err := fmt.Fprintln(w, "No results.")
+			return err
+		}
+		columns := tableColumns(v)
+		if len(columns) == 0 {
+			header = []string{"VALUE"}
+			for _, item := range v {
+				rows = append(rows, []string{tableCell(item)})
+			}
+			break
+		}
+		for _, c := range columns {
+			header = append(header, strings.ToUpper(c))
+		}
+		for _, item := range v {
+			obj, _ := item.(*orderedObject)
+			row := make([]string, len(columns))
+			for i, c := range columns {
+				if obj != nil {
+					row[i] = tableCell(obj.values[c])
+				}
+			}
+			rows = append(rows, row)
+		}
+	case *orderedObject:
+		header = []string{"FIELD", "VALUE"}
+		for _, key := range v.keys {
+			rows = append(rows, []string{key, tableCell(v.values[key])})
+		}
+	default:
+		_, err := fmt.Fprintln(w, tableCell(value))
+		return err
+	}
+
+	widths := fitColumns(header, rows, width)
+	for _, row := range append([][]string{header}, rows...) {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cell = truncateCell(cell, widths[i])
+			if i < len(row)-1 {
+				cell += strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			}
+			cells[i] = cell
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, "  "), " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tableColumns returns the keys of a list of objects in order of first
+// appearance. Wide task lists show only tableTaskColumns.
+func tableColumns(items []any) []string {
+	var columns []string
+	for _, item := range items {
+		obj, ok := item.(*orderedObject)
+		if !ok {
+			continue
+		}
+		for _, key := range obj.keys {
+			if !slices.Contains(columns, key) {
+				columns = append(columns, key)
+			}
+		}
+	}
+	if len(columns) > tableMaxColumns && slices.Contains(columns, "id") && slices.Contains(columns, "name") {
+		var taskColumns []string
+		for _, c := range tableTaskColumns {
+			if slices.Contains(columns, c) {
+				taskColumns = append(taskColumns, c)
+			}
+		}
+		return taskColumns
+	}
+	return columns
+}
+
+// tableCell renders a value on one line: nested tasks by ID, lists of
+// scalars or tasks joined with commas, other nested values as compact JSON
+func tableCell(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return oneLine(v)
+	case *orderedObject:
+		if id, ok := v.values["id"].(string); ok {
+			return id
+		}
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if _, nested := item.([]any); nested {
+				return compactJSON(value)
+			}
+			if obj, ok := item.(*orderedObject); ok {
+				if _, hasID := obj.values["id"].(string); !hasID {
+					return compactJSON(value)
+				}
+			}
+			parts = append(parts, tableCell(item))
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+	return compactJSON(value)
+}
+
+func compactJSON(value any) string {
+	var b strings.Builder
+	writeCompact(&b, value)
+	return b.String()
+}
+
+func writeCompact(b *strings.Builder, value any) {
+	switch v := value.(type) {
+	case *orderedObject:
+		b.WriteByte('{')
+		for i, key := range v.keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			k, _ := json.Marshal(key)
+			b.Write(k)
+			b.WriteByte(':')
+			writeCompact(b, v.values[key])
+		}
+		b.WriteByte('}')
+	case []any:
+		b.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeCompact(b, item)
+		}
+		b.WriteByte(']')
+	default:
+		out, _ := json.Marshal(v)
+		b.Write(out)
+	}
+}
+
+// fitColumns returns each column's width, shrinking the widest columns until
+// the row fits in width. Columns never shrink below their header.
+func fitColumns(header []string, rows [][]string, width int) []int {
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	if width <= 0 {
+		return widths
+	}
+
+	total := func() int {
+		sum := 2 * (len(widths) - 1)
+		for _, w := range widths {
+			sum += w
+		}
+		return sum
+	}
+	for total() > width {
+		widest := 0
+		for i := range widths {
+			if widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= max(utf8.RuneCountInString(header[widest]), 4) {
+			break
+		}
+		widths[widest]--
+	}
+	return widths
+}
+
+// truncateCell shortens s to width runes, marking the cut with an ellipsis
+func truncateCell(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+// terminalWidth is the width of the terminal on stdout, or 0 when stdout is
+// not a terminal
+func terminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
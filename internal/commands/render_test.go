@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type renderItem struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags,omitempty"`
+	Count int      `json:"count"`
+}
+
+func renderItems() []renderItem {
+	return []renderItem{
+		{ID: "aaaa", Name: "First", Tags: []string{"x", "true"}, Count: 2},
+		{ID: "bbbb", Name: "Second", Count: 0},
+	}
+}
+
+func renderString(t *testing.T, mode string, v any) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, render(&buf, mode, v, nil))
+	return buf.String()
+}
+
+func TestOutputMode(t *testing.T) {
+	defer func() { outputFlag = "" }()
+
+	assert.Equal(t, outputJSON, outputMode(false))
+	assert.Equal(t, outputPretty, outputMode(true))
+
+	// An explicit --output wins over a command's --pretty default
+	outputFlag = outputJSON
+	assert.Equal(t, outputJSON, outputMode(true))
+
+	outputFlag = "xml"
+	assert.ErrorContains(t, validateOutputFlag(), `invalid output "xml"`)
+	outputFlag = outputYAML
+	assert.NoError(t, validateOutputFlag())
+}
+
+func TestRender_JSONAndNDJSON(t *testing.T) {
+	assert.Equal(t, `[{"id":"aaaa","name":"First","tags":["x","true"],"count":2},{"id":"bbbb","name":"Second","count":0}]`+"\n",
+		renderString(t, outputJSON, renderItems()))
+
+	assert.Equal(t, `{"id":"aaaa","name":"First","tags":["x","true"],"count":2}`+"\n"+`{"id":"bbbb","name":"Second","count":0}`+"\n",
+		renderString(t, outputNDJSON, renderItems()))
+
+	// Single values are one line; empty lists print nothing
+	assert.Equal(t, `{"id":"aaaa","name":"First","tags":["x","true"],"count":2}`+"\n", renderString(t, outputNDJSON, renderItems()[0]))
+	assert.Equal(t, "", renderString(t, outputNDJSON, []renderItem{}))
+}
+
+func TestRender_YAML(t *testing.T) {
+	out := renderString(t, outputYAML, map[string]any{
+		"items": renderItems(),
+		"none":  nil,
+		"ratio": 0.5,
+	})
+	// Struct key order is kept, numbers stay numbers and strings that look
+	// like other types stay strings
+	assert.Equal(t, `items:
+  - id: aaaa
+    name: First
+    tags:
+      - x
+      - "true"
+    count: 2
+  - id: bbbb
+    name: Second
+    count: 0
+none: null
+ratio: 0.5
+`, out)
+}
+
+func TestRender_Table(t *testing.T) {
+	assert.Equal(t, `ID    NAME    TAGS     COUNT
+aaaa  First   x, true  2
+bbbb  Second           0
+`, renderString(t, outputTable, renderItems()))
+
+	// A single object is shown as field/value rows
+	assert.Equal(t, `FIELD  VALUE
+id     aaaa
+name   First
+tags   x, true
+count  2
+`, renderString(t, outputTable, renderItems()[0]))
+
+	assert.Equal(t, "No results.\n", renderString(t, outputTable, []renderItem{}))
+
+	// Pretty output without a command-specific view falls back to a table
+	assert.Equal(t, renderString(t, outputTable, renderItems()), renderString(t, outputPretty, renderItems()))
+}
+
+func TestRender_PrettyUsesCommandView(t *testing.T) {
+	var buf bytes.Buffer
+	called := false
+	require.NoError(t, render(&buf, outputPretty, renderItems(), func() { called = true }))
+	assert.True(t, called)
+	assert.Empty(t, buf.String())
+}
+
+func TestWriteTable_TaskColumns(t *testing.T) {
+	tasks := outputTasks()
+	var buf bytes.Buffer
+	require.NoError(t, render(&buf, outputTable, tasks, nil))
+	// Full tasks have too many fields for a table, so the summary columns are used
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"ID", "STATUS", "OWNER", "PARENT", "NAME"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"aaaa", "todo", "agent-1", "First"}, strings.Fields(lines[1]))
+}
+
+func TestWriteTable_FitsWidth(t *testing.T) {
+	value, err := decodeOrdered([]byte(`[{"id":"aaaa","name":"A rather long task name that will not fit"}]`))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTable(&buf, value, 24))
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	assert.Equal(t, "ID    NAME", lines[0])
+	assert.Equal(t, "aaaa  A rather long tas…", lines[1])
+	for _, line := range lines {
+		assert.LessOrEqual(t, len([]rune(line)), 24)
+	}
+}
+
+func TestTaskOutput_Encodings(t *testing.T) {
+	defer func() { outputFlag = "" }()
+
+	out := taskOutput{fields: "id,owner"}
+	require.NoError(t, out.prepare(outputNDJSON))
+	var buf bytes.Buffer
+	require.NoError(t, out.writeTasks(&buf, outputTasks()))
+	assert.Equal(t, `{"id":"aaaa","owner":"agent-1"}`+"\n"+`{"id":"bbbb","owner":null}`+"\n", buf.String())
+
+	out = taskOutput{fields: "id,owner"}
+	require.NoError(t, out.prepare(outputYAML))
+	buf.Reset()
+	require.NoError(t, out.writeTasks(&buf, outputTasks()))
+	assert.Equal(t, "- id: aaaa\n  owner: agent-1\n- id: bbbb\n  owner: null\n", buf.String())
+
+	// A nil list is an empty list outside JSON
+	out = taskOutput{}
+	require.NoError(t, out.prepare(outputYAML))
+	buf.Reset()
+	require.NoError(t, out.writeTasks(&buf, nil))
+	assert.Equal(t, "[]\n", buf.String())
+
+	outputFlag = outputYAML
+	out = taskOutput{format: "{{.ID}}"}
+	assert.ErrorContains(t, out.prepare(outputYAML), "--format cannot be combined with --output")
+}
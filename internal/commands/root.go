@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -13,22 +12,33 @@ var rootCmd = &cobra.Command{
 	Short:   "CLI Project Manager - A lightweight JSON-based task queue for LLMs",
 	Long: `clipm is a CLI-based task manager designed for use by LLMs and agents.
 It uses a single JSON file for storage and outputs JSON by default for easy parsing.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		commandStarted = true
+		return validateOutputFlag()
+	},
+	// Execute reports errors itself, as JSON when output is JSON
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
-// Execute runs the root command
+// Execute runs the root command, exiting with the status for the error code
+// of any failure
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil {
+		os.Exit(reportError(os.Stderr, cmd, err))
 	}
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "", "Output encoding: json, ndjson, yaml, table or pretty (default json, or pretty with --pretty)")
+
 	// Add subcommands
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(addCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(historyCmd)
 	rootCmd.AddCommand(statusCmd)
 	rootCmd.AddCommand(deleteCmd)
 	rootCmd.AddCommand(parentCmd)
@@ -39,7 +49,26 @@ func init() {
 	rootCmd.AddCommand(watchCmd)
 	rootCmd.AddCommand(blockCmd)
 	rootCmd.AddCommand(unblockCmd)
+	rootCmd.AddCommand(linkCmd)
+	rootCmd.AddCommand(unlinkCmd)
 	rootCmd.AddCommand(noteCmd)
 	rootCmd.AddCommand(claimCmd)
 	rootCmd.AddCommand(unclaimCmd)
+	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(templateCmd)
+	rootCmd.AddCommand(recurCmd)
+	rootCmd.AddCommand(batchCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(graphCmd)
+	rootCmd.AddCommand(analyzeCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(moveCmd)
+	rootCmd.AddCommand(cancelCmd)
+	rootCmd.AddCommand(splitCmd)
+	rootCmd.AddCommand(mergeCmd)
+	rootCmd.AddCommand(cloneCmd)
 }
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchPretty bool
+	searchStatus string
+	searchLimit  int
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search task text and notes",
+	Long: `Search task names, descriptions, structured fields (action, verify, result),
+outcomes and notes. Every term must match; results are ranked by where and how
+often the terms occur, with names counting most.
+
+Query syntax:
+  retry bug            both words, anywhere
+  "retry bug"          the exact phrase
+  notes:retry          a term limited to one field: name, description (desc),
+                       action, verify, result, outcome, notes (note)
+  outcome:"root cause" a phrase limited to one field
+
+Matching ignores case. Done tasks are searched too.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	searchCmd.Flags().BoolVar(&searchPretty, "pretty", false, "Pretty print output")
+	searchCmd.Flags().StringVarP(&searchStatus, "status", "s", "", "Only search tasks with this status (todo|in-progress|done|cancelled)")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 20, "Maximum results to return (0 for all)")
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	if searchStatus != "" && !models.IsValidStatus(searchStatus) {
+		return models.Errorf(models.CodeInvalidArgument, "invalid status %q. Must be: todo, in-progress, done, cancelled", searchStatus)
+	}
+	if searchLimit < 0 {
+		return models.Errorf(models.CodeInvalidArgument, "--limit cannot be negative")
+	}
+
+	terms, err := models.ParseSearchQuery(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	tasks, err := store.LoadAll()
+	if err != nil {
+		return err
+	}
+	if searchStatus != "" {
+		tasks = filterTasksByStatus(tasks, searchStatus)
+	}
+
+	results := models.Search(tasks, terms)
+	if searchLimit > 0 && len(results) > searchLimit {
+		results = results[:searchLimit]
+	}
+
+	return render(os.Stdout, outputMode(searchPretty), results, func() {
+		printSearchResults(results, terms)
+	})
+}
+
+func printSearchResults(results []models.SearchResult, terms []models.SearchTerm) {
+	if len(results) == 0 {
+		fmt.Println("No matching tasks.")
+		return
+	}
+
+	gray := color.New(color.FgHiBlack)
+	for i, result := range results {
+		if i > 0 {
+			fmt.Println()
+		}
+		task := result.Task
+		fmt.Printf("%s  %s ", task.ID, highlightMatches(task.Name, models.SearchFieldName, terms))
+		getStatusColor(task.Status).Printf("[%s]", task.Status)
+		gray.Printf(" score %d\n", result.Score)
+
+		for _, match := range result.Matches {
+			if match.Field == models.SearchFieldName {
+				continue
+			}
+			label := match.Field
+			if match.Field == models.SearchFieldNotes {
+				label = fmt.Sprintf("note #%d", match.NoteID)
+			}
+			gray.Printf("  %s: ", label)
+			fmt.Println(highlightMatches(match.Snippet, match.Field, terms))
+		}
+	}
+}
+
+// highlightMatches returns text from the given field with every occurrence of
+// a search term for that field in bold yellow
+func highlightMatches(s, field string, terms []models.SearchTerm) string {
+	hl := color.New(color.FgYellow, color.Bold)
+	var b strings.Builder
+	last := 0
+	for _, span := range models.SearchHighlights(s, field, terms) {
+		b.WriteString(s[last:span[0]])
+		b.WriteString(hl.Sprint(s[span[0]:span[1]]))
+		last = span[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
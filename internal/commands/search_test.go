@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetSearchFlags() {
+	searchPretty = false
+	searchStatus = ""
+	searchLimit = 20
+}
+
+func TestSearchCommand(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetSearchFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	createStructuredTask(t, store)
+
+	resetSearchFlags()
+	require.NoError(t, runSearch(nil, []string{"original"}))
+	searchPretty = true
+	require.NoError(t, runSearch(nil, []string{"description:old", `"do it"`}))
+	require.NoError(t, runSearch(nil, []string{"missing"}))
+
+	searchStatus = models.StatusDone
+	require.NoError(t, runSearch(nil, []string{"original"}))
+}
+
+func TestSearchCommand_Errors(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetSearchFlags()
+
+	resetSearchFlags()
+	searchStatus = "bogus"
+	assert.ErrorContains(t, runSearch(nil, []string{"x"}), "invalid status")
+
+	resetSearchFlags()
+	searchLimit = -1
+	assert.ErrorContains(t, runSearch(nil, []string{"x"}), "cannot be negative")
+
+	resetSearchFlags()
+	assert.ErrorContains(t, runSearch(nil, []string{`"open`}), "unterminated phrase")
+}
+
+func TestHighlightMatches(t *testing.T) {
+	terms, err := models.ParseSearchQuery("retry")
+	require.NoError(t, err)
+	assert.Contains(t, highlightMatches("Fix retry bug", models.SearchFieldName, terms), "retry")
+	assert.Equal(t, "no match", highlightMatches("no match", models.SearchFieldName, terms))
+}
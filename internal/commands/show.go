@@ -1,8 +1,8 @@
 package commands
 
 import (
-	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/fatih/color"
@@ -11,7 +11,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var showPretty bool
+var (
+	showPretty bool
+	showOutput taskOutput
+)
 
 var showCmd = &cobra.Command{
 	Use:   "show <id>",
@@ -23,6 +26,7 @@ var showCmd = &cobra.Command{
 
 func init() {
 	showCmd.Flags().BoolVar(&showPretty, "pretty", false, "Pretty print output")
+	addTaskOutputFlags(showCmd, &showOutput)
 }
 
 type blockerInfo struct {
@@ -31,17 +35,30 @@ type blockerInfo struct {
 	Status string `json:"status"`
 }
 
+// linkInfo is a link resolved to the other task, named from this task's point of view
+type linkInfo struct {
+	Type   string `json:"type"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
 type showResult struct {
 	*models.Task
-	Blockers []blockerInfo `json:"blockers,omitempty"`
-	Blocks   []blockerInfo `json:"blocks,omitempty"`
+	Blockers   []blockerInfo    `json:"blockers,omitempty"`
+	Blocks     []blockerInfo    `json:"blocks,omitempty"`
+	LinkedFrom []linkInfo       `json:"linkedFrom,omitempty"`
+	Progress   *models.Progress `json:"progress,omitempty"`
 }
 
 func runShow(cmd *cobra.Command, args []string) error {
 	// Normalize and validate task ID
 	id := models.NormalizeTaskID(args[0])
 	if !models.IsValidTaskID(id) {
-		return fmt.Errorf("invalid task ID: %s", args[0])
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
+	}
+	if err := showOutput.prepare(outputMode(showPretty), "blockers", "blocks", "linkedFrom", "progress"); err != nil {
+		return err
 	}
 
 	// Load storage
@@ -53,6 +70,9 @@ func runShow(cmd *cobra.Command, args []string) error {
 	// Load task
 	task, err := store.LoadTask(id)
 	if err != nil {
+		if err == storage.ErrTaskNotFound {
+			return models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+		}
 		return err
 	}
 
@@ -85,19 +105,40 @@ func runShow(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if showPretty {
-		printTaskDetails(task, blockers, blocks)
-	} else {
-		result := showResult{
-			Task:     task,
-			Blockers: blockers,
-			Blocks:   blocks,
+	// Resolve outgoing links, and find links from other tasks (named by their inverse)
+	var links, linkedFrom []linkInfo
+	for _, l := range task.Links {
+		if info := findBlockerInfo(allTasks, l.Target); info != nil {
+			links = append(links, linkInfo{Type: l.Type, ID: info.ID, Name: info.Name, Status: info.Status})
+		}
+	}
+	for i := range allTasks {
+		for _, l := range allTasks[i].Links {
+			if l.Target == id {
+				linkedFrom = append(linkedFrom, linkInfo{
+					Type:   models.InverseLinkType(l.Type),
+					ID:     allTasks[i].ID,
+					Name:   allTasks[i].Name,
+					Status: allTasks[i].Status,
+				})
+			}
 		}
-		out, _ := json.Marshal(result)
-		fmt.Println(string(out))
 	}
 
-	return nil
+	progress := models.ComputeProgress(allTasks)[id]
+
+	if showOutput.mode == outputPretty {
+		printTaskDetails(task, blockers, blocks, append(links, linkedFrom...), progress)
+		return nil
+	}
+	result := showResult{
+		Task:       task,
+		Blockers:   blockers,
+		Blocks:     blocks,
+		LinkedFrom: linkedFrom,
+		Progress:   progress,
+	}
+	return showOutput.writeValue(os.Stdout, &result)
 }
 
 func findBlockerInfo(tasks []models.Task, id string) *blockerInfo {
@@ -113,7 +154,7 @@ func findBlockerInfo(tasks []models.Task, id string) *blockerInfo {
 	return nil
 }
 
-func printTaskDetails(task *models.Task, blockers, blocks []blockerInfo) {
+func printTaskDetails(task *models.Task, blockers, blocks []blockerInfo, links []linkInfo, progress *models.Progress) {
 	cyan := color.New(color.FgCyan, color.Bold)
 	white := color.New(color.FgWhite)
 	gray := color.New(color.FgHiBlack)
@@ -140,12 +181,25 @@ func printTaskDetails(task *models.Task, blockers, blocks []blockerInfo) {
 	if task.Result != "" {
 		white.Printf("Result:      %s\n", task.Result)
 	}
+	if task.VerifyCmd != "" {
+		white.Printf("Verify cmd:  %s\n", task.VerifyCmd)
+	}
+	if latest := task.LatestVerification(); latest != nil {
+		if latest.Passed {
+			color.New(color.FgGreen).Printf("Verified:    passed (%s)\n", latest.Timestamp.Format("2006-01-02 15:04:05"))
+		} else {
+			color.New(color.FgRed).Printf("Verified:    failed, exit code %d (%s)\n", latest.ExitCode, latest.Timestamp.Format("2006-01-02 15:04:05"))
+		}
+	}
 	if task.Outcome != "" {
 		green := color.New(color.FgGreen)
 		green.Printf("Outcome:     %s\n", task.Outcome)
 	}
 
 	white.Printf("Status:      %s\n", task.Status)
+	if progress != nil {
+		white.Printf("Progress:    %s\n", formatProgress(progress))
+	}
 
 	if task.Parent != nil {
 		white.Printf("Parent:      %s\n", *task.Parent)
@@ -157,6 +211,38 @@ func printTaskDetails(task *models.Task, blockers, blocks []blockerInfo) {
 		white.Printf("Owner:       %s\n", *task.Owner)
 	}
 
+	if r := task.Recurrence; r != nil {
+		switch {
+		case r.Spawned != "":
+			white.Printf("Recurs:      %s (next occurrence: %s)\n", r.Spec, r.Spawned)
+		case r.Next != nil:
+			white.Printf("Recurs:      %s (next due %s)\n", r.Spec, r.Next.Local().Format("2006-01-02 15:04"))
+		default:
+			white.Printf("Recurs:      %s\n", r.Spec)
+		}
+	}
+
+	if len(task.Fields) > 0 {
+		fmt.Println()
+		yellow.Println("Fields:")
+		for _, name := range sortedFieldNames(task.Fields) {
+			white.Printf("  %s: %s\n", name, models.FormatFieldValue(task.Fields[name]))
+		}
+	}
+
+	if len(task.Checklist) > 0 {
+		done, total := task.ChecklistProgress()
+		fmt.Println()
+		yellow.Printf("Checklist (%d/%d):\n", done, total)
+		for i, item := range task.Checklist {
+			mark := " "
+			if item.Done {
+				mark = "x"
+			}
+			white.Printf("  %d. [%s] %s\n", i+1, mark, item.Text)
+		}
+	}
+
 	if len(blockers) > 0 {
 		fmt.Println()
 		yellow.Println("Blocked by:")
@@ -173,15 +259,28 @@ func printTaskDetails(task *models.Task, blockers, blocks []blockerInfo) {
 		}
 	}
 
+	if len(links) > 0 {
+		fmt.Println()
+		yellow.Println("Links:")
+		for _, l := range links {
+			white.Printf("  %s %s - %s (%s)\n", l.Type, l.ID, l.Name, l.Status)
+		}
+	}
+
 	gray.Printf("Created:     %s\n", task.Created.Format("2006-01-02 15:04:05"))
 	gray.Printf("Updated:     %s\n", task.Updated.Format("2006-01-02 15:04:05"))
 
 	if len(task.Notes) > 0 {
 		fmt.Println()
 		yellow.Println("Notes:")
-		for _, note := range task.Notes {
-			gray.Printf("  [%s] ", note.Timestamp.Format("2006-01-02 15:04"))
-			white.Printf("%s\n", note.Content)
+		for i := range task.Notes {
+			printNote(&task.Notes[i])
 		}
 	}
+
+	if len(task.History) > 0 {
+		fmt.Println()
+		yellow.Println("History:")
+		printHistory(task)
+	}
 }
@@ -0,0 +1,255 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	splitPretty      bool
+	splitInto        []string
+	splitSiblings    bool
+	splitBlockers    []string
+	splitDependents  []string
+	splitNotes       []string
+	splitInteractive bool
+)
+
+// splitInput and splitPrompts are where --interactive reads answers and
+// writes questions (replaced in tests)
+var (
+	splitInput   io.Reader = os.Stdin
+	splitPrompts io.Writer = os.Stderr
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split <id> --into <name> <name>...",
+	Short: "Split a task into several tasks",
+	Long: `Split a task into two or more new tasks, named by --into. Names may follow a
+single --into or each have their own.
+
+By default the parts become children of the task, which stays open until they
+are done. With --siblings the parts take the task's place under its parent and
+the task is cancelled.
+
+Parts copy the task's custom fields. Each blocker of the task is inherited by
+every part unless --blocker assigns it to one. Tasks waiting on the task keep
+waiting on it, or on every part when it is cancelled, unless --dependent
+assigns them to one. Notes stay on the task unless --note moves them. Parts
+are numbered from 1 in the order they are named; "all" assigns a blocker or
+dependent to every part. --interactive asks about everything not assigned by
+flags.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSplit,
+}
+
+func init() {
+	splitCmd.Flags().BoolVar(&splitPretty, "pretty", false, "Pretty print output")
+	splitCmd.Flags().StringArrayVar(&splitInto, "into", nil, "Name of a new task (repeatable)")
+	splitCmd.Flags().BoolVar(&splitSiblings, "siblings", false, "Create the parts next to the task and cancel it")
+	splitCmd.Flags().StringArrayVar(&splitBlockers, "blocker", nil, "Give a blocker of the task to one part: <blocker-id>=<part> (repeatable)")
+	splitCmd.Flags().StringArrayVar(&splitDependents, "dependent", nil, "Make a task waiting on the task wait on one part: <task-id>=<part> (repeatable)")
+	splitCmd.Flags().StringArrayVar(&splitNotes, "note", nil, "Move a note to one part: <note-id>=<part> (repeatable)")
+	splitCmd.Flags().BoolVarP(&splitInteractive, "interactive", "i", false, "Ask where each blocker, dependent and note goes")
+}
+
+func runSplit(cmd *cobra.Command, args []string) error {
+	id := models.NormalizeTaskID(args[0])
+	if !models.IsValidTaskID(id) {
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
+	}
+	if len(splitInto) == 0 {
+		return models.Errorf(models.CodeInvalidArgument, "--into is required")
+	}
+	opts := storage.SplitOptions{
+		Names:      append(append([]string(nil), splitInto...), args[1:]...),
+		Siblings:   splitSiblings,
+		Blockers:   make(map[string]int),
+		Dependents: make(map[string]int),
+		Notes:      make(map[int]int),
+	}
+
+	for _, value := range splitBlockers {
+		ref, part, err := parseSplitAssignment("--blocker", value, len(opts.Names), true)
+		if err != nil {
+			return err
+		}
+		opts.Blockers[models.NormalizeTaskID(ref)] = part
+	}
+	for _, value := range splitDependents {
+		ref, part, err := parseSplitAssignment("--dependent", value, len(opts.Names), true)
+		if err != nil {
+			return err
+		}
+		opts.Dependents[models.NormalizeTaskID(ref)] = part
+	}
+	for _, value := range splitNotes {
+		ref, part, err := parseSplitAssignment("--note", value, len(opts.Names), false)
+		if err != nil {
+			return err
+		}
+		noteID, err := strconv.Atoi(ref)
+		if err != nil {
+			return models.Errorf(models.CodeInvalidArgument, "invalid note ID %q in --note", ref)
+		}
+		opts.Notes[noteID] = part
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	if splitInteractive {
+		if err := askSplitAssignments(store, id, &opts); err != nil {
+			return err
+		}
+	}
+
+	result, err := store.SplitTask(id, opts)
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, outputMode(splitPretty), result, func() {
+		green := color.New(color.FgGreen)
+		if result.Cancelled {
+			green.Printf("Replaced task %s with:\n", id)
+		} else {
+			green.Printf("Split task %s into:\n", id)
+		}
+		for _, part := range result.Parts {
+			fmt.Printf("  %s  %s\n", part.ID, part.Name)
+		}
+	})
+}
+
+// parseSplitAssignment parses <ref>=<part>, returning the 0-based part or
+// storage.SplitAll for "all"
+func parseSplitAssignment(flag, value string, parts int, allowAll bool) (string, int, error) {
+	ref, partStr, ok := strings.Cut(value, "=")
+	if !ok || ref == "" {
+		return "", 0, models.Errorf(models.CodeInvalidArgument, "invalid %s %q: expected <id>=<part>", flag, value)
+	}
+	part, err := parseSplitPart(partStr, parts, allowAll)
+	if err != nil {
+		return "", 0, models.Errorf(models.CodeInvalidArgument, "invalid %s %q: %w", flag, value, err)
+	}
+	return ref, part, nil
+}
+
+func parseSplitPart(value string, parts int, allowAll bool) (int, error) {
+	value = strings.TrimSpace(value)
+	if allowAll && value == "all" {
+		return storage.SplitAll, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > parts {
+		if allowAll {
+			return 0, fmt.Errorf("part must be 1-%d or all", parts)
+		}
+		return 0, fmt.Errorf("part must be 1-%d", parts)
+	}
+	return n - 1, nil
+}
+
+// askSplitAssignments asks where each blocker, dependent and note of the task
+// goes, skipping those already assigned. An empty answer keeps the default.
+func askSplitAssignments(store *storage.Storage, id string, opts *storage.SplitOptions) error {
+	tasks, err := store.LoadAll()
+	if err != nil {
+		return err
+	}
+	task := findTask(tasks, id)
+	if task == nil {
+		return models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+	}
+	task.AssignNoteIDs()
+
+	fmt.Fprintf(splitPrompts, "Splitting %s into:\n", id)
+	for i, name := range opts.Names {
+		fmt.Fprintf(splitPrompts, "  %d. %s\n", i+1, name)
+	}
+
+	answers := bufio.NewScanner(splitInput)
+	ask := func(question, fallback string, allowAll bool) (int, bool, error) {
+		for {
+			fmt.Fprintf(splitPrompts, "%s [%s]: ", question, fallback)
+			if !answers.Scan() {
+				return 0, false, models.Errorf(models.CodeInvalidArgument, "no answer for %q", question)
+			}
+			answer := strings.TrimSpace(answers.Text())
+			if answer == "" {
+				return 0, false, nil
+			}
+			part, err := parseSplitPart(answer, len(opts.Names), allowAll)
+			if err == nil {
+				return part, true, nil
+			}
+			fmt.Fprintf(splitPrompts, "  %v\n", err)
+		}
+	}
+
+	for _, blockerID := range task.BlockedBy {
+		if _, done := opts.Blockers[blockerID]; done {
+			continue
+		}
+		question := fmt.Sprintf("Blocker %s%s: which part waits on it (1-%d, all)", blockerID, taskLabel(tasks, blockerID), len(opts.Names))
+		if part, ok, err := ask(question, "all", true); err != nil {
+			return err
+		} else if ok {
+			opts.Blockers[blockerID] = part
+		}
+	}
+
+	fallback := "keep"
+	if opts.Siblings {
+		fallback = "all"
+	}
+	for i := range tasks {
+		dep := &tasks[i]
+		if !slices.Contains(dep.BlockedBy, id) {
+			continue
+		}
+		if _, done := opts.Dependents[dep.ID]; done {
+			continue
+		}
+		question := fmt.Sprintf("Dependent %s%s: which part does it wait on (1-%d, all)", dep.ID, taskLabel(tasks, dep.ID), len(opts.Names))
+		if part, ok, err := ask(question, fallback, true); err != nil {
+			return err
+		} else if ok {
+			opts.Dependents[dep.ID] = part
+		}
+	}
+
+	for _, note := range task.Notes {
+		if _, done := opts.Notes[note.ID]; done {
+			continue
+		}
+		question := fmt.Sprintf("Note %d %q: move to part (1-%d)", note.ID, truncateCell(oneLine(note.Content), 40), len(opts.Names))
+		if part, ok, err := ask(question, "keep", false); err != nil {
+			return err
+		} else if ok {
+			opts.Notes[note.ID] = part
+		}
+	}
+	return nil
+}
+
+// taskLabel returns ` "name"` for a task in tasks, or nothing if it is missing
+func taskLabel(tasks []models.Task, id string) string {
+	if t := findTask(tasks, id); t != nil {
+		return fmt.Sprintf(" %q", t.Name)
+	}
+	return ""
+}
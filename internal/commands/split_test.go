@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetSplitFlags() {
+	splitPretty = false
+	splitInto = nil
+	splitSiblings = false
+	splitBlockers = nil
+	splitDependents = nil
+	splitNotes = nil
+	splitInteractive = false
+}
+
+// saveSplitTasks creates aaaa blocked by bbbb, with cccc waiting on aaaa
+func saveSplitTasks(t *testing.T, store *storage.Storage) {
+	t.Helper()
+	now := time.Now()
+	task := &models.Task{ID: "aaaa", Name: "Big", BlockedBy: []string{"bbbb"}, Status: models.StatusTodo, Created: now, Updated: now}
+	task.AddNote(models.Note{Content: "a note", Timestamp: now})
+	require.NoError(t, store.SaveTask(task))
+	require.NoError(t, store.SaveTask(&models.Task{ID: "bbbb", Name: "Blocker", Status: models.StatusTodo, Created: now, Updated: now}))
+	require.NoError(t, store.SaveTask(&models.Task{ID: "cccc", Name: "Dependent", BlockedBy: []string{"aaaa"}, Status: models.StatusTodo, Created: now, Updated: now}))
+}
+
+func TestSplitCommand(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetSplitFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	saveSplitTasks(t, store)
+
+	// Names may follow a single --into
+	resetSplitFlags()
+	splitInto = []string{"One"}
+	splitBlockers = []string{"BBBB=2"}
+	splitNotes = []string{"1=1"}
+	splitPretty = true
+	require.NoError(t, runSplit(nil, []string{"aaaa", "Two"}))
+
+	children, err := store.GetChildren("aaaa")
+	require.NoError(t, err)
+	require.Len(t, children, 2)
+	models.SortByRank(children)
+	assert.Equal(t, "One", children[0].Name)
+	assert.Empty(t, children[0].BlockedBy)
+	assert.Len(t, children[0].Notes, 1)
+	assert.Equal(t, []string{"bbbb"}, children[1].BlockedBy)
+}
+
+func TestSplitCommand_Interactive(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetSplitFlags()
+	origInput, origPrompts := splitInput, splitPrompts
+	defer func() { splitInput, splitPrompts = origInput, origPrompts }()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	saveSplitTasks(t, store)
+
+	// Blocker to part 1, an invalid answer then part 2 for the dependent,
+	// and the note kept
+	var prompts strings.Builder
+	splitInput = strings.NewReader("1\n3\n2\n\n")
+	splitPrompts = &prompts
+	resetSplitFlags()
+	splitInto = []string{"One", "Two"}
+	splitSiblings = true
+	splitInteractive = true
+	require.NoError(t, runSplit(nil, []string{"aaaa"}))
+	assert.Contains(t, prompts.String(), `Blocker bbbb "Blocker"`)
+	assert.Contains(t, prompts.String(), "part must be 1-2 or all")
+
+	task, err := store.LoadTask("aaaa")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, task.Status)
+	assert.Len(t, task.Notes, 1)
+	dep, err := store.LoadTask("cccc")
+	require.NoError(t, err)
+	require.Len(t, dep.BlockedBy, 1)
+	part, err := store.LoadTask(dep.BlockedBy[0])
+	require.NoError(t, err)
+	assert.Equal(t, "Two", part.Name)
+	assert.Empty(t, part.BlockedBy)
+
+	// Running out of answers is an error
+	saveSplitTasks(t, store)
+	splitInput = strings.NewReader("")
+	splitPrompts = io.Discard
+	assert.ErrorContains(t, runSplit(nil, []string{"aaaa"}), "no answer")
+}
+
+func TestSplitCommand_Errors(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetSplitFlags()
+
+	resetSplitFlags()
+	assert.ErrorContains(t, runSplit(nil, []string{"aaaa"}), "--into is required")
+	assert.ErrorContains(t, runSplit(nil, []string{"x"}), "invalid task ID")
+
+	splitInto = []string{"One", "Two"}
+	splitBlockers = []string{"bbbb"}
+	assert.ErrorContains(t, runSplit(nil, []string{"aaaa"}), "expected <id>=<part>")
+	splitBlockers = []string{"bbbb=3"}
+	assert.ErrorContains(t, runSplit(nil, []string{"aaaa"}), "part must be 1-2 or all")
+	splitBlockers = nil
+	splitNotes = []string{"1=all"}
+	assert.ErrorContains(t, runSplit(nil, []string{"aaaa"}), "part must be 1-2")
+	splitNotes = []string{"x=1"}
+	assert.ErrorContains(t, runSplit(nil, []string{"aaaa"}), "invalid note ID")
+}
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsPretty  bool
+	statsSince   string
+	statsOwner   string
+	statsSubtree string
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Report throughput, lead and cycle time, WIP and per-owner completions",
+	Long: `Report how work has moved over a window of time, from task timestamps and
+status history:
+
+  - tasks created and completed, in total and per day
+  - work in progress at the end of each day
+  - lead time (created to done) and cycle time (first started to done)
+  - reopen rate: done tasks moved back to another status, per completion
+  - completions and cycle time for each owner
+
+--since takes a duration counted back from now (24h, 7d, 2w) or a date
+(2026-03-01). Durations are reported in seconds.`,
+	Args: cobra.NoArgs,
+	RunE: runStats,
+}
+
+func init() {
+	statsCmd.Flags().BoolVar(&statsPretty, "pretty", false, "Pretty print output")
+	statsCmd.Flags().StringVar(&statsSince, "since", "30d", "Start of the report: a duration like 7d or a date like 2026-03-01")
+	statsCmd.Flags().StringVar(&statsOwner, "owner", "", "Only report on tasks this owner has held")
+	statsCmd.Flags().StringVar(&statsSubtree, "subtree", "", "Only report on this task and its descendants")
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	since, ok := models.ParseTimeRef(statsSince, now)
+	if !ok {
+		return models.Errorf(models.CodeInvalidArgument, "invalid --since %q: use a duration like 7d or a date like 2026-03-01", statsSince)
+	}
+	if since.After(now) {
+		return models.Errorf(models.CodeInvalidArgument, "--since %s is in the future", statsSince)
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	tasks, err := store.LoadAll()
+	if err != nil {
+		return err
+	}
+
+	if statsSubtree != "" {
+		id := models.NormalizeTaskID(statsSubtree)
+		if !models.IsValidTaskID(id) {
+			return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", statsSubtree).With("id", statsSubtree)
+		}
+		if findTask(tasks, id) == nil {
+			return models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+		}
+		tasks = subtreeTasks(tasks, id)
+	}
+	if statsOwner != "" {
+		tasks = filterHeldBy(tasks, statsOwner)
+	}
+
+	stats := models.ComputeStats(tasks, since, now)
+	return render(os.Stdout, outputMode(statsPretty), stats, func() {
+		printStats(stats)
+	})
+}
+
+// filterHeldBy returns the tasks owned by owner now or at any point in their history
+func filterHeldBy(tasks []models.Task, owner string) []models.Task {
+	var held []models.Task
+	for i := range tasks {
+		task := &tasks[i]
+		owners := []string{}
+		if task.Owner != nil {
+			owners = append(owners, *task.Owner)
+		}
+		for _, h := range task.History {
+			if h.Field == models.HistoryOwner {
+				owners = append(owners, h.From, h.To)
+			}
+		}
+		if slices.Contains(owners, owner) {
+			held = append(held, *task)
+		}
+	}
+	return held
+}
+
+func printStats(stats *models.Stats) {
+	bold := color.New(color.Bold)
+	yellow := color.New(color.FgYellow)
+	gray := color.New(color.FgHiBlack)
+
+	bold.Printf("Stats from %s to %s\n\n", stats.Since.Local().Format("2006-01-02 15:04"), stats.Until.Local().Format("2006-01-02 15:04"))
+	fmt.Printf("Created:    %d\n", stats.Created)
+	fmt.Printf("Completed:  %d\n", stats.Completed)
+	fmt.Printf("Reopened:   %d (%.0f%% of completions)\n", stats.Reopened, stats.ReopenRate*100)
+
+	fmt.Println()
+	yellow.Println("Lead time (created to done):")
+	printDistribution(stats.LeadTime)
+	yellow.Println("Cycle time (started to done):")
+	printDistribution(stats.CycleTime)
+
+	fmt.Println()
+	yellow.Println("Per day:")
+	gray.Printf("  %-10s  %7s  %9s  %3s\n", "DATE", "CREATED", "COMPLETED", "WIP")
+	for _, day := range stats.Days {
+		fmt.Printf("  %-10s  %7d  %9d  %3d", day.Date, day.Created, day.Completed, day.WIP)
+		if day.Completed > 0 {
+			color.New(color.FgGreen).Print("  " + strings.Repeat("#", day.Completed))
+		}
+		fmt.Println()
+	}
+
+	fmt.Println()
+	yellow.Println("Owners:")
+	if len(stats.Owners) == 0 {
+		gray.Println("  No owned tasks completed")
+	}
+	for _, o := range stats.Owners {
+		fmt.Printf("  %s: %d completed", o.Owner, o.Completed)
+		if o.CycleTime.Count > 0 {
+			gray.Printf(", median cycle time %s", formatDuration(time.Duration(o.CycleTime.Median)*time.Second))
+		}
+		fmt.Println()
+	}
+}
+
+func printDistribution(d models.Distribution) {
+	gray := color.New(color.FgHiBlack)
+	if d.Count == 0 {
+		gray.Println("  No completions")
+		return
+	}
+	seconds := func(s int64) string {
+		return formatDuration(time.Duration(s) * time.Second)
+	}
+	fmt.Printf("  median %s, p90 %s, mean %s", seconds(d.Median), seconds(d.P90), seconds(d.Mean))
+	gray.Printf(" (min %s, max %s, %d task(s))\n", seconds(d.Min), seconds(d.Max), d.Count)
+}
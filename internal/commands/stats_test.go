@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetStatsFlags() {
+	statsPretty = false
+	statsSince = "30d"
+	statsOwner = ""
+	statsSubtree = ""
+}
+
+func TestStatsCommand(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetStatsFlags()
+
+	resetStatsFlags()
+	require.NoError(t, runStats(nil, nil))
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createStructuredTask(t, store)
+	owner := "alice"
+	task.SetOwner(&owner, "", time.Now())
+	task.SetStatus(models.StatusInProgress, "", time.Now())
+	task.SetStatus(models.StatusDone, "", time.Now())
+	require.NoError(t, store.SaveTask(task))
+
+	statsPretty = true
+	require.NoError(t, runStats(nil, nil))
+	statsPretty = false
+	statsOwner = "alice"
+	statsSubtree = task.ID
+	statsSince = "2026-01-01"
+	require.NoError(t, runStats(nil, nil))
+
+	statsSubtree = "zzzz"
+	assert.ErrorContains(t, runStats(nil, nil), "task zzzz not found")
+	statsSubtree = ""
+
+	statsSince = "soon"
+	assert.ErrorContains(t, runStats(nil, nil), "invalid --since")
+	statsSince = "2999-01-01"
+	assert.ErrorContains(t, runStats(nil, nil), "in the future")
+}
+
+func TestFilterHeldBy(t *testing.T) {
+	now := time.Now()
+	alice, bob := "alice", "bob"
+	current := models.Task{ID: "aaaa", Owner: &alice}
+	former := models.Task{ID: "bbbb"}
+	former.SetOwner(&alice, "", now)
+	former.SetOwner(&bob, "", now)
+	never := models.Task{ID: "cccc", Owner: &bob}
+
+	held := filterHeldBy([]models.Task{current, former, never}, "alice")
+	require.Len(t, held, 2)
+	assert.Equal(t, "aaaa", held[0].ID)
+	assert.Equal(t, "bbbb", held[1].ID)
+}
@@ -1,8 +1,9 @@
 package commands
 
 import (
-	"encoding/json"
 	"fmt"
+	"os"
+	"slices"
 	"time"
 
 	"github.com/fatih/color"
@@ -13,25 +14,33 @@ import (
 
 var statusPretty bool
 var statusOutcome string
+var statusRecursive bool
+var statusOutput taskOutput
 
 var statusCmd = &cobra.Command{
 	Use:   "status <id> <status>",
 	Short: "Update task status",
-	Long:  `Update the status of a task. Valid statuses: todo, in-progress, done`,
-	Args:  cobra.ExactArgs(2),
-	RunE:  runStatus,
+	Long: `Update the status of a task. Valid statuses: todo, in-progress, done, cancelled
+
+With --recursive the status is applied to the task and all of its descendants
+in one transaction. Closing works from the leaves up and leaves descendants
+that are already closed alone; other statuses work from the top down.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runStatus,
 }
 
 func init() {
 	statusCmd.Flags().BoolVar(&statusPretty, "pretty", false, "Pretty print output")
-	statusCmd.Flags().StringVar(&statusOutcome, "outcome", "", "Actual result when marking done")
+	statusCmd.Flags().StringVar(&statusOutcome, "outcome", "", "Actual result when closing the task")
+	statusCmd.Flags().BoolVar(&statusRecursive, "recursive", false, "Apply the status to the task's descendants too")
+	addTaskOutputFlags(statusCmd, &statusOutput)
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
 	// Normalize and validate task ID
 	id := models.NormalizeTaskID(args[0])
 	if !models.IsValidTaskID(id) {
-		return fmt.Errorf("invalid task ID: %s", args[0])
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
 	}
 
 	// Get new status
@@ -39,7 +48,10 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 	// Validate status
 	if !models.IsValidStatus(newStatus) {
-		return fmt.Errorf("invalid status %q. Must be: todo, in-progress, done", newStatus)
+		return models.Errorf(models.CodeInvalidArgument, "invalid status %q. Must be: todo, in-progress, done, cancelled", newStatus)
+	}
+	if err := statusOutput.prepare(outputMode(statusPretty)); err != nil {
+		return err
 	}
 
 	// Load storage
@@ -52,53 +64,184 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	task, err := store.LoadTask(id)
 	if err != nil {
 		if err == storage.ErrTaskNotFound {
-			return fmt.Errorf("task %s not found", id)
+			return models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+		}
+		return err
+	}
+
+	now := time.Now()
+	if statusRecursive {
+		var updated []models.Task
+		var spawned []*models.Task
+		err = store.Transaction(func(tx *storage.Storage) error {
+			var err error
+			updated, spawned, err = applyStatusRecursive(tx, id, newStatus, statusOutcome, now)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		if statusOutput.mode == outputPretty {
+			green := color.New(color.FgGreen)
+			green.Printf("Updated %d task(s) to %s\n", len(updated), newStatus)
+			for i := range updated {
+				fmt.Printf("  %s  %s\n", updated[i].ID, updated[i].Name)
+			}
+			for _, next := range spawned {
+				green.Printf("Created next occurrence %s: %s\n", next.ID, next.Name)
+			}
+			return nil
 		}
+		return statusOutput.writeTasks(os.Stdout, updated)
+	}
+
+	var spawned *models.Task
+	err = store.Transaction(func(tx *storage.Storage) error {
+		var err error
+		spawned, err = applyStatus(tx, task, newStatus, statusOutcome, now)
+		return err
+	})
+	if err != nil {
 		return err
 	}
 
+	if statusOutput.mode == outputPretty {
+		green := color.New(color.FgGreen)
+		green.Printf("Updated task %s status: %s\n", task.ID, newStatus)
+		if spawned != nil {
+			green.Printf("Created next occurrence %s: %s\n", spawned.ID, spawned.Name)
+		} else if newStatus == models.StatusDone && task.Recurrence.Pending() {
+			fmt.Printf("Next occurrence due %s\n", task.Recurrence.Next.Local().Format("2006-01-02 15:04"))
+		}
+		return nil
+	}
+	return statusOutput.writeValue(os.Stdout, task)
+}
+
+// applyStatus validates and saves a status change. Closing a task records its
+// outcome, if given, and unblocks its dependents; marking it done also spawns
+// the next instance of a due recurring task, which is returned.
+func applyStatus(store *storage.Storage, task *models.Task, newStatus, outcome string, now time.Time) (*models.Task, error) {
 	// Validate transition constraints
 	if err := validateStatusTransition(store, task, newStatus); err != nil {
-		return err
+		return nil, err
 	}
 
-	// Require --outcome for structured tasks being marked done
+	// Require an outcome for structured tasks being marked done
 	if newStatus == models.StatusDone && task.HasStructuredFields() {
-		if statusOutcome == "" {
-			return fmt.Errorf("structured task %s requires --outcome when marking done", task.ID)
+		if outcome == "" {
+			return nil, models.Errorf(models.CodeInvalidArgument, "structured task %s requires --outcome when marking done", task.ID)
 		}
 	}
 
-	// Set outcome when marking done
-	if newStatus == models.StatusDone && statusOutcome != "" {
-		task.Outcome = statusOutcome
+	// Set outcome when closing
+	if models.IsClosed(newStatus) && outcome != "" {
+		task.Outcome = outcome
 	}
 
 	// Update status and timestamp
-	task.Status = newStatus
-	task.Updated = time.Now()
+	task.SetStatus(newStatus, storage.CurrentActor(), now)
+	task.Updated = now
 
-	// Save the task
 	if err := store.SaveTask(task); err != nil {
-		return err
+		return nil, err
 	}
 
-	// Auto-remove from all BlockedBy lists when marked done
-	if newStatus == models.StatusDone {
-		if err := store.RemoveFromAllBlockedBy(id); err != nil {
-			return err
+	if !models.IsClosed(newStatus) {
+		return nil, nil
+	}
+
+	// Auto-remove from all BlockedBy lists when closed
+	if err := store.RemoveFromAllBlockedBy(task.ID); err != nil {
+		return nil, err
+	}
+	if newStatus != models.StatusDone {
+		return nil, nil
+	}
+
+	// Spawn the next instance of a recurring task once it is due;
+	// scheduled occurrences still in the future are left to 'clipm recur run'
+	if task.Recurrence.Due(now) {
+		return store.SpawnRecurrence(task, now)
+	}
+	return nil, nil
+}
+
+// applyStatusRecursive applies a status change to a task and its descendants.
+// Closing works from the leaves up so no task is closed before its children,
+// and skips descendants that are already closed; other statuses work from the
+// top down and skip descendants that already have the status. It returns the
+// updated tasks in tree order and the recurring tasks spawned.
+func applyStatusRecursive(store *storage.Storage, id, newStatus, outcome string, now time.Time) ([]models.Task, []*models.Task, error) {
+	tasks, err := store.LoadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if findTask(tasks, id) == nil {
+		return nil, nil, models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+	}
+
+	closing := models.IsClosed(newStatus)
+	var ids []string
+	walkSubtree(tasks, id, func(task *models.Task, depth int) {
+		if depth == 0 || (closing && !models.IsClosed(task.Status)) || (!closing && task.Status != newStatus) {
+			ids = append(ids, task.ID)
 		}
+	})
+	order := slices.Clone(ids)
+	if closing {
+		slices.Reverse(order)
 	}
 
-	if statusPretty {
-		green := color.New(color.FgGreen)
-		green.Printf("Updated task %s status: %s\n", task.ID, newStatus)
-	} else {
-		out, _ := json.Marshal(task)
-		fmt.Println(string(out))
+	byID := make(map[string]models.Task, len(ids))
+	var spawned []*models.Task
+	for _, taskID := range order {
+		// Reload each task: closing earlier ones may have unblocked it
+		task, err := store.LoadTask(taskID)
+		if err != nil {
+			return nil, nil, err
+		}
+		next, err := applyStatus(store, task, newStatus, outcome, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		byID[taskID] = *task
+		if next != nil {
+			spawned = append(spawned, next)
+		}
 	}
 
-	return nil
+	updated := make([]models.Task, len(ids))
+	for i, taskID := range ids {
+		updated[i] = byID[taskID]
+	}
+	return updated, spawned, nil
+}
+
+// walkSubtree calls fn for the task with the given ID and each of its
+// descendants in tree order, parents before their children and siblings in
+// rank order. depth is 0 for the task itself.
+func walkSubtree(tasks []models.Task, id string, fn func(task *models.Task, depth int)) {
+	root := findTask(tasks, id)
+	if root == nil {
+		return
+	}
+	var walk func(task *models.Task, depth int)
+	walk = func(task *models.Task, depth int) {
+		fn(task, depth)
+		var children []models.Task
+		for i := range tasks {
+			if tasks[i].Parent != nil && *tasks[i].Parent == task.ID {
+				children = append(children, tasks[i])
+			}
+		}
+		models.SortByRank(children)
+		for i := range children {
+			walk(&children[i], depth+1)
+		}
+	}
+	walk(root, 0)
 }
 
 func validateStatusTransition(store *storage.Storage, task *models.Task, newStatus string) error {
@@ -108,17 +251,40 @@ func validateStatusTransition(store *storage.Storage, task *models.Task, newStat
 			return err
 		}
 		if blocked {
-			return fmt.Errorf("cannot start task %s: blocked by %v", task.ID, task.BlockedBy)
+			return models.Errorf(models.CodeBlocked, "cannot start task %s: blocked by %v", task.ID, task.BlockedBy).
+				With("id", task.ID).With("blockedBy", task.BlockedBy)
 		}
 	}
 
-	if newStatus == models.StatusDone {
+	if models.IsClosed(newStatus) {
 		hasUndone, err := store.HasUndoneChildren(task.ID)
 		if err != nil {
 			return err
 		}
 		if hasUndone {
-			return fmt.Errorf("cannot mark task as done: has undone children")
+			return models.Errorf(models.CodeHasUndoneChildren, "cannot mark task as %s: has undone children", newStatus).With("id", task.ID)
+		}
+	}
+
+	if newStatus == models.StatusDone {
+		cfg, err := store.LoadConfig()
+		if err != nil {
+			return err
+		}
+		if cfg.RequireVerification && task.VerifyCmd != "" {
+			latest := task.LatestVerification()
+			if latest == nil || !latest.Passed {
+				return models.Errorf(models.CodeVerificationFailed, "cannot mark task %s as done: latest verification has not passed (run 'clipm verify %s')", task.ID, task.ID).With("id", task.ID)
+			}
+			// A pass only counts for the command the task has now
+			if latest.Command != task.VerifyCmd {
+				return models.Errorf(models.CodeVerificationFailed, "cannot mark task %s as done: latest verification ran a different command (run 'clipm verify %s')", task.ID, task.ID).With("id", task.ID)
+			}
+		}
+		if cfg.RequireChecklist {
+			if done, total := task.ChecklistProgress(); done < total {
+				return models.Errorf(models.CodeConflict, "cannot mark task %s as done: %d of %d checklist items unticked", task.ID, total-done, total)
+			}
 		}
 	}
 
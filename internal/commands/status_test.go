@@ -391,3 +391,87 @@ func TestStatusCommand_LegacyTaskDoneWithoutOutcome(t *testing.T) {
 	assert.Equal(t, models.StatusDone, updated.Status)
 	assert.Empty(t, updated.Outcome)
 }
+
+func TestStatusCommand_Recursive(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { statusRecursive = false }()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	now := time.Now()
+	root, child := "aaaa", "bbbb"
+	for _, task := range []*models.Task{
+		{ID: "aaaa", Name: "Root", Status: models.StatusTodo},
+		{ID: "bbbb", Name: "Child", Parent: &root, Status: models.StatusInProgress},
+		{ID: "cccc", Name: "Cancelled child", Parent: &root, Status: models.StatusCancelled},
+		{ID: "dddd", Name: "Grandchild", Parent: &child, Status: models.StatusTodo},
+		{ID: "eeee", Name: "Sibling", Parent: &child, BlockedBy: []string{"dddd"}, Status: models.StatusTodo},
+	} {
+		task.Created, task.Updated = now, now
+		require.NoError(t, store.SaveTask(task))
+	}
+
+	statusPretty = false
+	statusOutcome = ""
+	statusRecursive = true
+
+	// Closing works from the leaves up and leaves closed descendants alone
+	require.NoError(t, runStatus(nil, []string{"aaaa", models.StatusDone}))
+	for _, id := range []string{"aaaa", "bbbb", "dddd", "eeee"} {
+		task, err := store.LoadTask(id)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusDone, task.Status, id)
+	}
+	cancelled, err := store.LoadTask("cccc")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, cancelled.Status)
+
+	// Reopening works from the top down
+	statusPretty = true
+	require.NoError(t, runStatus(nil, []string{"bbbb", models.StatusTodo}))
+	for _, id := range []string{"bbbb", "dddd", "eeee"} {
+		task, err := store.LoadTask(id)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusTodo, task.Status, id)
+	}
+
+	// A failure leaves the whole subtree unchanged
+	statusPretty = false
+	structured := &models.Task{ID: "ffff", Name: "Structured", Parent: &child, Action: "do it", Verify: "check it", Result: "report it", Status: models.StatusTodo, Created: now, Updated: now}
+	require.NoError(t, store.SaveTask(structured))
+	err = runStatus(nil, []string{"bbbb", models.StatusDone})
+	assert.ErrorContains(t, err, "requires --outcome")
+	for _, id := range []string{"bbbb", "dddd", "eeee"} {
+		task, err := store.LoadTask(id)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusTodo, task.Status, id)
+	}
+}
+
+func TestStatusDone_VerifyCmdChangedAfterPass(t *testing.T) {
+	tmpDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetEditFlags()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createVerifyTask(t, store, "true")
+	writeTestConfig(t, tmpDir, "requireVerification: true\n")
+
+	verifyPretty = false
+	verifyTimeout = time.Minute
+	require.NoError(t, runVerify(nil, []string{task.ID}))
+
+	// The pass was for the old command
+	resetEditFlags()
+	editVerifyCmd = "go test ./..."
+	require.NoError(t, runEdit(nil, []string{task.ID}))
+
+	statusPretty = false
+	statusOutcome = "finished"
+	defer func() { statusOutcome = "" }()
+	err = runStatus(nil, []string{task.ID, models.StatusDone})
+	assert.Equal(t, models.CodeVerificationFailed, models.ErrorCodeOf(err))
+	assert.ErrorContains(t, err, "ran a different command")
+}
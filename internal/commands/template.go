@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var templatePretty bool
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "List and inspect task templates",
+	Long: `Templates are YAML files in .clipm/templates describing a task subtree.
+Use 'clipm add --template <name> --var key=value' to create tasks from one.`,
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available templates",
+	Args:  cobra.NoArgs,
+	RunE:  runTemplateList,
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a template's variables and tasks",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTemplateShow,
+}
+
+func init() {
+	templateCmd.PersistentFlags().BoolVar(&templatePretty, "pretty", false, "Pretty print output")
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateShowCmd)
+}
+
+func runTemplateList(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	templates, err := store.ListTemplates()
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, outputMode(templatePretty), templates, func() {
+		if len(templates) == 0 {
+			fmt.Printf("No templates found in %s\n", filepath.Join(storage.ClipmDir, storage.TemplatesDir))
+			return
+		}
+		bold := color.New(color.Bold)
+		for _, tmpl := range templates {
+			bold.Print(tmpl.Name)
+			if tmpl.Description != "" {
+				fmt.Printf(" - %s", tmpl.Description)
+			}
+			fmt.Println()
+		}
+	})
+}
+
+func runTemplateShow(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := store.LoadTemplate(args[0])
+	if err != nil {
+		if err == storage.ErrTemplateNotFound {
+			return models.Errorf(models.CodeNotFound, "template %s not found", args[0]).With("name", args[0])
+		}
+		return err
+	}
+
+	return render(os.Stdout, outputMode(templatePretty), tmpl, func() {
+		bold := color.New(color.Bold)
+		gray := color.New(color.FgHiBlack)
+
+		bold.Printf("Template: %s\n", tmpl.Name)
+		if tmpl.Description != "" {
+			fmt.Printf("Description: %s\n", tmpl.Description)
+		}
+		if len(tmpl.Vars) > 0 {
+			fmt.Println("\nVariables:")
+			for _, v := range tmpl.Vars {
+				fmt.Printf("  %s", v.Name)
+				switch {
+				case v.Required:
+					gray.Print(" (required)")
+				case v.Default != "":
+					gray.Printf(" (default: %s)", v.Default)
+				}
+				if v.Description != "" {
+					fmt.Printf(" - %s", v.Description)
+				}
+				fmt.Println()
+			}
+		}
+		fmt.Println("\nTasks:")
+		printPlanNodes(tmpl.Tasks, 1)
+	})
+}
+
+func printPlanNodes(nodes []storage.PlanNode, depth int) {
+	gray := color.New(color.FgHiBlack)
+	indent := strings.Repeat("  ", depth)
+	for _, node := range nodes {
+		fmt.Printf("%s%s", indent, node.Name)
+		if node.Key != "" {
+			gray.Printf(" [%s]", node.Key)
+		}
+		if len(node.BlockedBy) > 0 {
+			gray.Printf(" blocked by %s", strings.Join(node.BlockedBy, ", "))
+		}
+		fmt.Println()
+		printPlanNodes(node.Children, depth+1)
+	}
+}
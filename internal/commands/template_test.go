@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFeatureTemplate = `description: Design, implement and test a feature
+vars:
+  - name: name
+    required: true
+tasks:
+  - key: feature
+    name: "Feature: {{.name}}"
+    action: Ship {{.name}}
+    verify: Demo works
+    result: Release notes
+    children:
+      - key: design
+        name: Design {{.name}}
+        action: Write the design for {{.name}}
+        verify: Design reviewed
+        result: Link to the design
+      - key: impl
+        name: Implement {{.name}}
+        action: Build {{.name}}
+        verify: Code reviewed
+        result: Merged PR
+        blockedBy: [design]
+      - key: test
+        name: Test {{.name}}
+        action: Test {{.name}}
+        verify: Tests pass
+        result: Test report
+        blockedBy: [impl]
+`
+
+func writeTestTemplate(t *testing.T, dir, name, content string) {
+	t.Helper()
+	tmplDir := filepath.Join(dir, storage.ClipmDir, storage.TemplatesDir)
+	require.NoError(t, os.MkdirAll(tmplDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmplDir, name+".yaml"), []byte(content), 0644))
+}
+
+func resetAddFlags() {
+	addDescription = ""
+	addParent = ""
+	addPretty = false
+	addAction = ""
+	addVerify = ""
+	addResult = ""
+	addVerifyCmd = ""
+	addRecur = ""
+	addFields = nil
+	addTemplate = ""
+	addVars = nil
+}
+
+func TestTemplateCommands(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { templatePretty = false }()
+
+	templatePretty = false
+	require.NoError(t, runTemplateList(nil, nil))
+
+	writeTestTemplate(t, dir, "feature", testFeatureTemplate)
+	require.NoError(t, runTemplateList(nil, nil))
+	require.NoError(t, runTemplateShow(nil, []string{"feature"}))
+
+	templatePretty = true
+	require.NoError(t, runTemplateList(nil, nil))
+	require.NoError(t, runTemplateShow(nil, []string{"feature"}))
+
+	assert.ErrorContains(t, runTemplateShow(nil, []string{"missing"}), "not found")
+}
+
+func TestAddCommand_Template(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetAddFlags()
+	writeTestTemplate(t, dir, "feature", testFeatureTemplate)
+
+	resetAddFlags()
+	addTemplate = "feature"
+	addVars = []string{"name=login"}
+	require.NoError(t, runAdd(nil, nil))
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	require.Len(t, tasks, 4)
+
+	byName := make(map[string]string)
+	for _, task := range tasks {
+		byName[task.Name] = task.ID
+	}
+	root, err := store.LoadTask(byName["Feature: login"])
+	require.NoError(t, err)
+	assert.Nil(t, root.Parent)
+
+	design, err := store.LoadTask(byName["Design login"])
+	require.NoError(t, err)
+	require.NotNil(t, design.Parent)
+	assert.Equal(t, root.ID, *design.Parent)
+	assert.Equal(t, "Write the design for login", design.Action)
+
+	impl, err := store.LoadTask(byName["Implement login"])
+	require.NoError(t, err)
+	assert.Equal(t, []string{design.ID}, impl.BlockedBy)
+
+	test, err := store.LoadTask(byName["Test login"])
+	require.NoError(t, err)
+	assert.Equal(t, []string{impl.ID}, test.BlockedBy)
+
+	// Nested under an existing parent, with pretty output
+	addParent = root.ID
+	addPretty = true
+	addVars = []string{"name=logout"}
+	require.NoError(t, runAdd(nil, nil))
+	children, err := store.GetChildren(root.ID)
+	require.NoError(t, err)
+	assert.Len(t, children, 4)
+}
+
+func TestAddCommand_TemplateErrors(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetAddFlags()
+	writeTestTemplate(t, dir, "feature", testFeatureTemplate)
+
+	resetAddFlags()
+	addTemplate = "missing"
+	assert.ErrorContains(t, runAdd(nil, nil), "not found")
+
+	addTemplate = "feature"
+	assert.ErrorContains(t, runAdd(nil, nil), `requires variable "name"`)
+
+	addVars = []string{"name"}
+	assert.ErrorContains(t, runAdd(nil, nil), "expected key=value")
+
+	addVars = []string{"name=login"}
+	assert.ErrorContains(t, runAdd(nil, []string{"Extra"}), "cannot combine")
+
+	addAction = "do it"
+	assert.ErrorContains(t, runAdd(nil, nil), "cannot be combined")
+	addAction = ""
+
+	addParent = "zzzz"
+	assert.ErrorContains(t, runAdd(nil, nil), "not found")
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	// --var without --template is rejected
+	resetAddFlags()
+	addAction, addVerify, addResult = "a", "v", "r"
+	addVars = []string{"name=login"}
+	assert.ErrorContains(t, runAdd(nil, []string{"Task"}), "only be used with --template")
+}
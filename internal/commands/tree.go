@@ -1,11 +1,11 @@
 package commands
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
-	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/simonspoon/clipm/internal/models"
@@ -15,21 +15,37 @@ import (
 
 var treePretty bool
 var treeShowAll bool
+var treeWhere string
 
 var treeCmd = &cobra.Command{
 	Use:   "tree",
 	Short: "Display tasks in a hierarchical tree view",
-	Long:  `Display all tasks in a hierarchical tree structure showing parent-child relationships.`,
-	RunE:  runTree,
+	Long: `Display all tasks in a hierarchical tree structure showing parent-child relationships.
+
+Parent tasks show how much of their subtree is done. With --pretty=false the tree
+is printed as nested JSON, each node carrying its children and progress.
+
+With --where, only matching tasks are shown, along with their ancestors so
+each match stays in place.`,
+	RunE: runTree,
 }
 
 func init() {
 	// tree defaults to pretty since JSON hierarchy is awkward
 	treeCmd.Flags().BoolVar(&treePretty, "pretty", true, "Pretty print output (default true for tree)")
 	treeCmd.Flags().BoolVar(&treeShowAll, "show-all", false, "Show all tasks including completed")
+	treeCmd.Flags().StringVar(&treeWhere, "where", "", "Only include tasks matching a query, e.g. \"status:todo owner:none\"")
 }
 
 func runTree(cmd *cobra.Command, args []string) error {
+	var query *models.Query
+	if treeWhere != "" {
+		var err error
+		if query, err = models.ParseQuery(treeWhere); err != nil {
+			return err
+		}
+	}
+
 	// Load storage
 	store, err := storage.NewStorage()
 	if err != nil {
@@ -42,29 +58,25 @@ func runTree(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Progress counts every descendant, including done tasks hidden below
+	progress := models.ComputeProgress(tasks)
+
+	all := tasks
 	if !treeShowAll {
 		tasks = filterCompletedTasks(tasks)
 	}
-
-	if len(tasks) == 0 {
-		if treePretty {
-			fmt.Println("No tasks found")
-		} else {
-			fmt.Println("[]")
-		}
-		return nil
+	if query != nil {
+		tasks = withAncestors(filterWhere(tasks, all, query), all)
 	}
 
-	if !treePretty {
-		out, _ := json.Marshal(tasks)
-		fmt.Println(string(out))
+	mode := outputMode(treePretty)
+	if len(tasks) == 0 && mode == outputPretty {
+		fmt.Println("No tasks found")
 		return nil
 	}
 
-	// Sort tasks by creation time
-	sort.Slice(tasks, func(i, j int) bool {
-		return tasks[i].Created.Before(tasks[j].Created)
-	})
+	// Oldest first, siblings in rank order
+	models.SortByRank(tasks)
 
 	// Build task map for easy lookup
 	taskMap := make(map[string]models.Task)
@@ -80,16 +92,67 @@ func runTree(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if mode != outputPretty {
+		nodes := make([]treeNode, 0, len(roots))
+		for i := range roots {
+			nodes = append(nodes, buildTreeNode(roots[i], taskMap, progress))
+		}
+		return render(os.Stdout, mode, nodes, nil)
+	}
+
 	// Print tree for each root
 	for i := range roots {
 		isLast := i == len(roots)-1
-		printTaskTree(os.Stdout, &roots[i], taskMap, "", isLast)
+		printTaskTree(os.Stdout, &roots[i], taskMap, progress, "", isLast)
 	}
 
 	return nil
 }
 
-func printTaskTree(w io.Writer, task *models.Task, taskMap map[string]models.Task, prefix string, isLast bool) {
+// treeNode is a task with its progress and children, for nested JSON output
+type treeNode struct {
+	*models.Task
+	Progress *models.Progress `json:"progress,omitempty"`
+	Children []treeNode       `json:"children"`
+}
+
+func buildTreeNode(task models.Task, taskMap map[string]models.Task, progress map[string]*models.Progress) treeNode {
+	node := treeNode{Task: &task, Progress: progress[task.ID], Children: []treeNode{}}
+	for _, child := range childrenOf(task.ID, taskMap) {
+		node.Children = append(node.Children, buildTreeNode(child, taskMap, progress))
+	}
+	return node
+}
+
+// childrenOf returns the tasks in taskMap whose parent is id, oldest first
+func childrenOf(id string, taskMap map[string]models.Task) []models.Task {
+	var children []models.Task
+	for tid := range taskMap {
+		t := taskMap[tid]
+		if t.Parent != nil && *t.Parent == id {
+			children = append(children, t)
+		}
+	}
+	models.SortByRank(children)
+	return children
+}
+
+// walkTree visits each root and then its descendants in taskMap, depth first
+// in tree order. Roots have depth 0.
+func walkTree(roots []models.Task, taskMap map[string]models.Task, visit func(task *models.Task, depth int)) {
+	var walk func(task models.Task, depth int)
+	walk = func(task models.Task, depth int) {
+		visit(&task, depth)
+		for _, child := range childrenOf(task.ID, taskMap) {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+}
+
+func printTaskTree(w io.Writer, task *models.Task, taskMap map[string]models.Task, progress map[string]*models.Progress, prefix string, isLast bool) {
 	boldWhite := color.New(color.Bold, color.FgWhite)
 	gray := color.New(color.FgHiBlack)
 	statusColor := getStatusColor(task.Status)
@@ -103,27 +166,21 @@ func printTaskTree(w io.Writer, task *models.Task, taskMap map[string]models.Tas
 		marker = "├─ "
 	}
 
-	// Format: ID  Name  [STATUS]
+	// Format: ID  Name  [STATUS] checklist progress
 	_, _ = fmt.Fprint(w, prefix+marker)
 	_, _ = gray.Fprintf(w, "%s  ", task.ID)
 	_, _ = boldWhite.Fprint(w, task.Name)
 	_, _ = fmt.Fprint(w, "  ")
 	_, _ = statusColor.Fprintf(w, "[%s]", formatStatus(task.Status))
-	_, _ = fmt.Fprintln(w)
-
-	// Find children
-	var children []models.Task
-	for id := range taskMap {
-		t := taskMap[id]
-		if t.Parent != nil && *t.Parent == task.ID {
-			children = append(children, t)
-		}
+	if done, total := task.ChecklistProgress(); total > 0 {
+		_, _ = gray.Fprintf(w, " %d/%d", done, total)
+	}
+	if p := progress[task.ID]; p != nil {
+		_, _ = gray.Fprintf(w, "  %s", formatProgress(p))
 	}
+	_, _ = fmt.Fprintln(w)
 
-	// Sort children by creation time
-	sort.Slice(children, func(i, j int) bool {
-		return children[i].Created.Before(children[j].Created)
-	})
+	children := childrenOf(task.ID, taskMap)
 
 	// Print children recursively
 	for i := range children {
@@ -136,7 +193,7 @@ func printTaskTree(w io.Writer, task *models.Task, taskMap map[string]models.Tas
 		} else {
 			childPrefix = prefix + "│  "
 		}
-		printTaskTree(w, &children[i], taskMap, childPrefix, childIsLast)
+		printTaskTree(w, &children[i], taskMap, progress, childPrefix, childIsLast)
 	}
 }
 
@@ -148,6 +205,8 @@ func getStatusColor(status string) *color.Color {
 		return color.New(color.FgYellow)
 	case models.StatusDone:
 		return color.New(color.FgGreen)
+	case models.StatusCancelled:
+		return color.New(color.FgHiBlack)
 	default:
 		return color.New(color.FgWhite)
 	}
@@ -159,9 +218,24 @@ func formatStatus(status string) string {
 		return "IN-PROG"
 	case models.StatusDone:
 		return "DONE"
+	case models.StatusCancelled:
+		return "CANCEL"
 	case models.StatusTodo:
 		return "TODO"
 	default:
 		return status
 	}
 }
+
+// progressBarWidth is the number of cells in a rendered progress bar
+const progressBarWidth = 10
+
+// formatProgress renders progress as a bar, percentage and done/total count
+func formatProgress(p *models.Progress) string {
+	filled := int(p.Percent / 100 * progressBarWidth)
+	if filled > progressBarWidth {
+		filled = progressBarWidth
+	}
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+	return fmt.Sprintf("%s %s%% (%d/%d)", bar, strconv.FormatFloat(p.Percent, 'f', -1, 64), p.Done, p.Total)
+}
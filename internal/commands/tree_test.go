@@ -6,6 +6,7 @@ import (
 
 	"github.com/simonspoon/clipm/internal/models"
 	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -206,3 +207,33 @@ func TestTreeCommand_JSONOutput(t *testing.T) {
 	err = runTree(nil, []string{})
 	require.NoError(t, err)
 }
+
+func TestBuildTreeNode(t *testing.T) {
+	now := time.Now()
+	root := "aaaa"
+	tasks := []models.Task{
+		{ID: "aaaa", Name: "Root", Status: models.StatusInProgress, Created: now},
+		{ID: "bbbb", Name: "Done child", Parent: &root, Status: models.StatusDone, Created: now.Add(time.Second)},
+		{ID: "cccc", Name: "Open child", Parent: &root, Status: models.StatusTodo, Created: now.Add(2 * time.Second)},
+	}
+	taskMap := make(map[string]models.Task)
+	for i := range tasks {
+		taskMap[tasks[i].ID] = tasks[i]
+	}
+
+	node := buildTreeNode(tasks[0], taskMap, models.ComputeProgress(tasks))
+	assert.Equal(t, "aaaa", node.ID)
+	require.NotNil(t, node.Progress)
+	assert.Equal(t, 50.0, node.Progress.Percent)
+	require.Len(t, node.Children, 2)
+	assert.Equal(t, "bbbb", node.Children[0].ID)
+	assert.Equal(t, "cccc", node.Children[1].ID)
+	assert.Nil(t, node.Children[0].Progress)
+	assert.NotNil(t, node.Children[0].Children)
+}
+
+func TestFormatProgress(t *testing.T) {
+	assert.Equal(t, "████░░░░░░ 40% (2/5)", formatProgress(&models.Progress{Done: 2, Total: 5, Percent: 40}))
+	assert.Equal(t, "███░░░░░░░ 33.3% (1/3)", formatProgress(&models.Progress{Done: 1, Total: 3, Percent: 33.3}))
+	assert.Equal(t, "██████████ 100% (3/3)", formatProgress(&models.Progress{Done: 3, Total: 3, Percent: 100}))
+}
@@ -1,8 +1,7 @@
 package commands
 
 import (
-	"encoding/json"
-	"fmt"
+	"os"
 	"time"
 
 	"github.com/fatih/color"
@@ -28,11 +27,11 @@ func init() {
 func runUnblock(cmd *cobra.Command, args []string) error {
 	blockerID := models.NormalizeTaskID(args[0])
 	if !models.IsValidTaskID(blockerID) {
-		return fmt.Errorf("invalid blocker ID: %s", args[0])
+		return models.Errorf(models.CodeInvalidID, "invalid blocker ID: %s", args[0]).With("id", args[0])
 	}
 	blockedID := models.NormalizeTaskID(args[1])
 	if !models.IsValidTaskID(blockedID) {
-		return fmt.Errorf("invalid blocked ID: %s", args[1])
+		return models.Errorf(models.CodeInvalidID, "invalid blocked ID: %s", args[1]).With("id", args[1])
 	}
 
 	store, err := storage.NewStorage()
@@ -43,40 +42,37 @@ func runUnblock(cmd *cobra.Command, args []string) error {
 	blocked, err := store.LoadTask(blockedID)
 	if err != nil {
 		if err == storage.ErrTaskNotFound {
-			return fmt.Errorf("task %s not found", blockedID)
+			return models.Errorf(models.CodeNotFound, "task %s not found", blockedID).With("id", blockedID)
 		}
 		return err
 	}
 
-	// Find and remove blocker
-	found := false
-	newBlockedBy := make([]string, 0, len(blocked.BlockedBy))
-	for _, id := range blocked.BlockedBy {
-		if id == blockerID {
-			found = true
-			continue
-		}
-		newBlockedBy = append(newBlockedBy, id)
-	}
-
-	if !found {
-		return fmt.Errorf("task %s is not blocked by %s", blockedID, blockerID)
+	if !removeBlocker(blocked, blockerID) {
+		return models.Errorf(models.CodeNotFound, "task %s is not blocked by %s", blockedID, blockerID)
 	}
-
-	blocked.BlockedBy = newBlockedBy
 	blocked.Updated = time.Now()
 
 	if err := store.SaveTask(blocked); err != nil {
 		return err
 	}
 
-	if unblockPretty {
+	return render(os.Stdout, outputMode(unblockPretty), blocked, func() {
 		green := color.New(color.FgGreen)
 		green.Printf("Task %s is no longer blocked by %s\n", blockedID, blockerID)
-	} else {
-		out, _ := json.Marshal(blocked)
-		fmt.Println(string(out))
-	}
+	})
+}
 
-	return nil
+// removeBlocker removes blockerID from task's BlockedBy and reports whether it was there
+func removeBlocker(task *models.Task, blockerID string) bool {
+	found := false
+	newBlockedBy := make([]string, 0, len(task.BlockedBy))
+	for _, id := range task.BlockedBy {
+		if id == blockerID {
+			found = true
+			continue
+		}
+		newBlockedBy = append(newBlockedBy, id)
+	}
+	task.BlockedBy = newBlockedBy
+	return found
 }
@@ -1,8 +1,7 @@
 package commands
 
 import (
-	"encoding/json"
-	"fmt"
+	"os"
 	"time"
 
 	"github.com/fatih/color"
@@ -28,7 +27,7 @@ func init() {
 func runUnclaim(cmd *cobra.Command, args []string) error {
 	id := models.NormalizeTaskID(args[0])
 	if !models.IsValidTaskID(id) {
-		return fmt.Errorf("invalid task ID: %s", args[0])
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
 	}
 
 	store, err := storage.NewStorage()
@@ -39,29 +38,25 @@ func runUnclaim(cmd *cobra.Command, args []string) error {
 	task, err := store.LoadTask(id)
 	if err != nil {
 		if err == storage.ErrTaskNotFound {
-			return fmt.Errorf("task %s not found", id)
+			return models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
 		}
 		return err
 	}
 
 	if task.Owner == nil {
-		return fmt.Errorf("task %s has no owner", id)
+		return models.Errorf(models.CodeConflict, "task %s has no owner", id)
 	}
 
-	task.Owner = nil
-	task.Updated = time.Now()
+	now := time.Now()
+	task.SetOwner(nil, storage.CurrentActor(), now)
+	task.Updated = now
 
 	if err := store.SaveTask(task); err != nil {
 		return err
 	}
 
-	if unclaimPretty {
+	return render(os.Stdout, outputMode(unclaimPretty), task, func() {
 		green := color.New(color.FgGreen)
 		green.Printf("Task %s ownership cleared\n", id)
-	} else {
-		out, _ := json.Marshal(task)
-		fmt.Println(string(out))
-	}
-
-	return nil
+	})
 }
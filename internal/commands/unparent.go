@@ -1,8 +1,7 @@
 package commands
 
 import (
-	"encoding/json"
-	"fmt"
+	"os"
 	"time"
 
 	"github.com/fatih/color"
@@ -29,7 +28,7 @@ func runUnparent(cmd *cobra.Command, args []string) error {
 	// Normalize and validate task ID
 	id := models.NormalizeTaskID(args[0])
 	if !models.IsValidTaskID(id) {
-		return fmt.Errorf("invalid task ID: %s", args[0])
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
 	}
 
 	// Load storage
@@ -41,37 +40,29 @@ func runUnparent(cmd *cobra.Command, args []string) error {
 	// Load the task
 	task, err := store.LoadTask(id)
 	if err != nil {
-		return fmt.Errorf("task %s not found", id)
+		return models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
 	}
 
 	// Check if task already has no parent
 	if task.Parent == nil {
-		if unparentPretty {
+		return render(os.Stdout, outputMode(unparentPretty), task, func() {
 			yellow := color.New(color.FgYellow)
 			yellow.Printf("Task %s is already a top-level task\n", id)
-		} else {
-			out, _ := json.Marshal(task)
-			fmt.Println(string(out))
-		}
-		return nil
+		})
 	}
 
 	// Remove parent and update timestamp
-	task.Parent = nil
-	task.Updated = time.Now()
+	now := time.Now()
+	task.SetParent(nil, storage.CurrentActor(), now)
+	task.Updated = now
 
 	// Save the task
 	if err := store.SaveTask(task); err != nil {
 		return err
 	}
 
-	if unparentPretty {
+	return render(os.Stdout, outputMode(unparentPretty), task, func() {
 		green := color.New(color.FgGreen)
 		green.Printf("Task %s is now a top-level task\n", id)
-	} else {
-		out, _ := json.Marshal(task)
-		fmt.Println(string(out))
-	}
-
-	return nil
+	})
 }
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyPretty  bool
+	verifyTimeout time.Duration
+)
+
+// maxVerifyOutput caps how much command output is kept in a verification record
+const maxVerifyOutput = 4000
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <id>",
+	Short: "Run a task's verification command",
+	Long: `Run the task's verifyCmd with sh in the project root and record the exit code and
+trimmed output on the task. Exits with an error when the verification fails.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runVerify,
+}
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyPretty, "pretty", false, "Pretty print output")
+	verifyCmd.Flags().DurationVar(&verifyTimeout, "timeout", 5*time.Minute, "Maximum time to let the command run")
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	id := models.NormalizeTaskID(args[0])
+	if !models.IsValidTaskID(id) {
+		return models.Errorf(models.CodeInvalidID, "invalid task ID: %s", args[0]).With("id", args[0])
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+
+	task, err := store.LoadTask(id)
+	if err != nil {
+		if err == storage.ErrTaskNotFound {
+			return models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+		}
+		return err
+	}
+
+	if task.VerifyCmd == "" {
+		return models.Errorf(models.CodeConflict, "task %s has no verification command (set one with 'clipm edit %s --verify-cmd')", id, id)
+	}
+
+	record := runVerification(task.VerifyCmd, store.GetRootDir(), verifyTimeout)
+
+	task.AddVerification(record)
+	task.Updated = time.Now()
+	if err := store.SaveTask(task); err != nil {
+		return err
+	}
+
+	if err := render(os.Stdout, outputMode(verifyPretty), record, func() {
+		printVerification(task.ID, &record)
+	}); err != nil {
+		return err
+	}
+
+	if !record.Passed {
+		if record.TimedOut {
+			return models.Errorf(models.CodeVerificationFailed, "verification of task %s timed out after %s", id, verifyTimeout).
+				With("id", id).With("timedOut", true)
+		}
+		return models.Errorf(models.CodeVerificationFailed, "verification of task %s failed with exit code %d", id, record.ExitCode).
+			With("id", id).With("exitCode", record.ExitCode)
+	}
+	return nil
+}
+
+// runVerification executes command through sh in dir and captures the result
+func runVerification(command, dir string, timeout time.Duration) models.Verification {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	c := exec.CommandContext(ctx, "sh", "-c", command) // #nosec G204 -- the command is the task's own verifyCmd
+	c.Dir = dir
+	c.Stdout = &output
+	c.Stderr = &output
+	// Don't wait forever on background processes that keep the pipes open
+	c.WaitDelay = time.Second
+
+	start := time.Now()
+	err := c.Run()
+	record := models.Verification{
+		Command:    command,
+		Output:     trimVerifyOutput(output.String()),
+		DurationMs: time.Since(start).Milliseconds(),
+		Timestamp:  start,
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		record.ExitCode = -1
+		record.TimedOut = true
+	case err == nil:
+		record.Passed = true
+	case errors.As(err, &exitErr):
+		record.ExitCode = exitErr.ExitCode()
+	default:
+		record.ExitCode = -1
+		record.Output = strings.TrimSpace(record.Output + "\n" + err.Error())
+	}
+	return record
+}
+
+// trimVerifyOutput strips surrounding whitespace and keeps only the tail of
+// long output, cutting at a character boundary
+func trimVerifyOutput(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > maxVerifyOutput {
+		i := len(s) - maxVerifyOutput
+		for i < len(s) && !utf8.RuneStart(s[i]) {
+			i++
+		}
+		s = "..." + s[i:]
+	}
+	return s
+}
+
+func printVerification(id string, record *models.Verification) {
+	if record.Passed {
+		green := color.New(color.FgGreen)
+		green.Printf("Verification of task %s passed", id)
+	} else {
+		red := color.New(color.FgRed)
+		red.Printf("Verification of task %s failed", id)
+	}
+	fmt.Printf(" (exit code %d, %dms)\n", record.ExitCode, record.DurationMs)
+	if record.Output != "" {
+		fmt.Println(record.Output)
+	}
+}
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/simonspoon/clipm/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createVerifyTask(t *testing.T, store *storage.Storage, verifyCmd string) *models.Task {
+	now := time.Now()
+	task := &models.Task{
+		ID:        "aaaa",
+		Name:      "Verified task",
+		Action:    "do it",
+		Verify:    "run the check",
+		Result:    "report it",
+		VerifyCmd: verifyCmd,
+		Status:    models.StatusInProgress,
+		Created:   now,
+		Updated:   now,
+	}
+	require.NoError(t, store.SaveTask(task))
+	return task
+}
+
+func TestVerifyCommand_Pass(t *testing.T) {
+	tmpDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createVerifyTask(t, store, "pwd && echo '  all good  '")
+
+	verifyPretty = false
+	verifyTimeout = time.Minute
+	require.NoError(t, runVerify(nil, []string{task.ID}))
+
+	updated, err := store.LoadTask(task.ID)
+	require.NoError(t, err)
+	latest := updated.LatestVerification()
+	require.NotNil(t, latest)
+	assert.True(t, latest.Passed)
+	assert.Equal(t, 0, latest.ExitCode)
+	assert.Equal(t, task.VerifyCmd, latest.Command)
+	// Runs in the project root; output is trimmed
+	assert.True(t, strings.HasSuffix(latest.Output, "all good"))
+	assert.Contains(t, latest.Output, tmpDir[strings.LastIndex(tmpDir, "/")+1:])
+}
+
+func TestVerifyCommand_Fail(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createVerifyTask(t, store, "echo broken >&2; exit 3")
+
+	verifyPretty = true
+	verifyTimeout = time.Minute
+	err = runVerify(nil, []string{task.ID})
+	assert.ErrorContains(t, err, "exit code 3")
+
+	updated, err := store.LoadTask(task.ID)
+	require.NoError(t, err)
+	latest := updated.LatestVerification()
+	require.NotNil(t, latest)
+	assert.False(t, latest.Passed)
+	assert.Equal(t, 3, latest.ExitCode)
+	assert.Equal(t, "broken", latest.Output)
+}
+
+func TestVerifyCommand_Timeout(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createVerifyTask(t, store, "sleep 5")
+
+	verifyPretty = false
+	verifyTimeout = 100 * time.Millisecond
+	err = runVerify(nil, []string{task.ID})
+	assert.ErrorContains(t, err, "timed out")
+
+	updated, err := store.LoadTask(task.ID)
+	require.NoError(t, err)
+	latest := updated.LatestVerification()
+	require.NotNil(t, latest)
+	assert.True(t, latest.TimedOut)
+	assert.False(t, latest.Passed)
+}
+
+func TestVerifyCommand_Errors(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createVerifyTask(t, store, "")
+
+	verifyPretty = false
+	verifyTimeout = time.Minute
+	assert.ErrorContains(t, runVerify(nil, []string{task.ID}), "no verification command")
+	assert.ErrorContains(t, runVerify(nil, []string{"zzzz"}), "not found")
+	assert.ErrorContains(t, runVerify(nil, []string{"nope!"}), "invalid task ID")
+}
+
+func TestStatusDone_RequireVerification(t *testing.T) {
+	tmpDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	task := createVerifyTask(t, store, "exit 1")
+	writeTestConfig(t, tmpDir, "requireVerification: true\n")
+
+	statusPretty = false
+	statusOutcome = "finished"
+
+	// Never verified
+	assert.ErrorContains(t, runStatus(nil, []string{task.ID, models.StatusDone}), "latest verification has not passed")
+
+	// Failed verification
+	verifyPretty = false
+	verifyTimeout = time.Minute
+	assert.Error(t, runVerify(nil, []string{task.ID}))
+	assert.ErrorContains(t, runStatus(nil, []string{task.ID, models.StatusDone}), "latest verification has not passed")
+
+	// Passing verification
+	task, err = store.LoadTask(task.ID)
+	require.NoError(t, err)
+	task.VerifyCmd = "true"
+	require.NoError(t, store.SaveTask(task))
+	require.NoError(t, runVerify(nil, []string{task.ID}))
+	require.NoError(t, runStatus(nil, []string{task.ID, models.StatusDone}))
+
+	statusOutcome = ""
+}
+
+func TestTrimVerifyOutput(t *testing.T) {
+	assert.Equal(t, "ok", trimVerifyOutput("\n  ok \n"))
+
+	long := strings.Repeat("x", maxVerifyOutput+10)
+	trimmed := trimVerifyOutput(long)
+	assert.True(t, strings.HasPrefix(trimmed, "..."))
+	assert.Len(t, trimmed, maxVerifyOutput+3)
+
+	// Multi-byte characters are never split: "é" is 2 bytes, so the cut
+	// lands inside one and moves forward to the next character
+	long = strings.Repeat("é", maxVerifyOutput) + "x"
+	trimmed = trimVerifyOutput(long)
+	assert.True(t, utf8.ValidString(trimmed))
+	assert.Equal(t, "..."+strings.Repeat("é", maxVerifyOutput/2-1)+"x", trimmed)
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
-	"sort"
 	"strings"
 	"time"
 
@@ -22,6 +21,8 @@ var (
 	watchPretty   bool
 	watchStatus   string
 	watchShowAll  bool
+	watchWhere    string
+	watchOutput   taskOutput
 )
 
 var watchCmd = &cobra.Command{
@@ -34,8 +35,10 @@ var watchCmd = &cobra.Command{
 func init() {
 	watchCmd.Flags().DurationVar(&watchInterval, "interval", 500*time.Millisecond, "Polling interval")
 	watchCmd.Flags().BoolVar(&watchPretty, "pretty", false, "Human-readable output (clear & redraw)")
-	watchCmd.Flags().StringVar(&watchStatus, "status", "", "Filter by status (todo|in-progress|done)")
+	watchCmd.Flags().StringVar(&watchStatus, "status", "", "Filter by status (todo|in-progress|done|cancelled)")
 	watchCmd.Flags().BoolVar(&watchShowAll, "show-all", false, "Show all tasks including completed")
+	addTaskOutputFlags(watchCmd, &watchOutput)
+	watchCmd.Flags().StringVar(&watchWhere, "where", "", "Only include tasks matching a query, e.g. \"status:todo owner:none\"")
 }
 
 // WatchEvent represents a change event for JSON output
@@ -55,7 +58,21 @@ func runWatch(cmd *cobra.Command, args []string) error {
 
 	// Validate status filter
 	if watchStatus != "" && !models.IsValidStatus(watchStatus) {
-		return fmt.Errorf("invalid status %q. Must be: todo, in-progress, done", watchStatus)
+		return models.Errorf(models.CodeInvalidArgument, "invalid status %q. Must be: todo, in-progress, done, cancelled", watchStatus)
+	}
+
+	if err := watchOutput.prepare(outputMode(watchPretty)); err != nil {
+		return err
+	}
+	if watchOutput.mode == outputTable {
+		return models.Errorf(models.CodeInvalidArgument, "watch does not support table output; use json, ndjson, yaml or pretty")
+	}
+
+	var query *models.Query
+	if watchWhere != "" {
+		if query, err = models.ParseQuery(watchWhere); err != nil {
+			return err
+		}
 	}
 
 	// Setup signal handling for graceful shutdown
@@ -63,7 +80,7 @@ func runWatch(cmd *cobra.Command, args []string) error {
 	defer cancel()
 
 	// Enter raw mode when pretty-printing to an interactive terminal
-	rawMode, cleanup := setupRawMode(watchPretty, cancel)
+	rawMode, cleanup := setupRawMode(watchOutput.mode == outputPretty, cancel)
 	if cleanup != nil {
 		defer cleanup()
 	}
@@ -84,24 +101,29 @@ func runWatch(cmd *cobra.Command, args []string) error {
 				continue
 			}
 
+			progress := models.ComputeProgress(tasks)
+			all := tasks
+
 			// Filter by status if specified
 			if watchStatus != "" {
 				tasks = filterByStatus(tasks, watchStatus)
 			}
 
+			if query != nil {
+				tasks = filterWhere(tasks, all, query)
+			}
+
 			if !watchShowAll {
 				tasks = filterCompletedTasks(tasks)
 			}
 
-			// Sort by created time
-			sort.Slice(tasks, func(i, j int) bool {
-				return tasks[i].Created.Before(tasks[j].Created)
-			})
+			// Oldest first, siblings in rank order
+			models.SortByRank(tasks)
 
 			currTasks := toTaskMap(tasks)
 
-			if watchPretty {
-				clearAndRender(tasks, rawMode)
+			if watchOutput.mode == outputPretty {
+				clearAndRender(tasks, progress, rawMode)
 			} else {
 				if first {
 					outputSnapshot(tasks)
@@ -157,8 +179,7 @@ func outputSnapshot(tasks []models.Task) {
 		Tasks:     tasks,
 		Timestamp: time.Now(),
 	}
-	out, _ := json.Marshal(event)
-	fmt.Println(string(out))
+	printWatchEvent(&event)
 }
 
 func outputChanges(prev, curr map[string]models.Task) {
@@ -173,8 +194,7 @@ func outputChanges(prev, curr map[string]models.Task) {
 			Task:      &task,
 			Timestamp: now,
 		}
-		out, _ := json.Marshal(event)
-		fmt.Println(string(out))
+		printWatchEvent(&event)
 	}
 
 	for _, id := range updated {
@@ -184,8 +204,7 @@ func outputChanges(prev, curr map[string]models.Task) {
 			Task:      &task,
 			Timestamp: now,
 		}
-		out, _ := json.Marshal(event)
-		fmt.Println(string(out))
+		printWatchEvent(&event)
 	}
 
 	for _, id := range deleted {
@@ -194,12 +213,46 @@ func outputChanges(prev, curr map[string]models.Task) {
 			TaskID:    id,
 			Timestamp: now,
 		}
-		out, _ := json.Marshal(event)
-		fmt.Println(string(out))
+		printWatchEvent(&event)
+	}
+}
+
+// printWatchEvent prints an event in the output encoding with its tasks
+// projected to --fields, or runs the --format template on it. YAML events are
+// separate documents. Errors are reported on stderr without stopping the watch.
+func printWatchEvent(event *WatchEvent) {
+	if watchOutput.mode == outputYAML {
+		fmt.Println("---")
+	}
+
+	var err error
+	switch {
+	case watchOutput.tmpl != nil:
+		err = watchOutput.execute(os.Stdout, event)
+	case len(watchOutput.keys) > 0:
+		projected := struct {
+			Type      string            `json:"type"`
+			Task      json.RawMessage   `json:"task,omitempty"`
+			Tasks     []json.RawMessage `json:"tasks,omitempty"`
+			TaskID    string            `json:"taskId,omitempty"`
+			Timestamp time.Time         `json:"timestamp"`
+		}{Type: event.Type, TaskID: event.TaskID, Timestamp: event.Timestamp}
+		if event.Task != nil {
+			projected.Task, _ = watchOutput.project(event.Task)
+		}
+		if len(event.Tasks) > 0 {
+			projected.Tasks, _ = watchOutput.projectAll(event.Tasks)
+		}
+		err = watchOutput.render(os.Stdout, projected)
+	default:
+		err = watchOutput.render(os.Stdout, event)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 	}
 }
 
-func clearAndRender(tasks []models.Task, rawMode bool) {
+func clearAndRender(tasks []models.Task, progress map[string]*models.Progress, rawMode bool) {
 	var buf bytes.Buffer
 
 	// Clear screen using ANSI escape codes
@@ -207,10 +260,11 @@ func clearAndRender(tasks []models.Task, rawMode bool) {
 
 	// Header
 	fmt.Fprintf(&buf, "clipm watch - %s\n", time.Now().Format("15:04:05"))
-	fmt.Fprintf(&buf, "Tasks: %d todo, %d in-progress, %d done\n\n",
+	fmt.Fprintf(&buf, "Tasks: %d todo, %d in-progress, %d done, %d cancelled\n\n",
 		countByStatus(tasks, models.StatusTodo),
 		countByStatus(tasks, models.StatusInProgress),
-		countByStatus(tasks, models.StatusDone))
+		countByStatus(tasks, models.StatusDone),
+		countByStatus(tasks, models.StatusCancelled))
 
 	if len(tasks) == 0 {
 		fmt.Fprintln(&buf, "No tasks found.")
@@ -232,7 +286,7 @@ func clearAndRender(tasks []models.Task, rawMode bool) {
 		// Print tree for each root
 		for i := range roots {
 			isLast := i == len(roots)-1
-			printTaskTree(&buf, &roots[i], taskMap, "", isLast)
+			printTaskTree(&buf, &roots[i], taskMap, progress, "", isLast)
 		}
 	}
 
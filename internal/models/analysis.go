@@ -0,0 +1,200 @@
+package models
+
+import (
+	"math"
+	"sort"
+)
+
+// Analysis reports where open work is held up
+type Analysis struct {
+	// Weighted is set when weights come from estimates rather than task counts
+	Weighted bool `json:"weighted"`
+	// CriticalPaths holds the longest chain of work before each open root can finish, longest first
+	CriticalPaths []CriticalPath `json:"criticalPaths"`
+	// Bottlenecks lists open tasks by how much work they transitively block, most first
+	Bottlenecks []Bottleneck `json:"bottlenecks"`
+	// UnownedBlockers lists unowned tasks that are ready to start and block other work
+	UnownedBlockers []Bottleneck `json:"unownedBlockers"`
+}
+
+// CriticalPath is the heaviest chain of open tasks ending at Root. Each task in
+// Path must finish before the next: it blocks it or is one of its children.
+type CriticalPath struct {
+	Root   string   `json:"root"`
+	Path   []string `json:"path"`
+	Length int      `json:"length"`
+	Weight float64  `json:"weight"`
+}
+
+// Bottleneck is an open task and the open work waiting on it through blockedBy
+type Bottleneck struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Status string  `json:"status"`
+	Owner  *string `json:"owner,omitempty"`
+	// Blocks counts the open tasks that transitively wait on this one
+	Blocks int `json:"blocks"`
+	// BlockedWork is the summed weight of those tasks
+	BlockedWork float64 `json:"blockedWork"`
+}
+
+// Analyze finds the critical path to each open root task, the tasks that
+// transitively block the most work, and unowned tasks at the head of blocker
+// chains. Closed tasks are ignored. Task weights are estimates when any open task
+// has one (tasks without one weigh the average), and 1 otherwise.
+func Analyze(tasks []Task) *Analysis {
+	open := make(map[string]*Task)
+	var ids []string
+	for i := range tasks {
+		if !IsClosed(tasks[i].Status) {
+			open[tasks[i].ID] = &tasks[i]
+			ids = append(ids, tasks[i].ID)
+		}
+	}
+	sort.Strings(ids)
+
+	weights, weighted := analysisWeights(open)
+	analysis := &Analysis{Weighted: weighted, CriticalPaths: []CriticalPath{}, Bottlenecks: []Bottleneck{}, UnownedBlockers: []Bottleneck{}}
+
+	// A task cannot finish before its open blockers and open children
+	preds := make(map[string][]string)
+	// dependents maps a blocker to the open tasks it blocks directly
+	dependents := make(map[string][]string)
+	for _, id := range ids {
+		task := open[id]
+		for _, b := range task.BlockedBy {
+			if open[b] != nil {
+				preds[id] = append(preds[id], b)
+				dependents[b] = append(dependents[b], id)
+			}
+		}
+		if task.Parent != nil && open[*task.Parent] != nil {
+			preds[*task.Parent] = append(preds[*task.Parent], id)
+		}
+	}
+	for id := range preds {
+		sort.Strings(preds[id])
+	}
+
+	// Longest weighted chain ending at each task, ignoring edges that close a cycle
+	longest := make(map[string]float64)
+	next := make(map[string]string)
+	visiting := make(map[string]bool)
+	var walk func(id string) float64
+	walk = func(id string) float64 {
+		if w, ok := longest[id]; ok {
+			return w
+		}
+		visiting[id] = true
+		best, bestPred := 0.0, ""
+		for _, p := range preds[id] {
+			if visiting[p] {
+				continue
+			}
+			if w := walk(p); bestPred == "" || w > best {
+				best, bestPred = w, p
+			}
+		}
+		visiting[id] = false
+		longest[id] = best + weights[id]
+		next[id] = bestPred
+		return longest[id]
+	}
+
+	for _, id := range ids {
+		task := open[id]
+		if task.Parent != nil && open[*task.Parent] != nil {
+			continue
+		}
+		cp := CriticalPath{Root: id, Weight: round2(walk(id))}
+		for cur := id; cur != ""; cur = next[cur] {
+			cp.Path = append([]string{cur}, cp.Path...)
+		}
+		cp.Length = len(cp.Path)
+		analysis.CriticalPaths = append(analysis.CriticalPaths, cp)
+	}
+	sort.SliceStable(analysis.CriticalPaths, func(i, j int) bool {
+		return analysis.CriticalPaths[i].Weight > analysis.CriticalPaths[j].Weight
+	})
+
+	for _, id := range ids {
+		// Everything reachable through dependents waits on id
+		seen := map[string]bool{id: true}
+		queue := []string{id}
+		var blocked float64
+		count := 0
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			for _, d := range dependents[cur] {
+				if !seen[d] {
+					seen[d] = true
+					count++
+					blocked += weights[d]
+					queue = append(queue, d)
+				}
+			}
+		}
+		if count == 0 {
+			continue
+		}
+
+		task := open[id]
+		b := Bottleneck{ID: id, Name: task.Name, Status: task.Status, Owner: task.Owner, Blocks: count, BlockedWork: round2(blocked)}
+		analysis.Bottlenecks = append(analysis.Bottlenecks, b)
+
+		isBlocked := false
+		for _, blocker := range task.BlockedBy {
+			if open[blocker] != nil {
+				isBlocked = true
+			}
+		}
+		if task.Owner == nil && !isBlocked {
+			analysis.UnownedBlockers = append(analysis.UnownedBlockers, b)
+		}
+	}
+	byBlockedWork := func(list []Bottleneck) {
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].BlockedWork != list[j].BlockedWork {
+				return list[i].BlockedWork > list[j].BlockedWork
+			}
+			return list[i].Blocks > list[j].Blocks
+		})
+	}
+	byBlockedWork(analysis.Bottlenecks)
+	byBlockedWork(analysis.UnownedBlockers)
+
+	return analysis
+}
+
+// analysisWeights returns each open task's weight, and whether the weights come
+// from estimates: a task's estimate when any open task has one, otherwise 1
+func analysisWeights(open map[string]*Task) (map[string]float64, bool) {
+	weights := make(map[string]float64, len(open))
+	var sum float64
+	count := 0
+	for id, task := range open {
+		if est, ok := task.Estimate(); ok {
+			weights[id] = est
+			sum += est
+			count++
+		}
+	}
+
+	for id := range open {
+		if _, ok := weights[id]; ok {
+			continue
+		}
+		if count > 0 {
+			weights[id] = sum / float64(count)
+		} else {
+			weights[id] = 1
+		}
+	}
+	return weights, count > 0
+}
+
+// round2 rounds to two decimal places so averaged weights print cleanly
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func analysisTask(id, parent string, blockedBy ...string) Task {
+	task := progressTask(id, parent, StatusTodo, nil)
+	task.BlockedBy = blockedBy
+	return task
+}
+
+func TestAnalyze_CriticalPath(t *testing.T) {
+	// root has children aaaa and bbbb; bbbb waits on aaaa, which waits on cccc
+	tasks := []Task{
+		analysisTask("root", ""),
+		analysisTask("aaaa", "root", "cccc"),
+		analysisTask("bbbb", "root", "aaaa"),
+		analysisTask("cccc", ""),
+		progressTask("dddd", "", StatusDone, nil),
+	}
+	owner := "agent-1"
+	tasks[3].Owner = &owner
+
+	analysis := Analyze(tasks)
+	assert.False(t, analysis.Weighted)
+	require.Len(t, analysis.CriticalPaths, 2)
+	assert.Equal(t, CriticalPath{Root: "root", Path: []string{"cccc", "aaaa", "bbbb", "root"}, Length: 4, Weight: 4}, analysis.CriticalPaths[0])
+	assert.Equal(t, CriticalPath{Root: "cccc", Path: []string{"cccc"}, Length: 1, Weight: 1}, analysis.CriticalPaths[1])
+
+	require.Len(t, analysis.Bottlenecks, 2)
+	assert.Equal(t, "cccc", analysis.Bottlenecks[0].ID)
+	assert.Equal(t, 2, analysis.Bottlenecks[0].Blocks)
+	assert.Equal(t, "aaaa", analysis.Bottlenecks[1].ID)
+	assert.Equal(t, 1, analysis.Bottlenecks[1].Blocks)
+
+	// cccc is owned and aaaa is itself blocked, so neither is an unowned head
+	assert.Empty(t, analysis.UnownedBlockers)
+	tasks[3].Owner = nil
+	analysis = Analyze(tasks)
+	require.Len(t, analysis.UnownedBlockers, 1)
+	assert.Equal(t, "cccc", analysis.UnownedBlockers[0].ID)
+}
+
+func TestAnalyze_Weighted(t *testing.T) {
+	tasks := []Task{
+		analysisTask("aaaa", ""),
+		analysisTask("bbbb", "", "aaaa"),
+		analysisTask("cccc", "", "aaaa"),
+	}
+	tasks[0].Fields = map[string]any{EstimateField: 1.0}
+	tasks[1].Fields = map[string]any{EstimateField: 5.0}
+
+	analysis := Analyze(tasks)
+	assert.True(t, analysis.Weighted)
+	// cccc has no estimate and weighs the average, 3
+	require.Len(t, analysis.CriticalPaths, 3)
+	assert.Equal(t, "bbbb", analysis.CriticalPaths[0].Root)
+	assert.Equal(t, 6.0, analysis.CriticalPaths[0].Weight)
+	assert.Equal(t, 4.0, analysis.CriticalPaths[1].Weight)
+	require.Len(t, analysis.Bottlenecks, 1)
+	assert.Equal(t, 8.0, analysis.Bottlenecks[0].BlockedWork)
+}
+
+func TestAnalyze_Cycle(t *testing.T) {
+	// A child blocked by its own parent can never finish; analysis must still end
+	tasks := []Task{
+		analysisTask("aaaa", ""),
+		analysisTask("bbbb", "aaaa", "aaaa"),
+	}
+	analysis := Analyze(tasks)
+	require.Len(t, analysis.CriticalPaths, 1)
+	assert.Equal(t, []string{"bbbb", "aaaa"}, analysis.CriticalPaths[0].Path)
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode classifies a failure so scripts can react to it without matching
+// on the message
+type ErrorCode string
+
+// Error codes
+const (
+	// CodeInternal is any failure without a more specific code, such as an
+	// unreadable tasks file
+	CodeInternal ErrorCode = "internal"
+	// CodeInvalidArgument is a malformed argument, flag or input document
+	CodeInvalidArgument ErrorCode = "invalid_argument"
+	// CodeInvalidID is an argument that is not a valid task ID
+	CodeInvalidID ErrorCode = "invalid_id"
+	// CodeNotFound is a task, note, link, template or other named thing that
+	// does not exist
+	CodeNotFound ErrorCode = "not_found"
+	// CodeBlocked is a task that cannot start because of unfinished blockers
+	CodeBlocked ErrorCode = "blocked"
+	// CodeHasUndoneChildren is a task that cannot be finished or deleted while
+	// it has unfinished children
+	CodeHasUndoneChildren ErrorCode = "has_undone_children"
+	// CodeConflict is a change that clashes with the task's current state,
+	// such as claiming an owned task or creating a dependency cycle
+	CodeConflict ErrorCode = "conflict"
+	// CodeLocked is a store held by another clipm process
+	CodeLocked ErrorCode = "locked"
+	// CodeNotInProject is a command run outside a clipm project
+	CodeNotInProject ErrorCode = "not_in_project"
+	// CodeVerificationFailed is a verification command that failed or timed out
+	CodeVerificationFailed ErrorCode = "verification_failed"
+)
+
+// Error is a failure with a code and optional details, such as the IDs
+// involved. It prints as its message alone.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Details map[string]any
+	err     error
+}
+
+// Errorf returns an error with the given code. Like fmt.Errorf, a %w verb
+// wraps its operand.
+func Errorf(code ErrorCode, format string, args ...any) *Error {
+	err := fmt.Errorf(format, args...)
+	return &Error{Code: code, Message: err.Error(), err: errors.Unwrap(err)}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// With adds a detail to the error and returns it
+func (e *Error) With(key string, value any) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]any)
+	}
+	e.Details[key] = value
+	return e
+}
+
+// ErrorCodeOf returns the code of the first coded error in err's chain.
+// Query errors are invalid arguments; other errors are internal.
+func ErrorCodeOf(err error) ErrorCode {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	var queryErr *QueryError
+	if errors.As(err, &queryErr) {
+		return CodeInvalidArgument
+	}
+	return CodeInternal
+}
+
+// ErrorDetailsOf returns the details of the first coded error in err's chain
+// that has any
+func ErrorDetailsOf(err error) map[string]any {
+	for err != nil {
+		if coded, ok := err.(*Error); ok && len(coded.Details) > 0 {
+			return coded.Details
+		}
+		err = errors.Unwrap(err)
+	}
+	return nil
+}
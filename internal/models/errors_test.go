@@ -0,0 +1,38 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorf(t *testing.T) {
+	cause := errors.New("disk full")
+	err := Errorf(CodeConflict, "cannot save %s: %w", "abcd", cause).With("id", "abcd")
+
+	assert.Equal(t, "cannot save abcd: disk full", err.Error())
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, CodeConflict, err.Code)
+	assert.Equal(t, map[string]any{"id": "abcd"}, err.Details)
+}
+
+func TestErrorCodeOf(t *testing.T) {
+	notFound := Errorf(CodeNotFound, "task abcd not found").With("id", "abcd")
+
+	assert.Equal(t, CodeNotFound, ErrorCodeOf(notFound))
+	// Codes survive plain wrapping
+	wrapped := fmt.Errorf("plan task x: %w", notFound)
+	assert.Equal(t, CodeNotFound, ErrorCodeOf(wrapped))
+	assert.Equal(t, map[string]any{"id": "abcd"}, ErrorDetailsOf(wrapped))
+
+	// The outermost code wins
+	assert.Equal(t, CodeInvalidArgument, ErrorCodeOf(Errorf(CodeInvalidArgument, "bad plan: %w", notFound)))
+
+	_, err := ParseQuery("status:")
+	assert.Equal(t, CodeInvalidArgument, ErrorCodeOf(err))
+
+	assert.Equal(t, CodeInternal, ErrorCodeOf(errors.New("boom")))
+	assert.Nil(t, ErrorDetailsOf(errors.New("boom")))
+}
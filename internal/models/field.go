@@ -0,0 +1,178 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Valid custom field types
+const (
+	FieldTypeString = "string"
+	FieldTypeNumber = "number"
+	FieldTypeBool   = "bool"
+	FieldTypeEnum   = "enum"
+)
+
+// FieldDef declares a custom field in the project schema
+type FieldDef struct {
+	Type     string   `json:"type" yaml:"type"`
+	Values   []string `json:"values,omitempty" yaml:"values,omitempty"`
+	Required bool     `json:"required,omitempty" yaml:"required,omitempty"`
+}
+
+// FieldSchema maps custom field names to their definitions.
+// An empty schema accepts any field as a plain string.
+type FieldSchema map[string]FieldDef
+
+// Check verifies that every definition in the schema is well formed
+func (s FieldSchema) Check() error {
+	for _, name := range s.Names() {
+		def := s[name]
+		switch def.Type {
+		case FieldTypeString, FieldTypeNumber, FieldTypeBool:
+			if len(def.Values) > 0 {
+				return Errorf(CodeInvalidArgument, "field %q: values are only allowed for enum fields", name)
+			}
+		case FieldTypeEnum:
+			if len(def.Values) == 0 {
+				return Errorf(CodeInvalidArgument, "field %q: enum fields must list their values", name)
+			}
+		default:
+			return Errorf(CodeInvalidArgument, "field %q: invalid type %q. Must be: string, number, bool, enum", name, def.Type)
+		}
+	}
+	return nil
+}
+
+// Names returns the field names in the schema, sorted
+func (s FieldSchema) Names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseValue converts raw command-line input into a typed value for the named field
+func (s FieldSchema) ParseValue(name, raw string) (any, error) {
+	if len(s) == 0 {
+		return raw, nil
+	}
+	def, ok := s[name]
+	if !ok {
+		return nil, Errorf(CodeInvalidArgument, "unknown field %q", name)
+	}
+	switch def.Type {
+	case FieldTypeNumber:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, Errorf(CodeInvalidArgument, "field %q must be a number, got %q", name, raw)
+		}
+		return n, nil
+	case FieldTypeBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, Errorf(CodeInvalidArgument, "field %q must be true or false, got %q", name, raw)
+		}
+		return b, nil
+	default:
+		return raw, s.checkValue(name, def, raw)
+	}
+}
+
+// Validate checks a task's fields against the schema: no unknown fields,
+// values of the declared type, enum membership and required fields present.
+func (s FieldSchema) Validate(fields map[string]any) error {
+	if len(s) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		def, ok := s[name]
+		if !ok {
+			return Errorf(CodeInvalidArgument, "unknown field %q", name)
+		}
+		if err := s.checkValue(name, def, fields[name]); err != nil {
+			return err
+		}
+	}
+	for _, name := range s.Names() {
+		if _, ok := fields[name]; s[name].Required && !ok {
+			return Errorf(CodeInvalidArgument, "missing required field %q", name)
+		}
+	}
+	return nil
+}
+
+func (s FieldSchema) checkValue(name string, def FieldDef, value any) error {
+	switch def.Type {
+	case FieldTypeNumber:
+		if _, ok := toFloat(value); !ok {
+			return Errorf(CodeInvalidArgument, "field %q must be a number", name)
+		}
+	case FieldTypeBool:
+		if _, ok := value.(bool); !ok {
+			return Errorf(CodeInvalidArgument, "field %q must be true or false", name)
+		}
+	case FieldTypeEnum:
+		str, ok := value.(string)
+		if !ok || !containsString(def.Values, str) {
+			return Errorf(CodeInvalidArgument, "field %q must be one of: %s", name, strings.Join(def.Values, ", "))
+		}
+	default:
+		if _, ok := value.(string); !ok {
+			return Errorf(CodeInvalidArgument, "field %q must be a string", name)
+		}
+	}
+	return nil
+}
+
+// ParseFieldAssignment splits a "key=value" argument into its parts
+func ParseFieldAssignment(arg string) (key, value string, err error) {
+	key, value, ok := strings.Cut(arg, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", Errorf(CodeInvalidArgument, "invalid field %q: expected key=value", arg)
+	}
+	return key, value, nil
+}
+
+// FormatFieldValue renders a field value the way it is entered on the command line
+func FormatFieldValue(value any) string {
+	if n, ok := toFloat(value); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// toFloat accepts the numeric types produced by JSON and YAML decoding
+func toFloat(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSchema() FieldSchema {
+	return FieldSchema{
+		"ticket": {Type: FieldTypeString, Required: true},
+		"points": {Type: FieldTypeNumber},
+		"urgent": {Type: FieldTypeBool},
+		"risk":   {Type: FieldTypeEnum, Values: []string{"low", "medium", "high"}},
+	}
+}
+
+func TestFieldSchemaCheck(t *testing.T) {
+	assert.NoError(t, testSchema().Check())
+
+	err := FieldSchema{"x": {Type: "date"}}.Check()
+	assert.ErrorContains(t, err, "invalid type")
+
+	err = FieldSchema{"x": {Type: FieldTypeEnum}}.Check()
+	assert.ErrorContains(t, err, "must list their values")
+
+	err = FieldSchema{"x": {Type: FieldTypeString, Values: []string{"a"}}}.Check()
+	assert.ErrorContains(t, err, "only allowed for enum")
+}
+
+func TestFieldSchemaParseValue(t *testing.T) {
+	schema := testSchema()
+
+	v, err := schema.ParseValue("points", "3.5")
+	require.NoError(t, err)
+	assert.Equal(t, 3.5, v)
+
+	v, err = schema.ParseValue("urgent", "true")
+	require.NoError(t, err)
+	assert.Equal(t, true, v)
+
+	v, err = schema.ParseValue("risk", "low")
+	require.NoError(t, err)
+	assert.Equal(t, "low", v)
+
+	_, err = schema.ParseValue("risk", "extreme")
+	assert.ErrorContains(t, err, "must be one of")
+
+	_, err = schema.ParseValue("points", "many")
+	assert.ErrorContains(t, err, "must be a number")
+
+	_, err = schema.ParseValue("nope", "x")
+	assert.ErrorContains(t, err, "unknown field")
+
+	// Without a schema everything is a string
+	v, err = FieldSchema(nil).ParseValue("anything", "42")
+	require.NoError(t, err)
+	assert.Equal(t, "42", v)
+}
+
+func TestFieldSchemaValidate(t *testing.T) {
+	schema := testSchema()
+
+	assert.NoError(t, schema.Validate(map[string]any{"ticket": "ENG-1", "points": float64(2), "risk": "high"}))
+	assert.ErrorContains(t, schema.Validate(map[string]any{"points": float64(2)}), "missing required field \"ticket\"")
+	assert.ErrorContains(t, schema.Validate(map[string]any{"ticket": "ENG-1", "extra": "x"}), "unknown field")
+	assert.ErrorContains(t, schema.Validate(map[string]any{"ticket": "ENG-1", "urgent": "yes"}), "true or false")
+	assert.ErrorContains(t, schema.Validate(map[string]any{"ticket": 7}), "must be a string")
+
+	// Empty schema accepts anything
+	assert.NoError(t, FieldSchema{}.Validate(map[string]any{"x": 1}))
+}
+
+func TestParseFieldAssignment(t *testing.T) {
+	key, value, err := ParseFieldAssignment("ticket=ENG-1=x")
+	require.NoError(t, err)
+	assert.Equal(t, "ticket", key)
+	assert.Equal(t, "ENG-1=x", value)
+
+	_, _, err = ParseFieldAssignment("ticket")
+	assert.Error(t, err)
+
+	_, _, err = ParseFieldAssignment("=value")
+	assert.Error(t, err)
+}
+
+func TestFormatFieldValue(t *testing.T) {
+	assert.Equal(t, "3", FormatFieldValue(float64(3)))
+	assert.Equal(t, "2.5", FormatFieldValue(2.5))
+	assert.Equal(t, "true", FormatFieldValue(true))
+	assert.Equal(t, "low", FormatFieldValue("low"))
+}
@@ -0,0 +1,90 @@
+package models
+
+import "time"
+
+// Fields tracked in a task's history
+const (
+	HistoryStatus = "status"
+	HistoryOwner  = "owner"
+	HistoryParent = "parent"
+)
+
+// HistoryEntry records one change to a task's status, owner or parent.
+// From and To are empty when the value was unset (no owner, no parent).
+type HistoryEntry struct {
+	Field     string    `json:"field"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Actor     string    `json:"actor,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SetStatus changes the task's status and records the change
+func (t *Task) SetStatus(status, actor string, at time.Time) {
+	t.recordChange(HistoryStatus, t.Status, status, actor, at)
+	t.Status = status
+}
+
+// SetOwner changes the task's owner (nil to release it) and records the change
+func (t *Task) SetOwner(owner *string, actor string, at time.Time) {
+	t.recordChange(HistoryOwner, derefString(t.Owner), derefString(owner), actor, at)
+	t.Owner = owner
+}
+
+// SetParent changes the task's parent (nil for a root task) and records the
+// change. A task that changes parent drops its rank and joins the end of its
+// new siblings.
+func (t *Task) SetParent(parent *string, actor string, at time.Time) {
+	if derefString(t.Parent) != derefString(parent) {
+		t.Rank = nil
+	}
+	t.recordChange(HistoryParent, derefString(t.Parent), derefString(parent), actor, at)
+	t.Parent = parent
+}
+
+func (t *Task) recordChange(field, from, to, actor string, at time.Time) {
+	if from == to {
+		return
+	}
+	t.History = append(t.History, HistoryEntry{
+		Field:     field,
+		From:      from,
+		To:        to,
+		Actor:     actor,
+		Timestamp: at,
+	})
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// StatusDurations returns how long the task has spent in each status, from its
+// creation to now. Time after the task was last closed is not counted.
+func (t *Task) StatusDurations(now time.Time) map[string]time.Duration {
+	durations := make(map[string]time.Duration)
+
+	status := t.Status
+	for _, h := range t.History {
+		if h.Field == HistoryStatus {
+			status = h.From
+			break
+		}
+	}
+
+	since := t.Created
+	for _, h := range t.History {
+		if h.Field != HistoryStatus {
+			continue
+		}
+		durations[status] += h.Timestamp.Sub(since)
+		status, since = h.To, h.Timestamp
+	}
+	if !IsClosed(status) {
+		durations[status] += now.Sub(since)
+	}
+	return durations
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskHistory(t *testing.T) {
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	task := &Task{ID: "aaaa", Status: StatusTodo, Created: start}
+
+	agent := "agent-1"
+	task.SetOwner(&agent, "lead", start.Add(time.Minute))
+	task.SetStatus(StatusInProgress, "agent-1", start.Add(time.Hour))
+	task.SetStatus(StatusInProgress, "agent-1", start.Add(2*time.Hour)) // no-op
+	task.SetStatus(StatusTodo, "agent-1", start.Add(3*time.Hour))
+	task.SetStatus(StatusInProgress, "agent-2", start.Add(4*time.Hour))
+	task.SetStatus(StatusDone, "agent-2", start.Add(6*time.Hour))
+	parent := "pppp"
+	task.SetParent(&parent, "", start.Add(7*time.Hour))
+	task.SetOwner(nil, "", start.Add(8*time.Hour))
+
+	require.Len(t, task.History, 7)
+	assert.Equal(t, HistoryEntry{Field: HistoryOwner, From: "", To: "agent-1", Actor: "lead", Timestamp: start.Add(time.Minute)}, task.History[0])
+	assert.Equal(t, HistoryStatus, task.History[1].Field)
+	assert.Equal(t, StatusTodo, task.History[1].From)
+	assert.Equal(t, StatusInProgress, task.History[1].To)
+	assert.Equal(t, HistoryParent, task.History[5].Field)
+	assert.Equal(t, "pppp", task.History[5].To)
+	assert.Equal(t, "agent-1", task.History[6].From)
+	assert.Equal(t, "", task.History[6].To)
+	assert.Nil(t, task.Owner)
+	assert.Equal(t, StatusDone, task.Status)
+
+	durations := task.StatusDurations(start.Add(24 * time.Hour))
+	assert.Equal(t, 2*time.Hour, durations[StatusTodo])
+	assert.Equal(t, 4*time.Hour, durations[StatusInProgress])
+	assert.Zero(t, durations[StatusDone])
+}
+
+func TestStatusDurations_NoHistory(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	task := &Task{Status: StatusTodo, Created: start}
+	durations := task.StatusDurations(start.Add(time.Hour))
+	assert.Equal(t, map[string]time.Duration{StatusTodo: time.Hour}, durations)
+}
@@ -0,0 +1,53 @@
+package models
+
+// Valid link types. A link is stored on its source task and points at Target:
+// "a duplicates b" means a is a duplicate of b; "a supersedes b" means a replaces b.
+const (
+	LinkRelatesTo  = "relates-to"
+	LinkDuplicates = "duplicates"
+	LinkSupersedes = "supersedes"
+	LinkFollowsUp  = "follows-up"
+)
+
+// Link is a typed relation from one task to another
+type Link struct {
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+// LinkTypes lists the valid link types in display order
+var LinkTypes = []string{LinkRelatesTo, LinkDuplicates, LinkSupersedes, LinkFollowsUp}
+
+// IsValidLinkType checks if a link type is valid
+func IsValidLinkType(linkType string) bool {
+	for _, t := range LinkTypes {
+		if t == linkType {
+			return true
+		}
+	}
+	return false
+}
+
+// InverseLinkType names a link as seen from its target
+func InverseLinkType(linkType string) string {
+	switch linkType {
+	case LinkDuplicates:
+		return "duplicated-by"
+	case LinkSupersedes:
+		return "superseded-by"
+	case LinkFollowsUp:
+		return "followed-up-by"
+	default:
+		return linkType
+	}
+}
+
+// HasLink reports whether the task already links to target with linkType
+func (t *Task) HasLink(linkType, target string) bool {
+	for _, l := range t.Links {
+		if l.Type == linkType && l.Target == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkTypes(t *testing.T) {
+	for _, lt := range LinkTypes {
+		assert.True(t, IsValidLinkType(lt))
+	}
+	assert.False(t, IsValidLinkType("blocks"))
+	assert.False(t, IsValidLinkType(""))
+
+	assert.Equal(t, "relates-to", InverseLinkType(LinkRelatesTo))
+	assert.Equal(t, "duplicated-by", InverseLinkType(LinkDuplicates))
+	assert.Equal(t, "superseded-by", InverseLinkType(LinkSupersedes))
+	assert.Equal(t, "followed-up-by", InverseLinkType(LinkFollowsUp))
+}
+
+func TestHasLink(t *testing.T) {
+	task := &Task{Links: []Link{{Type: LinkRelatesTo, Target: "bbbb"}}}
+	assert.True(t, task.HasLink(LinkRelatesTo, "bbbb"))
+	assert.False(t, task.HasLink(LinkDuplicates, "bbbb"))
+	assert.False(t, task.HasLink(LinkRelatesTo, "cccc"))
+}
@@ -0,0 +1,88 @@
+package models
+
+// Valid note kinds
+const (
+	NoteObservation = "observation"
+	NoteDecision    = "decision"
+	NoteQuestion    = "question"
+	NoteError       = "error"
+	NoteHandoff     = "handoff"
+)
+
+// NoteKinds lists the valid note kinds
+var NoteKinds = []string{NoteObservation, NoteDecision, NoteQuestion, NoteError, NoteHandoff}
+
+// IsValidNoteKind checks if a note kind is valid
+func IsValidNoteKind(kind string) bool {
+	for _, k := range NoteKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveKind returns the note's kind; notes written before kinds existed are observations
+func (n *Note) EffectiveKind() string {
+	if n.Kind == "" {
+		return NoteObservation
+	}
+	return n.Kind
+}
+
+// AddNote appends a note with the next unused ID and returns it. IDs of
+// deleted notes are never handed out again.
+func (t *Task) AddNote(note Note) Note {
+	t.AssignNoteIDs()
+	note.ID = t.nextNoteID()
+	t.Notes = append(t.Notes, note)
+	return note
+}
+
+// FindNote returns the note with the given ID, or nil
+func (t *Task) FindNote(id int) *Note {
+	for i := range t.Notes {
+		if t.Notes[i].ID == id {
+			return &t.Notes[i]
+		}
+	}
+	return nil
+}
+
+// DeleteNote removes the note with the given ID and reports whether it existed.
+// IDs of the remaining notes are unchanged.
+func (t *Task) DeleteNote(id int) bool {
+	for i := range t.Notes {
+		if t.Notes[i].ID == id {
+			t.Notes = append(t.Notes[:i], t.Notes[i+1:]...)
+			if len(t.Notes) == 0 {
+				t.Notes = nil
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// AssignNoteIDs gives notes stored without an ID (from before note IDs existed)
+// the next free IDs in order, so that they can be edited and deleted
+func (t *Task) AssignNoteIDs() {
+	for i := range t.Notes {
+		if t.Notes[i].ID == 0 {
+			t.Notes[i].ID = t.nextNoteID()
+		}
+	}
+}
+
+// nextNoteID returns the next unused note ID and advances the counter. Tasks
+// saved before the counter existed continue after their highest note ID.
+func (t *Task) nextNoteID() int {
+	id := max(t.NextNoteID, 1)
+	for _, n := range t.Notes {
+		if n.ID >= id {
+			id = n.ID + 1
+		}
+	}
+	t.NextNoteID = id + 1
+	return id
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValidNoteKind(t *testing.T) {
+	for _, kind := range NoteKinds {
+		assert.True(t, IsValidNoteKind(kind), kind)
+	}
+	assert.False(t, IsValidNoteKind(""))
+	assert.False(t, IsValidNoteKind("Decision"))
+}
+
+func TestNoteEffectiveKind(t *testing.T) {
+	assert.Equal(t, NoteObservation, (&Note{}).EffectiveKind())
+	assert.Equal(t, NoteError, (&Note{Kind: NoteError}).EffectiveKind())
+}
+
+func TestTaskNotes(t *testing.T) {
+	task := &Task{Notes: []Note{{Content: "legacy a"}, {Content: "legacy b"}}}
+
+	// Legacy notes are numbered before the new note
+	note := task.AddNote(Note{Content: "new"})
+	assert.Equal(t, 3, note.ID)
+	assert.Equal(t, 1, task.Notes[0].ID)
+	assert.Equal(t, 2, task.Notes[1].ID)
+
+	found := task.FindNote(2)
+	require.NotNil(t, found)
+	assert.Equal(t, "legacy b", found.Content)
+	assert.Nil(t, task.FindNote(7))
+
+	assert.True(t, task.DeleteNote(2))
+	assert.False(t, task.DeleteNote(2))
+	assert.Nil(t, task.FindNote(2))
+	assert.Equal(t, 4, task.AddNote(Note{Content: "later"}).ID)
+
+	assert.True(t, task.DeleteNote(1))
+	assert.True(t, task.DeleteNote(3))
+	assert.True(t, task.DeleteNote(4))
+	assert.Nil(t, task.Notes)
+}
+
+func TestTaskNotes_DeletedIDsNotReused(t *testing.T) {
+	task := &Task{}
+	task.AddNote(Note{Content: "one"})
+	task.AddNote(Note{Content: "two"})
+	require.True(t, task.DeleteNote(2))
+	assert.Equal(t, 3, task.AddNote(Note{Content: "three"}).ID)
+
+	// The counter survives deleting every note
+	require.True(t, task.DeleteNote(1))
+	require.True(t, task.DeleteNote(3))
+	assert.Equal(t, 4, task.AddNote(Note{Content: "four"}).ID)
+}
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"math"
+	"strconv"
+)
+
+// EstimateField is the custom field whose numeric value weights progress rollups
+const EstimateField = "estimate"
+
+// Progress summarises how much of a task's subtree is done
+type Progress struct {
+	// Done and Total count descendants at any depth
+	Done  int `json:"done"`
+	Total int `json:"total"`
+	// Percent is weighted by estimates when Weighted is set, otherwise by count
+	Percent  float64 `json:"percent"`
+	Weighted bool    `json:"weighted,omitempty"`
+	// EstimateDone and EstimateTotal are the summed estimates, present when Weighted
+	EstimateDone  float64 `json:"estimateDone,omitempty"`
+	EstimateTotal float64 `json:"estimateTotal,omitempty"`
+}
+
+// Estimate returns the task's estimate field as a number, if it has a usable one.
+// Fields set without a schema are strings, so numeric strings are accepted.
+func (t *Task) Estimate() (float64, bool) {
+	var n float64
+	switch v := t.Fields[EstimateField].(type) {
+	case float64:
+		n = v
+	case int:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	case string:
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, false
+		}
+		n = parsed
+	default:
+		return 0, false
+	}
+	if n < 0 || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, false
+	}
+	return n, true
+}
+
+// rollup accumulates descendant counts and estimates for one subtree
+type rollup struct {
+	done, total int
+	// estimated descendants: count, sum, and the sum over those that are done
+	estCount        int
+	estSum, estDone float64
+	// descendants without an estimate: count, and how many of them are done
+	noEst, noEstDone int
+}
+
+func (r *rollup) add(o rollup) {
+	r.done += o.done
+	r.total += o.total
+	r.estCount += o.estCount
+	r.estSum += o.estSum
+	r.estDone += o.estDone
+	r.noEst += o.noEst
+	r.noEstDone += o.noEstDone
+}
+
+// ComputeProgress returns the progress of every task in tasks that has children.
+// Each descendant counts once, done or not; cancelled descendants and their
+// subtrees are left out. When any descendant has an estimate,
+// Percent is weighted by estimates instead, and descendants without one count as
+// the average estimate of those that have one.
+func ComputeProgress(tasks []Task) map[string]*Progress {
+	children := make(map[string][]*Task)
+	for i := range tasks {
+		if tasks[i].Parent != nil {
+			children[*tasks[i].Parent] = append(children[*tasks[i].Parent], &tasks[i])
+		}
+	}
+
+	rollups := make(map[string]rollup)
+	visiting := make(map[string]bool)
+	var walk func(id string) rollup
+	walk = func(id string) rollup {
+		if r, ok := rollups[id]; ok {
+			return r
+		}
+		visiting[id] = true
+		var r rollup
+		for _, child := range children[id] {
+			if visiting[child.ID] || child.Status == StatusCancelled {
+				continue
+			}
+			var own rollup
+			own.total = 1
+			isDone := child.Status == StatusDone
+			if isDone {
+				own.done = 1
+			}
+			if est, ok := child.Estimate(); ok {
+				own.estCount = 1
+				own.estSum = est
+				if isDone {
+					own.estDone = est
+				}
+			} else {
+				own.noEst = 1
+				if isDone {
+					own.noEstDone = 1
+				}
+			}
+			r.add(own)
+			r.add(walk(child.ID))
+		}
+		visiting[id] = false
+		rollups[id] = r
+		return r
+	}
+
+	progress := make(map[string]*Progress)
+	for i := range tasks {
+		r := walk(tasks[i].ID)
+		if r.total == 0 {
+			continue
+		}
+		p := &Progress{Done: r.done, Total: r.total, Percent: percent(float64(r.done), float64(r.total))}
+		if r.estCount > 0 {
+			avg := r.estSum / float64(r.estCount)
+			p.Weighted = true
+			p.EstimateTotal = r.estSum + avg*float64(r.noEst)
+			p.EstimateDone = r.estDone + avg*float64(r.noEstDone)
+			if p.EstimateTotal > 0 {
+				p.Percent = percent(p.EstimateDone, p.EstimateTotal)
+			}
+		}
+		progress[tasks[i].ID] = p
+	}
+	return progress
+}
+
+// percent returns part/whole as a percentage rounded to one decimal place
+func percent(part, whole float64) float64 {
+	return math.Round(part/whole*1000) / 10
+}
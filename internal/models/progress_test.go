@@ -0,0 +1,105 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func progressTask(id string, parent string, status string, fields map[string]any) Task {
+	task := Task{ID: id, Status: status, Fields: fields}
+	if parent != "" {
+		task.Parent = &parent
+	}
+	return task
+}
+
+func TestComputeProgress_Counts(t *testing.T) {
+	tasks := []Task{
+		progressTask("root", "", StatusInProgress, nil),
+		progressTask("aaaa", "root", StatusDone, nil),
+		progressTask("bbbb", "root", StatusInProgress, nil),
+		progressTask("cccc", "bbbb", StatusDone, nil),
+		progressTask("dddd", "bbbb", StatusTodo, nil),
+	}
+
+	progress := ComputeProgress(tasks)
+	require.Len(t, progress, 2)
+
+	root := progress["root"]
+	require.NotNil(t, root)
+	assert.Equal(t, 2, root.Done)
+	assert.Equal(t, 4, root.Total)
+	assert.Equal(t, 50.0, root.Percent)
+	assert.False(t, root.Weighted)
+
+	assert.Equal(t, &Progress{Done: 1, Total: 2, Percent: 50}, progress["bbbb"])
+	assert.Nil(t, progress["aaaa"])
+}
+
+func TestComputeProgress_SkipsCancelled(t *testing.T) {
+	tasks := []Task{
+		progressTask("root", "", StatusInProgress, nil),
+		progressTask("aaaa", "root", StatusDone, nil),
+		progressTask("bbbb", "root", StatusCancelled, nil),
+		progressTask("cccc", "bbbb", StatusTodo, nil),
+		progressTask("dddd", "root", StatusTodo, nil),
+	}
+
+	// The cancelled task and its subtree are left out
+	progress := ComputeProgress(tasks)
+	assert.Equal(t, &Progress{Done: 1, Total: 2, Percent: 50}, progress["root"])
+}
+
+func TestComputeProgress_Weighted(t *testing.T) {
+	tasks := []Task{
+		progressTask("root", "", StatusTodo, nil),
+		progressTask("aaaa", "root", StatusDone, map[string]any{EstimateField: 6.0}),
+		progressTask("bbbb", "root", StatusTodo, map[string]any{EstimateField: "2"}),
+		// No estimate: counts as the average, 4
+		progressTask("cccc", "root", StatusTodo, nil),
+	}
+
+	root := ComputeProgress(tasks)["root"]
+	require.NotNil(t, root)
+	assert.True(t, root.Weighted)
+	assert.Equal(t, 6.0, root.EstimateDone)
+	assert.Equal(t, 12.0, root.EstimateTotal)
+	assert.Equal(t, 50.0, root.Percent)
+	assert.Equal(t, 1, root.Done)
+	assert.Equal(t, 3, root.Total)
+}
+
+func TestComputeProgress_Rounding(t *testing.T) {
+	tasks := []Task{
+		progressTask("root", "", StatusTodo, nil),
+		progressTask("aaaa", "root", StatusDone, nil),
+		progressTask("bbbb", "root", StatusTodo, nil),
+		progressTask("cccc", "root", StatusTodo, nil),
+	}
+	assert.Equal(t, 33.3, ComputeProgress(tasks)["root"].Percent)
+}
+
+func TestTaskEstimate(t *testing.T) {
+	for _, tc := range []struct {
+		value any
+		want  float64
+		ok    bool
+	}{
+		{3.5, 3.5, true},
+		{2, 2, true},
+		{"1.5", 1.5, true},
+		{"soon", 0, false},
+		{-1.0, 0, false},
+		{true, 0, false},
+	} {
+		task := Task{Fields: map[string]any{EstimateField: tc.value}}
+		got, ok := task.Estimate()
+		assert.Equal(t, tc.ok, ok, "%v", tc.value)
+		assert.Equal(t, tc.want, got, "%v", tc.value)
+	}
+
+	_, ok := (&Task{}).Estimate()
+	assert.False(t, ok)
+}
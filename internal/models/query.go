@@ -0,0 +1,576 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TagsField is the custom field that tag: query terms match against. Its value
+// is a comma- or space-separated list of tags.
+const TagsField = "tags"
+
+// queryKeys lists the keys a query term may use, for error messages. Custom
+// fields are matched with field.<name>.
+var queryKeys = []string{"status", "owner", "id", "parent", "ancestor", "blocked", "tag", "name", "text", "created", "updated", "field.<name>"}
+
+// Query is a parsed filter expression such as
+//
+//	status:todo owner:none (tag:backend OR tag:api) updated:>24h NOT ancestor:abcd
+//
+// Terms are key:value pairs. Adjacent terms must all match; OR, NOT (or a
+// leading -) and parentheses combine them as usual, with NOT binding tightest
+// and OR loosest. Keywords are case-insensitive.
+type Query struct {
+	src  string
+	pred queryPred
+}
+
+// QueryError is a query parse error. Pos is the byte offset of the problem in Query.
+type QueryError struct {
+	Query string
+	Pos   int
+	Msg   string
+}
+
+func (e *QueryError) Error() string {
+	col := utf8.RuneCountInString(e.Query[:e.Pos])
+	return fmt.Sprintf("invalid query: %s\n  %s\n  %s^", e.Msg, e.Query, strings.Repeat(" ", col))
+}
+
+// queryPred reports whether a task matches part of a query
+type queryPred func(t *Task, env *queryEnv) bool
+
+// queryEnv is what a query needs beyond the task itself: other tasks, for
+// parent and blocker lookups, and the current time for relative times
+type queryEnv struct {
+	byID map[string]*Task
+	now  time.Time
+}
+
+// ParseQuery parses a filter expression. Errors are *QueryError values that
+// point at the offending part of the query.
+func ParseQuery(src string) (*Query, error) {
+	tokens, err := lexQuery(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{src: src, tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, p.errorf(0, "query cannot be empty")
+	}
+	pred, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		if tok.kind == tokRParen {
+			return nil, p.errorf(tok.pos, "unexpected ) without a matching (")
+		}
+		return nil, p.errorf(tok.pos, "unexpected %q", tok.text)
+	}
+	return &Query{src: src, pred: pred}, nil
+}
+
+// String returns the query as it was written
+func (q *Query) String() string {
+	return q.src
+}
+
+// Matcher returns a function reporting whether a task matches the query.
+// all is every task in the store, used to resolve parents, ancestors and
+// blockers; now anchors relative times like updated:>24h.
+func (q *Query) Matcher(all []Task, now time.Time) func(*Task) bool {
+	env := &queryEnv{byID: make(map[string]*Task, len(all)), now: now}
+	for i := range all {
+		env.byID[all[i].ID] = &all[i]
+	}
+	return func(t *Task) bool {
+		return q.pred(t, env)
+	}
+}
+
+// Query token kinds
+const (
+	tokEOF = iota
+	tokLParen
+	tokRParen
+	tokNot  // a leading - before a term or group
+	tokWord // a bare word: AND, OR or NOT
+	tokTerm // key:value
+)
+
+type queryToken struct {
+	kind  int
+	pos   int
+	text  string
+	key   string
+	op    string
+	value string
+	// valuePos is the offset of the value, after any operator
+	valuePos int
+}
+
+func lexQuery(src string) ([]queryToken, error) {
+	var tokens []queryToken
+	isBreak := func(r byte) bool {
+		return r == '(' || r == ')' || r == '"' || unicode.IsSpace(rune(r))
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: tokLParen, pos: i, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: tokRParen, pos: i, text: ")"})
+			i++
+		case c == '-' && i+1 < len(src) && !unicode.IsSpace(rune(src[i+1])):
+			tokens = append(tokens, queryToken{kind: tokNot, pos: i, text: "-"})
+			i++
+		case c == '"':
+			return nil, &QueryError{Query: src, Pos: i, Msg: `quoted text must follow a key, as in name:"some words"`}
+		default:
+			start := i
+			for i < len(src) && src[i] != ':' && !isBreak(src[i]) {
+				i++
+			}
+			if i >= len(src) || src[i] != ':' {
+				tokens = append(tokens, queryToken{kind: tokWord, pos: start, text: src[start:i]})
+				continue
+			}
+
+			tok := queryToken{kind: tokTerm, pos: start, key: strings.ToLower(src[start:i])}
+			i++
+			for _, op := range []string{">=", "<=", ">", "<", "="} {
+				if strings.HasPrefix(src[i:], op) {
+					tok.op = op
+					i += len(op)
+					break
+				}
+			}
+			tok.valuePos = i
+			if i < len(src) && src[i] == '"' {
+				end := strings.IndexByte(src[i+1:], '"')
+				if end < 0 {
+					return nil, &QueryError{Query: src, Pos: i, Msg: "unterminated quoted value"}
+				}
+				tok.value = src[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				for i < len(src) && !isBreak(src[i]) {
+					i++
+				}
+				tok.value = src[tok.valuePos:i]
+			}
+			tok.text = src[start:i]
+			if strings.TrimSpace(tok.value) == "" {
+				return nil, &QueryError{Query: src, Pos: tok.valuePos, Msg: fmt.Sprintf("missing value for %s", tok.key)}
+			}
+			tokens = append(tokens, tok)
+		}
+	}
+	return append(tokens, queryToken{kind: tokEOF, pos: len(src)}), nil
+}
+
+type queryParser struct {
+	src    string
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *queryParser) errorf(pos int, format string, args ...any) *QueryError {
+	return &QueryError{Query: p.src, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isKeyword reports whether tok is the bare word kw, ignoring case
+func isKeyword(tok queryToken, kw string) bool {
+	return tok.kind == tokWord && strings.EqualFold(tok.text, kw)
+}
+
+func (p *queryParser) parseOr() (queryPred, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for isKeyword(p.peek(), "or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(t *Task, env *queryEnv) bool { return l(t, env) || right(t, env) }
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryPred, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if isKeyword(tok, "and") {
+			p.next()
+		} else if tok.kind == tokEOF || tok.kind == tokRParen || isKeyword(tok, "or") {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(t *Task, env *queryEnv) bool { return l(t, env) && right(t, env) }
+	}
+}
+
+func (p *queryParser) parseUnary() (queryPred, error) {
+	tok := p.next()
+	switch {
+	case tok.kind == tokNot || isKeyword(tok, "not"):
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(t *Task, env *queryEnv) bool { return !inner(t, env) }, nil
+	case tok.kind == tokLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, p.errorf(tok.pos, "missing ) to close this (")
+		}
+		p.next()
+		return inner, nil
+	case tok.kind == tokTerm:
+		return p.compileTerm(tok)
+	case tok.kind == tokRParen:
+		return nil, p.errorf(tok.pos, "expected a condition before )")
+	case tok.kind == tokEOF:
+		return nil, p.errorf(tok.pos, "expected a condition at end of query")
+	case isKeyword(tok, "and") || isKeyword(tok, "or"):
+		return nil, p.errorf(tok.pos, "expected a condition before %s", strings.ToUpper(tok.text))
+	default:
+		return nil, p.errorf(tok.pos, "unexpected %q: conditions are written key:value, e.g. text:%s", tok.text, tok.text)
+	}
+}
+
+func (p *queryParser) compileTerm(tok queryToken) (queryPred, error) {
+	value := strings.TrimSpace(tok.value)
+	if name, ok := strings.CutPrefix(tok.key, "field."); ok && name != "" {
+		return p.compileField(tok, name, value)
+	}
+	if tok.op != "" && tok.key != "created" && tok.key != "updated" {
+		return nil, p.errorf(tok.pos, "%s does not take an operator; use %s:value", tok.key, tok.key)
+	}
+
+	switch tok.key {
+	case "status":
+		statuses := strings.Split(value, ",")
+		for _, s := range statuses {
+			if !IsValidStatus(s) {
+				return nil, p.errorf(tok.valuePos, "invalid status %q. Must be: todo, in-progress, done, cancelled", s)
+			}
+		}
+		return func(t *Task, _ *queryEnv) bool { return containsString(statuses, t.Status) }, nil
+
+	case "owner":
+		switch strings.ToLower(value) {
+		case "none":
+			return func(t *Task, _ *queryEnv) bool { return t.Owner == nil }, nil
+		case "any":
+			return func(t *Task, _ *queryEnv) bool { return t.Owner != nil }, nil
+		}
+		owners := strings.Split(value, ",")
+		return func(t *Task, _ *queryEnv) bool { return t.Owner != nil && containsString(owners, *t.Owner) }, nil
+
+	case "id", "parent", "ancestor":
+		if tok.key == "parent" && strings.EqualFold(value, "none") {
+			return func(t *Task, _ *queryEnv) bool { return t.Parent == nil }, nil
+		}
+		ids, err := p.taskIDs(tok, value)
+		if err != nil {
+			return nil, err
+		}
+		switch tok.key {
+		case "id":
+			return func(t *Task, _ *queryEnv) bool { return containsString(ids, t.ID) }, nil
+		case "parent":
+			return func(t *Task, _ *queryEnv) bool { return t.Parent != nil && containsString(ids, *t.Parent) }, nil
+		}
+		return func(t *Task, env *queryEnv) bool { return env.hasAncestor(t, ids) }, nil
+
+	case "blocked":
+		want, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, p.errorf(tok.valuePos, "blocked must be true or false, got %q", value)
+		}
+		return func(t *Task, env *queryEnv) bool { return env.isBlocked(t) == want }, nil
+
+	case "tag":
+		return func(t *Task, _ *queryEnv) bool { return hasTag(t, value) }, nil
+
+	case "name":
+		needle := normalizeSearchText(value)
+		return func(t *Task, _ *queryEnv) bool {
+			return strings.Contains(normalizeSearchText(t.Name), needle)
+		}, nil
+
+	case "text":
+		terms := []SearchTerm{{Text: normalizeSearchText(value)}}
+		return func(t *Task, _ *queryEnv) bool {
+			_, ok := searchTask(t, terms)
+			return ok
+		}, nil
+
+	case "created", "updated":
+		return p.compileTime(tok, value)
+	}
+
+	msg := fmt.Sprintf("unknown key %q", tok.key)
+	if s := suggestQueryKey(tok.key); s != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", s)
+	} else {
+		msg += ". Must be one of: " + strings.Join(queryKeys, ", ")
+	}
+	return nil, p.errorf(tok.pos, "%s", msg)
+}
+
+func (p *queryParser) taskIDs(tok queryToken, value string) ([]string, error) {
+	ids := strings.Split(value, ",")
+	for i, id := range ids {
+		ids[i] = NormalizeTaskID(id)
+		if !IsValidTaskID(ids[i]) {
+			return nil, p.errorf(tok.valuePos, "invalid task ID: %s", id)
+		}
+	}
+	return ids, nil
+}
+
+// compileTime handles created: and updated:, which take an operator and either
+// a duration (24h, 3d, 2w) counted back from now or a date (2026-03-01 or
+// RFC 3339). Without an operator, >= is assumed: updated:24h means updated in
+// the last 24 hours.
+func (p *queryParser) compileTime(tok queryToken, value string) (queryPred, error) {
+	op := tok.op
+	switch op {
+	case "":
+		op = ">="
+	case "=":
+		return nil, p.errorf(tok.pos, "%s takes >, >=, < or <=", tok.key)
+	}
+
+	if _, ok := ParseTimeRef(value, time.Now()); !ok {
+		return nil, p.errorf(tok.valuePos, "invalid time %q: use a duration like 24h, 3d or 2w, or a date like 2026-03-01", value)
+	}
+	bound := func(now time.Time) time.Time {
+		at, _ := ParseTimeRef(value, now)
+		return at
+	}
+
+	updated := tok.key == "updated"
+	return func(t *Task, env *queryEnv) bool {
+		at := t.Created
+		if updated {
+			at = t.Updated
+		}
+		b := bound(env.now)
+		switch op {
+		case ">":
+			return at.After(b)
+		case ">=":
+			return !at.Before(b)
+		case "<":
+			return at.Before(b)
+		default:
+			return !at.After(b)
+		}
+	}, nil
+}
+
+// compileField handles field.<name>: none and any test whether the field is
+// set, an operator compares numerically, and anything else must equal the
+// field's value, ignoring case
+func (p *queryParser) compileField(tok queryToken, name, value string) (queryPred, error) {
+	if tok.op == "" || tok.op == "=" {
+		switch strings.ToLower(value) {
+		case "none":
+			return func(t *Task, _ *queryEnv) bool { _, ok := t.Fields[name]; return !ok }, nil
+		case "any":
+			return func(t *Task, _ *queryEnv) bool { _, ok := t.Fields[name]; return ok }, nil
+		}
+		return func(t *Task, _ *queryEnv) bool {
+			v, ok := t.Fields[name]
+			return ok && strings.EqualFold(FormatFieldValue(v), value)
+		}, nil
+	}
+
+	want, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, p.errorf(tok.valuePos, "%s compares numbers, got %q", tok.op, value)
+	}
+	op := tok.op
+	return func(t *Task, _ *queryEnv) bool {
+		v, ok := t.Fields[name]
+		if !ok {
+			return false
+		}
+		n, ok := toFloat(v)
+		if !ok {
+			s, isString := v.(string)
+			if !isString {
+				return false
+			}
+			if n, err = strconv.ParseFloat(strings.TrimSpace(s), 64); err != nil {
+				return false
+			}
+		}
+		switch op {
+		case ">":
+			return n > want
+		case ">=":
+			return n >= want
+		case "<":
+			return n < want
+		default:
+			return n <= want
+		}
+	}, nil
+}
+
+// ParseTimeRef parses a point in time given as a duration counted back from
+// now (24h, 3d, 2w) or a date (2026-03-01 in local time, or RFC 3339)
+func ParseTimeRef(value string, now time.Time) (time.Time, bool) {
+	if d, err := parseQueryDuration(value); err == nil {
+		return now.Add(-d), true
+	}
+	if at, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return at, true
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, true
+	}
+	return time.Time{}, false
+}
+
+// parseQueryDuration accepts Go durations plus whole days (3d) and weeks (2w)
+func parseQueryDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, Errorf(CodeInvalidArgument, "empty duration")
+	}
+	if unit := s[len(s)-1]; unit == 'd' || unit == 'w' {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil {
+			return 0, err
+		}
+		d := time.Duration(n) * 24 * time.Hour
+		if unit == 'w' {
+			d *= 7
+		}
+		return d, nil
+	}
+	return time.ParseDuration(s)
+}
+
+func (env *queryEnv) hasAncestor(t *Task, ids []string) bool {
+	// Bounded by the task count so a corrupt parent cycle cannot loop forever
+	for steps := 0; t.Parent != nil && steps < len(env.byID); steps++ {
+		if containsString(ids, *t.Parent) {
+			return true
+		}
+		if t = env.byID[*t.Parent]; t == nil {
+			return false
+		}
+	}
+	return false
+}
+
+// isBlocked matches storage.IsBlocked: some blocker exists and is not closed
+func (env *queryEnv) isBlocked(t *Task) bool {
+	for _, id := range t.BlockedBy {
+		if blocker := env.byID[id]; blocker != nil && !IsClosed(blocker.Status) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTag reports whether the task's tags field lists tag, ignoring case
+func hasTag(t *Task, tag string) bool {
+	v, ok := t.Fields[TagsField]
+	if !ok {
+		return false
+	}
+	var raw []string
+	if list, isList := v.([]any); isList {
+		for _, item := range list {
+			raw = append(raw, FormatFieldValue(item))
+		}
+	} else {
+		raw = strings.FieldsFunc(FormatFieldValue(v), func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+	}
+	for _, s := range raw {
+		if strings.EqualFold(strings.TrimSpace(s), tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestQueryKey returns the known key closest to key, if it is a likely typo
+func suggestQueryKey(key string) string {
+	if strings.HasPrefix(key, "field") {
+		return "field.<name>"
+	}
+	best, bestDist := "", 3
+	for _, k := range queryKeys {
+		if d := editDistance(key, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queryTasks builds a small store: root > child > grandchild, plus a blocker
+func queryTasks(now time.Time) []Task {
+	root, child := "root", "chld"
+	owner := "agent-1"
+	return []Task{
+		{ID: "root", Name: "Auth system", Status: StatusInProgress, Owner: &owner, Created: now.Add(-72 * time.Hour), Updated: now.Add(-time.Hour)},
+		{ID: "chld", Name: "Login handler", Status: StatusTodo, Parent: &root, BlockedBy: []string{"blkr"}, Fields: map[string]any{TagsField: "backend, api", "estimate": 3.0}, Created: now.Add(-48 * time.Hour), Updated: now.Add(-48 * time.Hour)},
+		{ID: "grnd", Name: "Token refresh", Status: StatusTodo, Parent: &child, Fields: map[string]any{TagsField: []any{"Backend"}, "estimate": "8"}, Created: now.Add(-2 * time.Hour), Updated: now.Add(-2 * time.Hour)},
+		{ID: "blkr", Name: "Schema migration", Status: StatusDone, Notes: []Note{{ID: 1, Content: "retry bug fixed"}}, Created: now.Add(-96 * time.Hour), Updated: now.Add(-30 * time.Minute)},
+	}
+}
+
+func queryIDs(t *testing.T, src string, tasks []Task, now time.Time) []string {
+	t.Helper()
+	query, err := ParseQuery(src)
+	require.NoError(t, err, src)
+	match := query.Matcher(tasks, now)
+	ids := []string{}
+	for i := range tasks {
+		if match(&tasks[i]) {
+			ids = append(ids, tasks[i].ID)
+		}
+	}
+	return ids
+}
+
+func TestQuery_Terms(t *testing.T) {
+	now := time.Now()
+	tasks := queryTasks(now)
+
+	cases := map[string][]string{
+		"status:todo":         {"chld", "grnd"},
+		"status:todo,done":    {"chld", "grnd", "blkr"},
+		"owner:none":          {"chld", "grnd", "blkr"},
+		"owner:any":           {"root"},
+		"owner:agent-1":       {"root"},
+		"id:ROOT,blkr":        {"root", "blkr"},
+		"parent:none":         {"root", "blkr"},
+		"parent:chld":         {"grnd"},
+		"ancestor:root":       {"chld", "grnd"},
+		"blocked:false":       {"root", "chld", "grnd", "blkr"},
+		"tag:backend":         {"chld", "grnd"},
+		"tag:api":             {"chld"},
+		`name:"login hand"`:   {"chld"},
+		"text:retry":          {"blkr"},
+		"updated:>24h":        {"root", "grnd", "blkr"},
+		"updated:<24h":        {"chld"},
+		"created:3d":          {"root", "chld", "grnd"},
+		"field.estimate:>=3":  {"chld", "grnd"},
+		"field.estimate:>3":   {"grnd"},
+		"field.estimate:none": {"root", "blkr"},
+		"field.estimate:3":    {"chld"},
+	}
+	for src, want := range cases {
+		assert.Equal(t, want, queryIDs(t, src, tasks, now), src)
+	}
+
+	since := now.Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+	assert.Equal(t, []string{"grnd"}, queryIDs(t, "created:>="+since, tasks, now))
+
+	// chld is only blocked while blkr is open
+	tasks[3].Status = StatusTodo
+	assert.Equal(t, []string{"chld"}, queryIDs(t, "blocked:true", tasks, now))
+}
+
+func TestQuery_Operators(t *testing.T) {
+	now := time.Now()
+	tasks := queryTasks(now)
+
+	cases := map[string][]string{
+		"status:todo owner:none tag:backend updated:>24h NOT ancestor:abcd": {"grnd"},
+		"status:todo AND tag:api":                {"chld"},
+		"status:done OR owner:any":               {"root", "blkr"},
+		"NOT status:todo":                        {"root", "blkr"},
+		"-status:todo":                           {"root", "blkr"},
+		"not (status:todo or status:done)":       {"root"},
+		"status:todo OR status:done tag:backend": {"chld", "grnd"},
+		"(status:todo OR status:done) -tag:api":  {"grnd", "blkr"},
+		"status:in-progress OR -(ancestor:root)": {"root", "blkr"},
+	}
+	for src, want := range cases {
+		assert.Equal(t, want, queryIDs(t, src, tasks, now), src)
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	cases := map[string]struct {
+		msg string
+		pos int
+	}{
+		"":                     {"cannot be empty", 0},
+		"stauts:todo":          {`did you mean "status"`, 0},
+		"status:todo bogus:x":  {"Must be one of", 12},
+		"status:doing":         {`invalid status "doing"`, 7},
+		"status:todo OR":       {"expected a condition at end", 14},
+		"(status:todo":         {"missing ) to close", 0},
+		"status:todo)":         {"without a matching (", 11},
+		"OR status:todo":       {"expected a condition before OR", 0},
+		"retry":                {"conditions are written key:value", 0},
+		`"retry bug"`:          {"quoted text must follow a key", 0},
+		`name:"retry`:          {"unterminated quoted value", 5},
+		"owner:":               {"missing value for owner", 6},
+		"status:>todo":         {"does not take an operator", 0},
+		"updated:=1d":          {"takes >, >=, < or <=", 0},
+		"updated:>yesterday":   {"invalid time", 9},
+		"blocked:maybe":        {"must be true or false", 8},
+		"parent:abc":           {"invalid task ID", 7},
+		"field.estimate:>lots": {"compares numbers", 16},
+	}
+	for src, want := range cases {
+		_, err := ParseQuery(src)
+		var qerr *QueryError
+		require.ErrorAs(t, err, &qerr, src)
+		assert.Contains(t, qerr.Msg, want.msg, src)
+		assert.Equal(t, want.pos, qerr.Pos, src)
+	}
+}
+
+func TestQueryError_PointsAtProblem(t *testing.T) {
+	_, err := ParseQuery("status:todo stauts:done")
+	require.Error(t, err)
+	assert.Equal(t, "invalid query: unknown key \"stauts\" (did you mean \"status\"?)\n  status:todo stauts:done\n              ^", err.Error())
+}
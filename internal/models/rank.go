@@ -0,0 +1,81 @@
+package models
+
+import (
+	"cmp"
+	"sort"
+)
+
+// CompareRank orders two siblings: ranked tasks first by rank, then unranked
+// tasks oldest first. Ties break by ID so the order is deterministic.
+func CompareRank(a, b *Task) int {
+	switch {
+	case a.Rank != nil && b.Rank != nil:
+		if c := cmp.Compare(*a.Rank, *b.Rank); c != 0 {
+			return c
+		}
+	case a.Rank != nil:
+		return -1
+	case b.Rank != nil:
+		return 1
+	}
+	return compareCreated(a, b)
+}
+
+func compareCreated(a, b *Task) int {
+	if c := a.Created.Compare(b.Created); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// SortByRank orders tasks oldest first, except that tasks sharing a parent
+// follow their manual order (see CompareRank) among the positions they hold.
+// A list of siblings comes out in rank order.
+func SortByRank(tasks []Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return compareCreated(&tasks[i], &tasks[j]) < 0
+	})
+
+	groups := make(map[string][]int)
+	for i := range tasks {
+		parent := derefString(tasks[i].Parent)
+		groups[parent] = append(groups[parent], i)
+	}
+	for _, slots := range groups {
+		if len(slots) < 2 {
+			continue
+		}
+		group := make([]Task, len(slots))
+		for k, i := range slots {
+			group[k] = tasks[i]
+		}
+		sort.SliceStable(group, func(i, j int) bool {
+			return CompareRank(&group[i], &group[j]) < 0
+		})
+		for k, i := range slots {
+			tasks[i] = group[k]
+		}
+	}
+}
+
+// RankBetween returns a rank that sorts after prev and before next. A nil
+// prev or next stands for the start or end of the list. It reports false
+// when either neighbour is unranked or there is no room left between them,
+// in which case the siblings need renumbering.
+func RankBetween(prev, next *Task) (float64, bool) {
+	switch {
+	case prev != nil && prev.Rank == nil, next != nil && next.Rank == nil:
+		return 0, false
+	case prev == nil && next == nil:
+		return 1, true
+	case prev == nil:
+		return *next.Rank - 1, true
+	case next == nil:
+		return *prev.Rank + 1, true
+	}
+	mid := *prev.Rank + (*next.Rank-*prev.Rank)/2
+	if mid <= *prev.Rank || mid >= *next.Rank {
+		return 0, false
+	}
+	return mid, true
+}
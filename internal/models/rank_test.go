@@ -0,0 +1,101 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rankPtr(r float64) *float64 {
+	return &r
+}
+
+func taskIDs(tasks []Task) []string {
+	ids := make([]string, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+	}
+	return ids
+}
+
+func TestCompareRank(t *testing.T) {
+	now := time.Now()
+	ranked := &Task{ID: "bbbb", Rank: rankPtr(2), Created: now}
+	rankedLow := &Task{ID: "cccc", Rank: rankPtr(1), Created: now.Add(time.Hour)}
+	old := &Task{ID: "dddd", Created: now.Add(-time.Hour)}
+	tie := &Task{ID: "aaaa", Created: now.Add(-time.Hour)}
+
+	assert.Equal(t, -1, CompareRank(rankedLow, ranked))
+	// Ranked tasks come before unranked ones, whatever their age
+	assert.Equal(t, -1, CompareRank(ranked, old))
+	assert.Equal(t, 1, CompareRank(old, ranked))
+	assert.Equal(t, 1, CompareRank(old, tie))
+	assert.Equal(t, 0, CompareRank(old, old))
+}
+
+func TestSortByRank(t *testing.T) {
+	now := time.Now()
+	parent := "pppp"
+	tasks := []Task{
+		{ID: "aaaa", Created: now},
+		{ID: "bbbb", Parent: &parent, Created: now.Add(1 * time.Minute)},
+		{ID: "cccc", Created: now.Add(2 * time.Minute)},
+		{ID: "dddd", Parent: &parent, Created: now.Add(3 * time.Minute), Rank: rankPtr(1)},
+		{ID: "eeee", Created: now.Add(4 * time.Minute), Rank: rankPtr(5)},
+	}
+
+	// Siblings swap into each other's positions; the rest stays oldest first
+	SortByRank(tasks)
+	assert.Equal(t, []string{"eeee", "dddd", "aaaa", "bbbb", "cccc"}, taskIDs(tasks))
+
+	// Without ranks the order is creation time
+	plain := []Task{{ID: "bbbb", Created: now.Add(time.Minute)}, {ID: "aaaa", Created: now}}
+	SortByRank(plain)
+	assert.Equal(t, []string{"aaaa", "bbbb"}, taskIDs(plain))
+}
+
+func TestRankBetween(t *testing.T) {
+	one := &Task{Rank: rankPtr(1)}
+	two := &Task{Rank: rankPtr(2)}
+	unranked := &Task{}
+
+	rank, ok := RankBetween(nil, nil)
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, rank)
+
+	rank, ok = RankBetween(nil, one)
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, rank)
+
+	rank, ok = RankBetween(two, nil)
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, rank)
+
+	rank, ok = RankBetween(one, two)
+	assert.True(t, ok)
+	assert.Equal(t, 1.5, rank)
+
+	_, ok = RankBetween(one, unranked)
+	assert.False(t, ok)
+	_, ok = RankBetween(unranked, nil)
+	assert.False(t, ok)
+
+	// No room between adjacent floats
+	a := &Task{Rank: rankPtr(1)}
+	b := &Task{Rank: rankPtr(1.0000000000000002)}
+	_, ok = RankBetween(a, b)
+	assert.False(t, ok)
+}
+
+func TestSetParent_DropsRank(t *testing.T) {
+	parent := "pppp"
+	task := Task{ID: "aaaa", Parent: &parent, Rank: rankPtr(3)}
+
+	same := "pppp"
+	task.SetParent(&same, "", time.Now())
+	assert.NotNil(t, task.Rank)
+
+	task.SetParent(nil, "", time.Now())
+	assert.Nil(t, task.Rank)
+}
@@ -0,0 +1,239 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecurOnDone is the recurrence spec that spawns the next instance as soon as a task is done
+const RecurOnDone = "on-done"
+
+// Recurrence makes a task regenerate after it is completed.
+//
+// Spec is one of:
+//   - "on-done": the next instance is created when the task is marked done
+//   - an interval such as "12h", "1d" or "2w": the next instance is due that long
+//     after the current one was created
+//   - a 5-field cron expression ("minute hour day-of-month month day-of-week")
+//     or one of @hourly, @daily, @weekly, @monthly
+//
+// Next is when the next instance is due (unset for on-done). Spawned holds the
+// ID of the instance created from this task; once set the rule has moved on.
+type Recurrence struct {
+	Spec    string     `json:"spec"`
+	Next    *time.Time `json:"next,omitempty"`
+	Spawned string     `json:"spawned,omitempty"`
+}
+
+// ParseRecurrence validates spec and returns a rule scheduled relative to now
+func ParseRecurrence(spec string, now time.Time) (*Recurrence, error) {
+	spec = strings.Join(strings.Fields(spec), " ")
+	sched, err := parseSchedule(spec)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recurrence{Spec: spec}
+	if sched != nil {
+		next := sched.next(now)
+		r.Next = &next
+	}
+	return r, nil
+}
+
+// Pending reports whether the next instance has not been created yet
+func (r *Recurrence) Pending() bool {
+	return r != nil && r.Spawned == ""
+}
+
+// Due reports whether the next instance should exist at now
+func (r *Recurrence) Due(now time.Time) bool {
+	return r.Pending() && (r.Next == nil || !r.Next.After(now))
+}
+
+// Following returns the rule for the instance spawned at now
+func (r *Recurrence) Following(now time.Time) (*Recurrence, error) {
+	return ParseRecurrence(r.Spec, now)
+}
+
+// schedule computes the next due time after t
+type schedule interface {
+	next(t time.Time) time.Time
+}
+
+func parseSchedule(spec string) (schedule, error) {
+	switch spec {
+	case "":
+		return nil, Errorf(CodeInvalidArgument, "recurrence spec cannot be empty")
+	case RecurOnDone:
+		return nil, nil
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily":
+		spec = "0 0 * * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	}
+
+	if strings.Contains(spec, " ") {
+		return parseCron(spec)
+	}
+	d, err := parseInterval(spec)
+	if err != nil {
+		return nil, Errorf(CodeInvalidArgument, "invalid recurrence %q: expected on-done, an interval like 1d or 12h, or a cron expression", spec)
+	}
+	return interval(d), nil
+}
+
+type interval time.Duration
+
+func (i interval) next(t time.Time) time.Time {
+	return t.Add(time.Duration(i))
+}
+
+// parseInterval accepts Go durations plus whole days ("3d") and weeks ("2w")
+func parseInterval(s string) (time.Duration, error) {
+	var d time.Duration
+	if unit := s[len(s)-1]; unit == 'd' || unit == 'w' {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil {
+			return 0, err
+		}
+		d = time.Duration(n) * 24 * time.Hour
+		if unit == 'w' {
+			d *= 7
+		}
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, err
+		}
+	}
+	if d < time.Minute {
+		return 0, Errorf(CodeInvalidArgument, "interval must be at least 1m")
+	}
+	return d, nil
+}
+
+// cron is a parsed 5-field cron expression; each field is a set of allowed values
+type cron struct {
+	minute, hour, dom, month, dow map[int]bool
+	domAny, dowAny                bool
+}
+
+func parseCron(spec string) (*cron, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return nil, Errorf(CodeInvalidArgument, "invalid cron expression %q: expected 5 fields", spec)
+	}
+
+	c := &cron{domAny: parts[2] == "*", dowAny: parts[4] == "*"}
+	fields := []struct {
+		set      *map[int]bool
+		name     string
+		min, max int
+	}{
+		{&c.minute, "minute", 0, 59},
+		{&c.hour, "hour", 0, 23},
+		{&c.dom, "day-of-month", 1, 31},
+		{&c.month, "month", 1, 12},
+		{&c.dow, "day-of-week", 0, 7},
+	}
+	for i, f := range fields {
+		set, err := parseCronField(parts[i], f.min, f.max)
+		if err != nil {
+			return nil, Errorf(CodeInvalidArgument, "invalid cron expression %q: %s: %w", spec, f.name, err)
+		}
+		*f.set = set
+	}
+	// Both 0 and 7 mean Sunday
+	if c.dow[7] {
+		c.dow[0] = true
+	}
+	// Reject dates that never occur, such as February 30
+	if c.next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, Errorf(CodeInvalidArgument, "invalid cron expression %q: never matches", spec)
+	}
+	return c, nil
+}
+
+// parseCronField handles "*", "n", "a-b", "*/s", "a-b/s" and comma-separated lists
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	set := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return nil, Errorf(CodeInvalidArgument, "invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return nil, Errorf(CodeInvalidArgument, "invalid value %q", from)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return nil, Errorf(CodeInvalidArgument, "invalid value %q", to)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return nil, Errorf(CodeInvalidArgument, "%q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// next returns the first matching minute strictly after t, in t's location,
+// or the zero time if the expression never matches
+func (c *cron) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Any valid expression matches within a few years (Feb 29 needs up to 8)
+	limit := t.AddDate(9, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case !c.month[int(t.Month())]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !c.hour[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !c.minute[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches follows cron's rule: when both day fields are restricted, either may match
+func (c *cron) dayMatches(t time.Time) bool {
+	dom := c.dom[t.Day()]
+	dow := c.dow[int(t.Weekday())]
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	now := time.Date(2025, 3, 14, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"12h", now.Add(12 * time.Hour)},
+		{"1d", now.Add(24 * time.Hour)},
+		{"2w", now.Add(14 * 24 * time.Hour)},
+		{"30m", now.Add(30 * time.Minute)},
+		{"0 9 * * *", time.Date(2025, 3, 15, 9, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 3, 14, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2025, 3, 17, 9, 0, 0, 0, time.UTC)}, // Friday -> Monday
+		{"0 0 1 */3 *", time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2025, 3, 16, 12, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			r, err := ParseRecurrence(tt.spec, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.spec, r.Spec)
+			require.NotNil(t, r.Next)
+			assert.Equal(t, tt.next, *r.Next)
+		})
+	}
+}
+
+func TestParseRecurrence_OnDone(t *testing.T) {
+	r, err := ParseRecurrence(" on-done ", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, RecurOnDone, r.Spec)
+	assert.Nil(t, r.Next)
+	assert.True(t, r.Due(time.Now()))
+}
+
+func TestParseRecurrence_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "soon", "0d", "-1d", "10s", "* * * *", "60 * * * *", "0 24 * * *", "0 0 0 * *", "0 0 * 13 *", "*/0 * * * *", "5-1 * * * *"} {
+		t.Run(spec, func(t *testing.T) {
+			_, err := ParseRecurrence(spec, time.Now())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRecurrenceDue(t *testing.T) {
+	now := time.Now()
+	r, err := ParseRecurrence("1d", now)
+	require.NoError(t, err)
+
+	assert.True(t, r.Pending())
+	assert.False(t, r.Due(now))
+	assert.True(t, r.Due(now.Add(25*time.Hour)))
+
+	r.Spawned = "abcd"
+	assert.False(t, r.Pending())
+	assert.False(t, r.Due(now.Add(25*time.Hour)))
+
+	var none *Recurrence
+	assert.False(t, none.Pending())
+}
+
+func TestParseRecurrence_NeverMatches(t *testing.T) {
+	for _, spec := range []string{"0 0 30 2 *", "0 0 31 4 *"} {
+		t.Run(spec, func(t *testing.T) {
+			_, err := ParseRecurrence(spec, time.Now())
+			assert.Equal(t, CodeInvalidArgument, ErrorCodeOf(err))
+			assert.ErrorContains(t, err, "never matches")
+		})
+	}
+
+	// A restricted day of week can still match when the date never occurs
+	_, err := ParseRecurrence("0 0 30 2 1", time.Now())
+	assert.NoError(t, err)
+}
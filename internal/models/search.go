@@ -0,0 +1,313 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Searchable task fields, as used in field-scoped query terms like name:retry
+const (
+	SearchFieldName        = "name"
+	SearchFieldDescription = "description"
+	SearchFieldAction      = "action"
+	SearchFieldVerify      = "verify"
+	SearchFieldResult      = "result"
+	SearchFieldOutcome     = "outcome"
+	SearchFieldNotes       = "notes"
+)
+
+// searchFields lists the searchable fields in display order with the score of
+// one match in each
+var searchFields = []struct {
+	name   string
+	weight int
+}{
+	{SearchFieldName, 5},
+	{SearchFieldDescription, 2},
+	{SearchFieldAction, 2},
+	{SearchFieldVerify, 2},
+	{SearchFieldResult, 2},
+	{SearchFieldOutcome, 3},
+	{SearchFieldNotes, 1},
+}
+
+// searchFieldAliases maps accepted field prefixes to field names
+var searchFieldAliases = map[string]string{
+	"desc": SearchFieldDescription,
+	"note": SearchFieldNotes,
+}
+
+// snippetContext is how many bytes of text a snippet keeps either side of a match
+const snippetContext = 40
+
+// SearchTerm is one term of a search query. Field is empty when the term may
+// match any field.
+type SearchTerm struct {
+	Field  string `json:"field,omitempty"`
+	Text   string `json:"text"`
+	Phrase bool   `json:"phrase,omitempty"`
+}
+
+// SearchMatch is one field of a task that matched the query
+type SearchMatch struct {
+	Field string `json:"field"`
+	// NoteID identifies the matching note when Field is notes
+	NoteID  int    `json:"noteId,omitempty"`
+	Snippet string `json:"snippet"`
+}
+
+// SearchResult is a task that matched every term of a query
+type SearchResult struct {
+	Task    *Task         `json:"task"`
+	Score   int           `json:"score"`
+	Matches []SearchMatch `json:"matches"`
+}
+
+// ParseSearchQuery splits a query into terms. Double quotes group words into a
+// phrase, and a known field name followed by a colon (name:retry,
+// notes:"retry bug") limits a term to that field. Other text containing a colon
+// is searched as is.
+func ParseSearchQuery(query string) ([]SearchTerm, error) {
+	var terms []SearchTerm
+	rest := strings.TrimSpace(query)
+	for rest != "" {
+		var term SearchTerm
+		if i := strings.IndexByte(rest, ':'); i > 0 && !strings.ContainsFunc(rest[:i], unicode.IsSpace) && !strings.ContainsRune(rest[:i], '"') {
+			if field, ok := searchFieldNamed(rest[:i]); ok {
+				term.Field = field
+				rest = rest[i+1:]
+			}
+		}
+
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return nil, Errorf(CodeInvalidArgument, "unterminated phrase in query: %s", query)
+			}
+			term.Text = normalizeSearchText(rest[1 : end+1])
+			term.Phrase = true
+			rest = rest[end+2:]
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			term.Text = strings.ToLower(rest[:end])
+			rest = rest[end:]
+		}
+		rest = strings.TrimSpace(rest)
+
+		if term.Text == "" {
+			if term.Field != "" {
+				return nil, Errorf(CodeInvalidArgument, "empty search term for field %s", term.Field)
+			}
+			continue
+		}
+		terms = append(terms, term)
+	}
+
+	if len(terms) == 0 {
+		return nil, Errorf(CodeInvalidArgument, "search query cannot be empty")
+	}
+	return terms, nil
+}
+
+func searchFieldNamed(name string) (string, bool) {
+	name = strings.ToLower(name)
+	if field, ok := searchFieldAliases[name]; ok {
+		return field, true
+	}
+	for _, f := range searchFields {
+		if f.name == name {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Search returns the tasks matching every term, best match first. Matching is
+// case-insensitive and ignores differences in whitespace. A task scores the
+// weight of each field a term occurs in, per occurrence; names weigh most and
+// notes least. Ties go to the most recently updated task.
+func Search(tasks []Task, terms []SearchTerm) []SearchResult {
+	results := []SearchResult{}
+	for i := range tasks {
+		if result, ok := searchTask(&tasks[i], terms); ok {
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if !results[i].Task.Updated.Equal(results[j].Task.Updated) {
+			return results[i].Task.Updated.After(results[j].Task.Updated)
+		}
+		return results[i].Task.ID < results[j].Task.ID
+	})
+	return results
+}
+
+// searchText is one piece of searchable text on a task
+type searchText struct {
+	field  string
+	weight int
+	noteID int
+	// text has whitespace collapsed; lower is text in lower case
+	text  string
+	lower string
+}
+
+func searchTask(task *Task, terms []SearchTerm) (SearchResult, bool) {
+	var texts []searchText
+	for _, f := range searchFields {
+		if f.name == SearchFieldNotes {
+			for _, note := range task.Notes {
+				texts = append(texts, newSearchText(f.name, f.weight, note.ID, note.Content))
+			}
+			continue
+		}
+		if text := taskSearchField(task, f.name); strings.TrimSpace(text) != "" {
+			texts = append(texts, newSearchText(f.name, f.weight, 0, text))
+		}
+	}
+
+	result := SearchResult{Task: task}
+	matched := make([]bool, len(texts))
+	for _, term := range terms {
+		found := false
+		for i, t := range texts {
+			if term.Field != "" && term.Field != t.field {
+				continue
+			}
+			if n := strings.Count(t.lower, term.Text); n > 0 {
+				found = true
+				matched[i] = true
+				result.Score += n * t.weight
+			}
+		}
+		if !found {
+			return SearchResult{}, false
+		}
+	}
+
+	for i, t := range texts {
+		if matched[i] {
+			result.Matches = append(result.Matches, SearchMatch{Field: t.field, NoteID: t.noteID, Snippet: searchSnippet(t, terms)})
+		}
+	}
+	return result, true
+}
+
+func taskSearchField(task *Task, field string) string {
+	switch field {
+	case SearchFieldName:
+		return task.Name
+	case SearchFieldDescription:
+		return task.Description
+	case SearchFieldAction:
+		return task.Action
+	case SearchFieldVerify:
+		return task.Verify
+	case SearchFieldResult:
+		return task.Result
+	case SearchFieldOutcome:
+		return task.Outcome
+	}
+	return ""
+}
+
+func newSearchText(field string, weight, noteID int, s string) searchText {
+	text := strings.Join(strings.Fields(s), " ")
+	return searchText{field: field, weight: weight, noteID: noteID, text: text, lower: strings.ToLower(text)}
+}
+
+// normalizeSearchText lower-cases text and collapses runs of whitespace, so
+// phrases match across line breaks
+func normalizeSearchText(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// searchSnippet returns the text around the first term that occurs in it,
+// with "..." marking text cut from either end
+func searchSnippet(t searchText, terms []SearchTerm) string {
+	// Offsets into lower only hold for text when lower-casing kept every length
+	text := t.text
+	if len(t.lower) != len(text) {
+		text = t.lower
+	}
+
+	start := -1
+	for _, term := range terms {
+		if term.Field != "" && term.Field != t.field {
+			continue
+		}
+		if i := strings.Index(t.lower, term.Text); i >= 0 && (start < 0 || i < start) {
+			start = i
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+
+	from, to := start-snippetContext, start+2*snippetContext
+	prefix, suffix := "...", "..."
+	if from <= 0 {
+		from, prefix = 0, ""
+	}
+	if to >= len(text) {
+		to, suffix = len(text), ""
+	}
+	// Avoid cutting a multi-byte character in half
+	for from > 0 && !isRuneStart(text[from]) {
+		from--
+	}
+	for to < len(text) && !isRuneStart(text[to]) {
+		to++
+	}
+	return prefix + text[from:to] + suffix
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// SearchHighlights returns the byte ranges of s, text from the given field,
+// where any term for that field occurs, in order and without overlaps
+func SearchHighlights(s, field string, terms []SearchTerm) [][2]int {
+	lower := strings.ToLower(s)
+	if len(lower) != len(s) {
+		return nil
+	}
+	s = lower
+
+	var spans [][2]int
+	for _, term := range terms {
+		if term.Field != "" && term.Field != field {
+			continue
+		}
+		for off := 0; off < len(s); {
+			i := strings.Index(s[off:], term.Text)
+			if i < 0 {
+				break
+			}
+			spans = append(spans, [2]int{off + i, off + i + len(term.Text)})
+			off += i + len(term.Text)
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	var merged [][2]int
+	for _, span := range spans {
+		if n := len(merged); n > 0 && span[0] <= merged[n-1][1] {
+			if span[1] > merged[n-1][1] {
+				merged[n-1][1] = span[1]
+			}
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	terms, err := ParseSearchQuery(`Retry  "Back  Off" notes:jitter desc:"root cause" http://host`)
+	require.NoError(t, err)
+	assert.Equal(t, []SearchTerm{
+		{Text: "retry"},
+		{Text: "back off", Phrase: true},
+		{Field: SearchFieldNotes, Text: "jitter"},
+		{Field: SearchFieldDescription, Text: "root cause", Phrase: true},
+		{Text: "http://host"},
+	}, terms)
+
+	_, err = ParseSearchQuery(`"unterminated`)
+	assert.ErrorContains(t, err, "unterminated phrase")
+	_, err = ParseSearchQuery(`   `)
+	assert.ErrorContains(t, err, "cannot be empty")
+	_, err = ParseSearchQuery(`name:`)
+	assert.ErrorContains(t, err, "empty search term")
+}
+
+func TestSearch_RanksAndScopes(t *testing.T) {
+	now := time.Now()
+	tasks := []Task{
+		{ID: "aaaa", Name: "Uploader", Description: "Retry loop never\nbacks off", Updated: now},
+		{ID: "bbbb", Name: "Fix retry bug", Updated: now.Add(-time.Hour)},
+		{ID: "cccc", Name: "Docs", Notes: []Note{{ID: 1, Content: "unrelated"}, {ID: 2, Content: "The retry bug was jitter"}}, Updated: now},
+		{ID: "dddd", Name: "Nothing here", Updated: now},
+	}
+
+	terms, err := ParseSearchQuery("retry")
+	require.NoError(t, err)
+	results := Search(tasks, terms)
+	require.Len(t, results, 3)
+	assert.Equal(t, "bbbb", results[0].Task.ID) // name match outweighs the rest
+	assert.Equal(t, "aaaa", results[1].Task.ID)
+	assert.Equal(t, "cccc", results[2].Task.ID)
+	assert.Equal(t, []SearchMatch{{Field: SearchFieldNotes, NoteID: 2, Snippet: "The retry bug was jitter"}}, results[2].Matches)
+
+	// Phrases match across line breaks; every term must match
+	terms, err = ParseSearchQuery(`"never backs" uploader`)
+	require.NoError(t, err)
+	results = Search(tasks, terms)
+	require.Len(t, results, 1)
+	assert.Equal(t, "aaaa", results[0].Task.ID)
+	assert.Equal(t, "Retry loop never backs off", results[0].Matches[1].Snippet)
+
+	terms, err = ParseSearchQuery("name:retry")
+	require.NoError(t, err)
+	results = Search(tasks, terms)
+	require.Len(t, results, 1)
+	assert.Equal(t, "bbbb", results[0].Task.ID)
+
+	terms, err = ParseSearchQuery("retry missing")
+	require.NoError(t, err)
+	assert.Empty(t, Search(tasks, terms))
+}
+
+func TestSearch_Snippet(t *testing.T) {
+	long := strings.Repeat("a ", 50) + "retry" + strings.Repeat(" b", 60)
+	tasks := []Task{{ID: "aaaa", Description: long}}
+	terms, err := ParseSearchQuery("retry")
+	require.NoError(t, err)
+
+	results := Search(tasks, terms)
+	require.Len(t, results, 1)
+	snippet := results[0].Matches[0].Snippet
+	assert.True(t, strings.HasPrefix(snippet, "..."))
+	assert.True(t, strings.HasSuffix(snippet, "..."))
+	assert.Contains(t, snippet, "retry")
+}
+
+func TestSearchHighlights(t *testing.T) {
+	terms, err := ParseSearchQuery(`retry "retry bug" name:fix`)
+	require.NoError(t, err)
+
+	assert.Equal(t, [][2]int{{4, 13}}, SearchHighlights("Fix Retry bug", SearchFieldDescription, terms))
+	assert.Equal(t, [][2]int{{0, 3}, {4, 13}}, SearchHighlights("Fix Retry bug", SearchFieldName, terms))
+	assert.Empty(t, SearchHighlights("nothing", SearchFieldName, terms))
+}
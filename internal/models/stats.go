@@ -0,0 +1,259 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// statsDateLayout is the layout of DayStats.Date
+const statsDateLayout = "2006-01-02"
+
+// Stats reports throughput and timing for tasks over a window of time
+type Stats struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	// Created and Completed count tasks created and marked done in the window.
+	// A task marked done twice counts twice.
+	Created   int `json:"created"`
+	Completed int `json:"completed"`
+	// Reopened counts done tasks moved back to another status in the window,
+	// and ReopenRate is that count per completion
+	Reopened   int     `json:"reopened"`
+	ReopenRate float64 `json:"reopenRate"`
+	// LeadTime runs from creation to done and CycleTime from first start to
+	// done, for each completion in the window
+	LeadTime  Distribution `json:"leadTime"`
+	CycleTime Distribution `json:"cycleTime"`
+	// Days has one entry per local calendar day of the window, oldest first
+	Days []DayStats `json:"days"`
+	// Owners has the completions of each owner, most first. Tasks without an
+	// owner when completed are left out.
+	Owners []OwnerStats `json:"owners"`
+}
+
+// DayStats is the activity of one day
+type DayStats struct {
+	Date      string `json:"date"`
+	Created   int    `json:"created"`
+	Completed int    `json:"completed"`
+	// WIP is how many tasks were in progress at the end of the day
+	WIP int `json:"wip"`
+}
+
+// Distribution summarises a set of durations, in seconds
+type Distribution struct {
+	Count  int   `json:"count"`
+	Min    int64 `json:"min"`
+	Median int64 `json:"median"`
+	P90    int64 `json:"p90"`
+	Max    int64 `json:"max"`
+	Mean   int64 `json:"mean"`
+}
+
+// OwnerStats is the work an owner completed in the window
+type OwnerStats struct {
+	Owner     string       `json:"owner"`
+	Completed int          `json:"completed"`
+	CycleTime Distribution `json:"cycleTime"`
+}
+
+// ComputeStats reports on tasks between since and until. Completions and
+// reopenings come from status history; a done task with no status history
+// counts as completed when it was last updated. Clones created already closed
+// that have not changed status since are left out.
+func ComputeStats(tasks []Task, since, until time.Time) *Stats {
+	stats := &Stats{Since: since, Until: until, Days: []DayStats{}, Owners: []OwnerStats{}}
+
+	dayIndex := make(map[string]int)
+	for day := startOfDay(since); !day.After(until); day = day.AddDate(0, 0, 1) {
+		dayIndex[day.Format(statsDateLayout)] = len(stats.Days)
+		stats.Days = append(stats.Days, DayStats{Date: day.Format(statsDateLayout)})
+	}
+	inWindow := func(at time.Time) bool {
+		return !at.Before(since) && !at.After(until)
+	}
+	day := func(at time.Time) *DayStats {
+		return &stats.Days[dayIndex[at.Local().Format(statsDateLayout)]]
+	}
+
+	var lead, cycle []time.Duration
+	ownerCycles := make(map[string][]time.Duration)
+	for i := range tasks {
+		task := &tasks[i]
+		if task.clonedClosed() {
+			continue
+		}
+		if inWindow(task.Created) {
+			stats.Created++
+			day(task.Created).Created++
+		}
+
+		for _, done := range task.completions() {
+			if !inWindow(done) {
+				continue
+			}
+			stats.Completed++
+			day(done).Completed++
+			lead = append(lead, done.Sub(task.Created))
+
+			started, ok := task.firstStarted()
+			ok = ok && started.Before(done)
+			if ok {
+				cycle = append(cycle, done.Sub(started))
+			}
+			if owner := task.valueAt(HistoryOwner, done); owner != "" {
+				stats.ownerCompleted(owner)
+				if ok {
+					ownerCycles[owner] = append(ownerCycles[owner], done.Sub(started))
+				}
+			}
+		}
+
+		for _, h := range task.History {
+			if h.Field == HistoryStatus && h.From == StatusDone && inWindow(h.Timestamp) {
+				stats.Reopened++
+			}
+		}
+
+		for d := range stats.Days {
+			end := endOfDay(stats.Days[d].Date)
+			if end.After(until) {
+				end = until
+			}
+			if !task.Created.After(end) && task.valueAt(HistoryStatus, end) == StatusInProgress {
+				stats.Days[d].WIP++
+			}
+		}
+	}
+
+	if stats.Completed > 0 {
+		stats.ReopenRate = float64(stats.Reopened) / float64(stats.Completed)
+	}
+	stats.LeadTime = summarize(lead)
+	stats.CycleTime = summarize(cycle)
+	for i := range stats.Owners {
+		stats.Owners[i].CycleTime = summarize(ownerCycles[stats.Owners[i].Owner])
+	}
+	sort.SliceStable(stats.Owners, func(i, j int) bool {
+		if stats.Owners[i].Completed != stats.Owners[j].Completed {
+			return stats.Owners[i].Completed > stats.Owners[j].Completed
+		}
+		return stats.Owners[i].Owner < stats.Owners[j].Owner
+	})
+	return stats
+}
+
+func (s *Stats) ownerCompleted(owner string) {
+	for i := range s.Owners {
+		if s.Owners[i].Owner == owner {
+			s.Owners[i].Completed++
+			return
+		}
+	}
+	s.Owners = append(s.Owners, OwnerStats{Owner: owner, Completed: 1})
+}
+
+// clonedClosed reports whether the task is a clone created closed whose status
+// has not changed since, so none of its work happened as this task
+func (t *Task) clonedClosed() bool {
+	if t.ClonedFrom == "" || !IsClosed(t.Status) {
+		return false
+	}
+	for _, h := range t.History {
+		if h.Field == HistoryStatus {
+			return false
+		}
+	}
+	return true
+}
+
+// completions returns when the task was marked done
+func (t *Task) completions() []time.Time {
+	var times []time.Time
+	hasStatusHistory := false
+	for _, h := range t.History {
+		if h.Field != HistoryStatus {
+			continue
+		}
+		hasStatusHistory = true
+		if h.To == StatusDone {
+			times = append(times, h.Timestamp)
+		}
+	}
+	if !hasStatusHistory && t.Status == StatusDone {
+		times = append(times, t.Updated)
+	}
+	return times
+}
+
+// firstStarted returns when the task was first moved to in-progress
+func (t *Task) firstStarted() (time.Time, bool) {
+	for _, h := range t.History {
+		if h.Field == HistoryStatus && h.To == StatusInProgress {
+			return h.Timestamp, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// valueAt returns the task's status or owner at a point in its life, replaying
+// its history. Without history for the field, the current value is used.
+func (t *Task) valueAt(field string, at time.Time) string {
+	value, seen := "", false
+	for _, h := range t.History {
+		if h.Field != field {
+			continue
+		}
+		if !seen {
+			value, seen = h.From, true
+		}
+		if h.Timestamp.After(at) {
+			return value
+		}
+		value = h.To
+	}
+	if seen {
+		return value
+	}
+	if field == HistoryOwner {
+		return derefString(t.Owner)
+	}
+	return t.Status
+}
+
+func summarize(durations []time.Duration) Distribution {
+	if len(durations) == 0 {
+		return Distribution{}
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	rank := func(p float64) int64 {
+		i := int(math.Ceil(p*float64(len(sorted)))) - 1
+		return int64(sorted[max(i, 0)].Seconds())
+	}
+	return Distribution{
+		Count:  len(sorted),
+		Min:    int64(sorted[0].Seconds()),
+		Median: rank(0.5),
+		P90:    rank(0.9),
+		Max:    int64(sorted[len(sorted)-1].Seconds()),
+		Mean:   int64((total / time.Duration(len(sorted))).Seconds()),
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// endOfDay returns the last instant of a day given as statsDateLayout
+func endOfDay(date string) time.Time {
+	day, _ := time.ParseInLocation(statsDateLayout, date, time.Local)
+	return day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
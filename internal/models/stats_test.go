@@ -0,0 +1,91 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeStats(t *testing.T) {
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	day3 := day1.AddDate(0, 0, 2)
+	alice := "alice"
+
+	// Started on day 1, done on day 2 by alice
+	fast := Task{ID: "aaaa", Status: StatusTodo, Created: day1}
+	fast.SetOwner(&alice, "", day1)
+	fast.SetStatus(StatusInProgress, "", day1.Add(time.Hour))
+	fast.SetStatus(StatusDone, "", day2)
+
+	// Done on day 2, reopened and done again on day 3, never started
+	reopened := Task{ID: "bbbb", Status: StatusTodo, Created: day1}
+	reopened.SetStatus(StatusDone, "", day2)
+	reopened.SetStatus(StatusTodo, "", day3)
+	reopened.SetStatus(StatusDone, "", day3.Add(time.Hour))
+
+	// In progress since day 2
+	open := Task{ID: "cccc", Status: StatusTodo, Created: day2}
+	open.SetStatus(StatusInProgress, "", day2)
+
+	// Done before history was kept: completed when last updated
+	legacy := Task{ID: "dddd", Status: StatusDone, Created: day1, Updated: day3}
+
+	stats := ComputeStats([]Task{fast, reopened, open, legacy}, day1.Add(-time.Hour), day3.Add(2*time.Hour))
+
+	assert.Equal(t, 4, stats.Created)
+	assert.Equal(t, 4, stats.Completed)
+	assert.Equal(t, 1, stats.Reopened)
+	assert.InDelta(t, 0.25, stats.ReopenRate, 1e-9)
+
+	require.Len(t, stats.Days, 3)
+	assert.Equal(t, DayStats{Date: "2026-03-01", Created: 3, Completed: 0, WIP: 1}, stats.Days[0])
+	assert.Equal(t, DayStats{Date: "2026-03-02", Created: 1, Completed: 2, WIP: 1}, stats.Days[1])
+	assert.Equal(t, DayStats{Date: "2026-03-03", Created: 0, Completed: 2, WIP: 1}, stats.Days[2])
+
+	day := int64(24 * time.Hour / time.Second)
+	hour := int64(time.Hour / time.Second)
+	// Lead times: 1d (fast), 1d (reopened, first), 2d1h (reopened, second), 2d (legacy)
+	assert.Equal(t, Distribution{Count: 4, Min: day, Median: day, P90: 2*day + hour, Max: 2*day + hour, Mean: (6*day + hour) / 4}, stats.LeadTime)
+	// Only fast was ever started
+	assert.Equal(t, Distribution{Count: 1, Min: day - hour, Median: day - hour, P90: day - hour, Max: day - hour, Mean: day - hour}, stats.CycleTime)
+
+	require.Len(t, stats.Owners, 1)
+	assert.Equal(t, "alice", stats.Owners[0].Owner)
+	assert.Equal(t, 1, stats.Owners[0].Completed)
+	assert.Equal(t, 1, stats.Owners[0].CycleTime.Count)
+}
+
+func TestComputeStats_Window(t *testing.T) {
+	created := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	task := Task{ID: "aaaa", Status: StatusTodo, Created: created}
+	task.SetStatus(StatusDone, "", created.Add(time.Hour))
+
+	// Work outside the window is not counted
+	stats := ComputeStats([]Task{task}, created.AddDate(0, 0, 1), created.AddDate(0, 0, 2))
+	assert.Equal(t, 0, stats.Created)
+	assert.Equal(t, 0, stats.Completed)
+	assert.Equal(t, Distribution{}, stats.LeadTime)
+	assert.Len(t, stats.Days, 2)
+	assert.Empty(t, stats.Owners)
+}
+
+func TestValueAt(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	bob := "bob"
+	task := Task{Status: StatusTodo, Created: start}
+	task.SetStatus(StatusInProgress, "", start.Add(time.Hour))
+	task.SetOwner(&bob, "", start.Add(2*time.Hour))
+
+	assert.Equal(t, StatusTodo, task.valueAt(HistoryStatus, start))
+	assert.Equal(t, StatusInProgress, task.valueAt(HistoryStatus, start.Add(time.Hour)))
+	assert.Equal(t, "", task.valueAt(HistoryOwner, start.Add(time.Hour)))
+	assert.Equal(t, "bob", task.valueAt(HistoryOwner, start.Add(3*time.Hour)))
+
+	// Without history the current value holds throughout
+	plain := Task{Status: StatusDone, Owner: &bob}
+	assert.Equal(t, StatusDone, plain.valueAt(HistoryStatus, start))
+	assert.Equal(t, "bob", plain.valueAt(HistoryOwner, start))
+}
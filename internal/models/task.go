@@ -5,28 +5,64 @@ import (
 	"time"
 )
 
-// Note represents an observation or progress update on a task
+// Note represents an observation or progress update on a task.
+// ID is unique within the task and never reused while the note exists.
 type Note struct {
-	Content   string    `json:"content"`
-	Timestamp time.Time `json:"timestamp"`
+	ID        int        `json:"id"`
+	Content   string     `json:"content"`
+	Kind      string     `json:"kind,omitempty"`
+	Author    string     `json:"author,omitempty"`
+	Timestamp time.Time  `json:"timestamp"`
+	Edited    *time.Time `json:"edited,omitempty"`
 }
 
+// ChecklistItem is one acceptance criterion on a task
+type ChecklistItem struct {
+	Text   string     `json:"text"`
+	Done   bool       `json:"done"`
+	DoneAt *time.Time `json:"doneAt,omitempty"`
+}
+
+// Verification records one run of a task's verification command
+type Verification struct {
+	Command    string    `json:"command"`
+	ExitCode   int       `json:"exitCode"`
+	Passed     bool      `json:"passed"`
+	TimedOut   bool      `json:"timedOut,omitempty"`
+	Output     string    `json:"output,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// MaxVerifications is how many verification records are kept per task
+const MaxVerifications = 10
+
 // Task represents a task in the work queue
 type Task struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description,omitempty"`
-	Action      string    `json:"action,omitempty"`
-	Verify      string    `json:"verify,omitempty"`
-	Result      string    `json:"result,omitempty"`
-	Outcome     string    `json:"outcome,omitempty"`
-	Parent      *string   `json:"parent"`
-	Status      string    `json:"status"`
-	BlockedBy   []string  `json:"blockedBy,omitempty"`
-	Owner       *string   `json:"owner,omitempty"`
-	Notes       []Note    `json:"notes,omitempty"`
-	Created     time.Time `json:"created"`
-	Updated     time.Time `json:"updated"`
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description,omitempty"`
+	Action        string          `json:"action,omitempty"`
+	Verify        string          `json:"verify,omitempty"`
+	Result        string          `json:"result,omitempty"`
+	Outcome       string          `json:"outcome,omitempty"`
+	VerifyCmd     string          `json:"verifyCmd,omitempty"`
+	Parent        *string         `json:"parent"`
+	Status        string          `json:"status"`
+	Rank          *float64        `json:"rank,omitempty"`
+	BlockedBy     []string        `json:"blockedBy,omitempty"`
+	Links         []Link          `json:"links,omitempty"`
+	Owner         *string         `json:"owner,omitempty"`
+	Notes         []Note          `json:"notes,omitempty"`
+	NextNoteID    int             `json:"nextNoteId,omitempty"`
+	Fields        map[string]any  `json:"fields,omitempty"`
+	Checklist     []ChecklistItem `json:"checklist,omitempty"`
+	Recurrence    *Recurrence     `json:"recurrence,omitempty"`
+	ClonedFrom    string          `json:"clonedFrom,omitempty"`
+	Verifications []Verification  `json:"verifications,omitempty"`
+	History       []HistoryEntry  `json:"history,omitempty"`
+	Created       time.Time       `json:"created"`
+	Updated       time.Time       `json:"updated"`
 }
 
 // Valid status values
@@ -34,6 +70,7 @@ const (
 	StatusTodo       = "todo"
 	StatusInProgress = "in-progress"
 	StatusDone       = "done"
+	StatusCancelled  = "cancelled"
 )
 
 // HasStructuredFields returns true when all three required structured fields are non-empty.
@@ -42,9 +79,41 @@ func (t *Task) HasStructuredFields() bool {
 	return t.Action != "" && t.Verify != "" && t.Result != ""
 }
 
+// LatestVerification returns the most recent verification record, or nil if the task was never verified
+func (t *Task) LatestVerification() *Verification {
+	if len(t.Verifications) == 0 {
+		return nil
+	}
+	return &t.Verifications[len(t.Verifications)-1]
+}
+
+// AddVerification appends a verification record, keeping only the most recent MaxVerifications
+func (t *Task) AddVerification(v Verification) {
+	t.Verifications = append(t.Verifications, v)
+	if len(t.Verifications) > MaxVerifications {
+		t.Verifications = t.Verifications[len(t.Verifications)-MaxVerifications:]
+	}
+}
+
+// ChecklistProgress returns how many checklist items are ticked and how many there are
+func (t *Task) ChecklistProgress() (done, total int) {
+	for i := range t.Checklist {
+		if t.Checklist[i].Done {
+			done++
+		}
+	}
+	return done, len(t.Checklist)
+}
+
 // IsValidStatus checks if a status value is valid
 func IsValidStatus(status string) bool {
-	return status == StatusTodo || status == StatusInProgress || status == StatusDone
+	return status == StatusTodo || status == StatusInProgress || status == StatusDone || status == StatusCancelled
+}
+
+// IsClosed reports whether a status ends a task's work: done or cancelled.
+// Closed tasks no longer block others or hold their parent open.
+func IsClosed(status string) bool {
+	return status == StatusDone || status == StatusCancelled
 }
 
 // IsValidTaskID checks if an ID is a valid 4-character lowercase alphabetic string
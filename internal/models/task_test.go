@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,6 +12,7 @@ func TestIsValidStatus(t *testing.T) {
 	assert.True(t, IsValidStatus(StatusTodo))
 	assert.True(t, IsValidStatus(StatusInProgress))
 	assert.True(t, IsValidStatus(StatusDone))
+	assert.True(t, IsValidStatus(StatusCancelled))
 
 	// Invalid statuses
 	assert.False(t, IsValidStatus(""))
@@ -20,6 +22,13 @@ func TestIsValidStatus(t *testing.T) {
 	assert.False(t, IsValidStatus("in_progress")) // wrong format
 }
 
+func TestIsClosed(t *testing.T) {
+	assert.True(t, IsClosed(StatusDone))
+	assert.True(t, IsClosed(StatusCancelled))
+	assert.False(t, IsClosed(StatusTodo))
+	assert.False(t, IsClosed(StatusInProgress))
+}
+
 func TestHasStructuredFields(t *testing.T) {
 	// All three set → true
 	task := &Task{Action: "do X", Verify: "check Y", Result: "report Z"}
@@ -41,3 +50,29 @@ func TestHasStructuredFields(t *testing.T) {
 	task = &Task{}
 	assert.False(t, task.HasStructuredFields())
 }
+
+func TestVerifications(t *testing.T) {
+	task := &Task{}
+	assert.Nil(t, task.LatestVerification())
+
+	for i := 0; i < MaxVerifications+3; i++ {
+		task.AddVerification(Verification{Command: fmt.Sprintf("run %d", i), ExitCode: i})
+	}
+
+	// Only the most recent records are kept
+	assert.Len(t, task.Verifications, MaxVerifications)
+	assert.Equal(t, "run 3", task.Verifications[0].Command)
+	assert.Equal(t, MaxVerifications+2, task.LatestVerification().ExitCode)
+}
+
+func TestChecklistProgress(t *testing.T) {
+	task := &Task{}
+	done, total := task.ChecklistProgress()
+	assert.Equal(t, 0, done)
+	assert.Equal(t, 0, total)
+
+	task.Checklist = []ChecklistItem{{Text: "a", Done: true}, {Text: "b"}, {Text: "c", Done: true}}
+	done, total = task.ChecklistProgress()
+	assert.Equal(t, 2, done)
+	assert.Equal(t, 3, total)
+}
@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"slices"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+)
+
+// CloneOptions controls what CloneTask copies
+type CloneOptions struct {
+	// Recursive clones the task's descendants too
+	Recursive bool
+	// Parent is the parent of the copy; nil keeps the original's parent
+	Parent *string
+	// ResetStatus makes every copy todo with an unticked checklist
+	ResetStatus bool
+	// KeepOwners, KeepNotes and KeepOutcomes copy what is otherwise dropped
+	KeepOwners   bool
+	KeepNotes    bool
+	KeepOutcomes bool
+}
+
+// CloneResult reports the tasks created by CloneTask
+type CloneResult struct {
+	// Task is the copy of the cloned task
+	Task *models.Task `json:"task"`
+	// IDs maps each original task ID to the ID of its copy
+	IDs map[string]string `json:"ids"`
+	// Created lists the new task IDs in creation order
+	Created []string `json:"created"`
+}
+
+// CloneTask copies a task, and with opts.Recursive its subtree, under fresh
+// IDs in one transaction. Copies keep the name, description, structured
+// fields, verifyCmd, custom fields, checklist and status of the original, and
+// the dependencies among the cloned tasks. Dependencies on tasks outside the
+// clone, links, history and verifications are not copied. Each copy records
+// the ID of its original in ClonedFrom.
+func (s *Storage) CloneTask(id string, opts CloneOptions) (*CloneResult, error) {
+	var result *CloneResult
+	err := s.Transaction(func(tx *Storage) error {
+		var err error
+		result, err = tx.cloneTask(id, opts, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Storage) cloneTask(id string, opts CloneOptions, now time.Time) (*CloneResult, error) {
+	tasks, err := s.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	root := findTask(tasks, id)
+	if root == nil {
+		return nil, models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+	}
+
+	parent := root.Parent
+	if opts.Parent != nil {
+		parent = opts.Parent
+	}
+	if parent != nil {
+		p := findTask(tasks, *parent)
+		if p == nil {
+			return nil, models.Errorf(models.CodeNotFound, "parent task %s not found", *parent).With("id", *parent)
+		}
+		if models.IsClosed(p.Status) {
+			return nil, models.Errorf(models.CodeConflict, "cannot add child to %s task", p.Status)
+		}
+	}
+
+	// Originals in tree order, so parents are copied before their children
+	originals := []*models.Task{root}
+	if opts.Recursive {
+		for i := 0; i < len(originals); i++ {
+			var children []models.Task
+			for j := range tasks {
+				if tasks[j].Parent != nil && *tasks[j].Parent == originals[i].ID {
+					children = append(children, tasks[j])
+				}
+			}
+			models.SortByRank(children)
+			for j := range children {
+				originals = append(originals, findTask(tasks, children[j].ID))
+			}
+		}
+	}
+
+	// Draw every ID up front so dependencies can point at later copies
+	result := &CloneResult{IDs: make(map[string]string, len(originals))}
+	drawn := make(map[string]bool, len(originals))
+	for _, orig := range originals {
+		for {
+			newID, err := s.GenerateTaskID()
+			if err != nil {
+				return nil, err
+			}
+			if !drawn[newID] {
+				drawn[newID] = true
+				result.IDs[orig.ID] = newID
+				break
+			}
+		}
+	}
+
+	actor := CurrentActor()
+	for i, orig := range originals {
+		// Space out timestamps so copies keep the order of the originals
+		created := now.Add(time.Duration(i) * time.Microsecond)
+		clone := &models.Task{
+			ID:          result.IDs[orig.ID],
+			Name:        orig.Name,
+			Description: orig.Description,
+			Action:      orig.Action,
+			Verify:      orig.Verify,
+			Result:      orig.Result,
+			VerifyCmd:   orig.VerifyCmd,
+			Status:      orig.Status,
+			Fields:      copyFields(orig.Fields),
+			ClonedFrom:  orig.ID,
+			Created:     created,
+			Updated:     created,
+		}
+		if i == 0 {
+			clone.Parent = parent
+		} else {
+			parentID := result.IDs[*orig.Parent]
+			clone.Parent = &parentID
+			clone.Rank = orig.Rank
+		}
+		if opts.ResetStatus {
+			clone.Status = models.StatusTodo
+		}
+		for _, item := range orig.Checklist {
+			if opts.ResetStatus {
+				item = models.ChecklistItem{Text: item.Text}
+			}
+			clone.Checklist = append(clone.Checklist, item)
+		}
+		for _, blockerID := range orig.BlockedBy {
+			if newID, ok := result.IDs[blockerID]; ok {
+				clone.BlockedBy = append(clone.BlockedBy, newID)
+			}
+		}
+		if opts.KeepOwners && orig.Owner != nil {
+			owner := *orig.Owner
+			clone.SetOwner(&owner, actor, created)
+		}
+		if opts.KeepNotes {
+			clone.Notes = slices.Clone(orig.Notes)
+			clone.NextNoteID = orig.NextNoteID
+		}
+		if opts.KeepOutcomes {
+			clone.Outcome = orig.Outcome
+		}
+
+		if err := s.SaveTask(clone); err != nil {
+			return nil, err
+		}
+		result.Created = append(result.Created, clone.ID)
+	}
+
+	if result.Task, err = s.LoadTask(result.IDs[id]); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCloneStore creates root aaaa with children bbbb and cccc, where cccc is
+// blocked by its sibling bbbb and by the outside task dddd
+func newCloneStore(t *testing.T) *Storage {
+	t.Helper()
+	store := NewStorageAt(t.TempDir())
+	require.NoError(t, store.Init())
+	saveSiblings(t, store, nil, "aaaa", "dddd")
+	parent := "aaaa"
+	saveSiblings(t, store, &parent, "bbbb", "cccc")
+
+	task, err := store.LoadTask("aaaa")
+	require.NoError(t, err)
+	owner := "alice"
+	task.Owner = &owner
+	task.Action = "Ship it"
+	task.Fields = map[string]any{"tag": "release"}
+	task.Checklist = []models.ChecklistItem{{Text: "tag", Done: true}}
+	task.Outcome = "Shipped"
+	task.AddNote(models.Note{Content: "went fine"})
+	require.NoError(t, store.SaveTask(task))
+
+	child, err := store.LoadTask("cccc")
+	require.NoError(t, err)
+	child.BlockedBy = []string{"bbbb", "dddd"}
+	child.Status = models.StatusDone
+	require.NoError(t, store.SaveTask(child))
+	return store
+}
+
+func TestCloneTask_Recursive(t *testing.T) {
+	store := newCloneStore(t)
+
+	result, err := store.CloneTask("aaaa", CloneOptions{Recursive: true})
+	require.NoError(t, err)
+	require.Len(t, result.Created, 3)
+	root := result.Task
+	assert.Equal(t, result.IDs["aaaa"], root.ID)
+	assert.NotContains(t, []string{"aaaa", "bbbb", "cccc", "dddd"}, root.ID)
+	assert.Nil(t, root.Parent)
+	assert.Equal(t, "aaaa", root.ClonedFrom)
+
+	// Definitions are copied; owners, notes and outcomes are not
+	assert.Equal(t, "Ship it", root.Action)
+	assert.Equal(t, map[string]any{"tag": "release"}, root.Fields)
+	assert.Equal(t, []models.ChecklistItem{{Text: "tag", Done: true}}, root.Checklist)
+	assert.Nil(t, root.Owner)
+	assert.Empty(t, root.Notes)
+	assert.Empty(t, root.Outcome)
+
+	// Children keep their order, status and dependencies among the copies
+	assert.Equal(t, []string{result.IDs["bbbb"], result.IDs["cccc"]}, siblingOrder(t, store, root.ID))
+	child, err := store.LoadTask(result.IDs["cccc"])
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusDone, child.Status)
+	assert.Equal(t, []string{result.IDs["bbbb"]}, child.BlockedBy)
+
+	// The originals are untouched
+	orig, err := store.LoadTask("cccc")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bbbb", "dddd"}, orig.BlockedBy)
+}
+
+func TestCloneTask_Options(t *testing.T) {
+	store := newCloneStore(t)
+	parent := "dddd"
+
+	result, err := store.CloneTask("aaaa", CloneOptions{
+		Parent:       &parent,
+		ResetStatus:  true,
+		KeepOwners:   true,
+		KeepNotes:    true,
+		KeepOutcomes: true,
+	})
+	require.NoError(t, err)
+	assert.Len(t, result.Created, 1)
+	root := result.Task
+	assert.Equal(t, "dddd", *root.Parent)
+	assert.Equal(t, []models.ChecklistItem{{Text: "tag"}}, root.Checklist)
+	assert.Equal(t, "alice", *root.Owner)
+	require.Len(t, root.Notes, 1)
+	assert.Equal(t, "went fine", root.Notes[0].Content)
+	assert.Equal(t, "Shipped", root.Outcome)
+
+	result, err = store.CloneTask("cccc", CloneOptions{ResetStatus: true})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusTodo, result.Task.Status)
+	assert.Equal(t, "aaaa", *result.Task.Parent)
+	assert.Empty(t, result.Task.BlockedBy)
+}
+
+func TestCloneTask_Errors(t *testing.T) {
+	store := newCloneStore(t)
+
+	_, err := store.CloneTask("zzzz", CloneOptions{})
+	assert.ErrorContains(t, err, "task zzzz not found")
+
+	missing := "zzzz"
+	_, err = store.CloneTask("aaaa", CloneOptions{Parent: &missing})
+	assert.ErrorContains(t, err, "parent task zzzz not found")
+
+	task, err := store.LoadTask("dddd")
+	require.NoError(t, err)
+	task.Status = models.StatusCancelled
+	task.Updated = time.Now()
+	require.NoError(t, store.SaveTask(task))
+	closed := "dddd"
+	_, err = store.CloneTask("aaaa", CloneOptions{Parent: &closed})
+	assert.ErrorContains(t, err, "cannot add child to cancelled task")
+
+	// Failed clones create nothing
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 4)
+}
+
+func TestCloneTask_StatsUnchanged(t *testing.T) {
+	store := NewStorageAt(t.TempDir())
+	require.NoError(t, store.Init())
+	saveSiblings(t, store, nil, "aaaa")
+	parent := "aaaa"
+	saveSiblings(t, store, &parent, "bbbb", "cccc")
+
+	// Close the subtree from the leaves up, with one task predating history
+	for _, id := range []string{"bbbb", "aaaa"} {
+		task, err := store.LoadTask(id)
+		require.NoError(t, err)
+		task.SetStatus(models.StatusInProgress, "", time.Now())
+		task.SetStatus(models.StatusDone, "", time.Now())
+		require.NoError(t, store.SaveTask(task))
+	}
+	legacy, err := store.LoadTask("cccc")
+	require.NoError(t, err)
+	legacy.Status = models.StatusDone
+	require.NoError(t, store.SaveTask(legacy))
+
+	since, until := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	before := models.ComputeStats(tasks, since, until)
+	require.Equal(t, 3, before.Completed)
+
+	_, err = store.CloneTask("aaaa", CloneOptions{Recursive: true})
+	require.NoError(t, err)
+	tasks, err = store.LoadAll()
+	require.NoError(t, err)
+	require.Len(t, tasks, 6)
+	assert.Equal(t, before, models.ComputeStats(tasks, since, until))
+}
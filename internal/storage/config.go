@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFile is the optional project configuration inside the .clipm directory
+const ConfigFile = "config"
+
+// Config holds per-project settings read from .clipm/config (YAML)
+type Config struct {
+	Fields models.FieldSchema `yaml:"fields,omitempty"`
+
+	// RequireVerification makes "status done" refuse tasks with a VerifyCmd
+	// unless their latest verification passed
+	RequireVerification bool `yaml:"requireVerification,omitempty"`
+
+	// RequireChecklist makes "status done" refuse tasks with unticked checklist items
+	RequireChecklist bool `yaml:"requireChecklist,omitempty"`
+}
+
+// LoadConfig reads the project configuration. A missing file yields an empty config.
+func (s *Storage) LoadConfig() (*Config, error) {
+	configPath := filepath.Join(s.rootDir, ClipmDir, ConfigFile)
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if err := cfg.Fields.Check(); err != nil {
+		return nil, models.Errorf(models.CodeInvalidArgument, "invalid field schema in config: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// ValidateFields checks a task's custom fields against the project schema
+func (s *Storage) ValidateFields(task *models.Task) error {
+	cfg, err := s.LoadConfig()
+	if err != nil {
+		return err
+	}
+	return cfg.Fields.Validate(task.Fields)
+}
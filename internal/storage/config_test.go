@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	path := filepath.Join(dir, ClipmDir, ConfigFile)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestLoadConfig_Missing(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	cfg, err := store.LoadConfig()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Fields)
+}
+
+func TestLoadConfig_FieldSchema(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	writeConfig(t, dir, `fields:
+  ticket:
+    type: string
+    required: true
+  risk:
+    type: enum
+    values: [low, medium, high]
+`)
+
+	cfg, err := store.LoadConfig()
+	require.NoError(t, err)
+	require.Len(t, cfg.Fields, 2)
+	assert.True(t, cfg.Fields["ticket"].Required)
+	assert.Equal(t, models.FieldTypeEnum, cfg.Fields["risk"].Type)
+	assert.Equal(t, []string{"low", "medium", "high"}, cfg.Fields["risk"].Values)
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	writeConfig(t, dir, "fields:\n  risk:\n    type: enum\n")
+	_, err := store.LoadConfig()
+	assert.ErrorContains(t, err, "invalid field schema")
+
+	writeConfig(t, dir, "feilds: {}\n")
+	_, err = store.LoadConfig()
+	assert.ErrorContains(t, err, "failed to parse config file")
+}
+
+func TestValidateFields(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	task := &models.Task{ID: "aaaa", Fields: map[string]any{"anything": "goes"}}
+	assert.NoError(t, store.ValidateFields(task))
+
+	writeConfig(t, dir, "fields:\n  ticket:\n    type: string\n    required: true\n")
+	assert.ErrorContains(t, store.ValidateFields(task), "unknown field")
+
+	task.Fields = map[string]any{"ticket": "ENG-1"}
+	assert.NoError(t, store.ValidateFields(task))
+}
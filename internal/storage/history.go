@@ -0,0 +1,12 @@
+package storage
+
+import "os"
+
+// AgentEnv names the environment variable that identifies the agent running clipm.
+// Its value is recorded as the actor of status, owner and parent changes.
+const AgentEnv = "CLIPM_AGENT"
+
+// CurrentActor returns the agent name from CLIPM_AGENT, or "" when unset
+func CurrentActor() string {
+	return os.Getenv(AgentEnv)
+}
@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+)
+
+// LinkResult reports the changes made by AddLink
+type LinkResult struct {
+	// Task is the source task carrying the new link
+	Task *models.Task `json:"task"`
+	// Closed is the task cancelled by a duplicates or supersedes link
+	Closed string `json:"closed,omitempty"`
+	// MovedNotes counts the notes moved from a duplicate to its original
+	MovedNotes int `json:"movedNotes,omitempty"`
+	// Repointed lists tasks whose blockedBy moved from the closed task to its replacement
+	Repointed []string `json:"repointed,omitempty"`
+}
+
+// AddLink links sourceID to targetID with the given type in one transaction.
+//
+// "source duplicates target" cancels the source and moves its notes to the target;
+// "source supersedes target" cancels the target. In both cases tasks blocked by the
+// cancelled task are re-pointed to the task that replaces it.
+func (s *Storage) AddLink(sourceID, linkType, targetID string) (*LinkResult, error) {
+	if !models.IsValidLinkType(linkType) {
+		return nil, models.Errorf(models.CodeInvalidArgument, "invalid link type %q. Must be one of: relates-to, duplicates, supersedes, follows-up", linkType)
+	}
+	if sourceID == targetID {
+		return nil, models.Errorf(models.CodeInvalidArgument, "a task cannot link to itself")
+	}
+
+	var result *LinkResult
+	err := s.Transaction(func(tx *Storage) error {
+		var err error
+		result, err = tx.addLink(sourceID, linkType, targetID, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Storage) addLink(sourceID, linkType, targetID string, now time.Time) (*LinkResult, error) {
+	source, err := s.loadLinkTask(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.loadLinkTask(targetID)
+	if err != nil {
+		return nil, err
+	}
+	if source.HasLink(linkType, targetID) {
+		return nil, models.Errorf(models.CodeConflict, "task %s already %s %s", sourceID, linkType, targetID)
+	}
+
+	source.Links = append(source.Links, models.Link{Type: linkType, Target: targetID})
+	source.Updated = now
+	result := &LinkResult{}
+
+	// closed is replaced by replacement
+	var closed, replacement *models.Task
+	switch linkType {
+	case models.LinkDuplicates:
+		closed, replacement = source, target
+		if len(source.Notes) > 0 {
+			// Moved notes get new IDs on the target and keep their original timestamps
+			for _, note := range source.Notes {
+				note.Content = fmt.Sprintf("(from %s) %s", source.ID, note.Content)
+				target.AddNote(note)
+			}
+			result.MovedNotes = len(source.Notes)
+			source.Notes = nil
+			target.Updated = now
+		}
+	case models.LinkSupersedes:
+		closed, replacement = target, source
+	}
+
+	if closed != nil && !models.IsClosed(closed.Status) {
+		hasUndone, err := s.HasUndoneChildren(closed.ID)
+		if err != nil {
+			return nil, err
+		}
+		if hasUndone {
+			return nil, models.Errorf(models.CodeHasUndoneChildren, "cannot close task %s: has undone children", closed.ID).With("id", closed.ID)
+		}
+		// Cancelled rather than done: the work was never done as this task,
+		// so it skips the done gates, stats and recurrence
+		closed.SetStatus(models.StatusCancelled, CurrentActor(), now)
+		if closed.Outcome == "" {
+			if linkType == models.LinkDuplicates {
+				closed.Outcome = fmt.Sprintf("Duplicate of %s", replacement.ID)
+			} else {
+				closed.Outcome = fmt.Sprintf("Superseded by %s", replacement.ID)
+			}
+		}
+		closed.Updated = now
+		result.Closed = closed.ID
+	}
+
+	if err := s.SaveTask(source); err != nil {
+		return nil, err
+	}
+	if err := s.SaveTask(target); err != nil {
+		return nil, err
+	}
+
+	if result.Closed != "" {
+		result.Repointed, err = s.repointBlockers(closed.ID, replacement, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result.Task, err = s.LoadTask(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Storage) loadLinkTask(id string) (*models.Task, error) {
+	task, err := s.LoadTask(id)
+	if err != nil {
+		if err == ErrTaskNotFound {
+			return nil, models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// repointBlockers makes tasks blocked by fromID wait for replacement instead.
+// The dependency is dropped where it would be redundant, self-referential or
+// cyclic, or when the replacement is already done.
+func (s *Storage) repointBlockers(fromID string, replacement *models.Task, now time.Time) ([]string, error) {
+	tasks, err := s.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var repointed []string
+	for i := range tasks {
+		task := &tasks[i]
+		idx := -1
+		for j, id := range task.BlockedBy {
+			if id == fromID {
+				idx = j
+				break
+			}
+		}
+		if idx < 0 {
+			continue
+		}
+
+		keep := !models.IsClosed(replacement.Status) && task.ID != replacement.ID
+		for _, id := range task.BlockedBy {
+			if id == replacement.ID {
+				keep = false
+			}
+		}
+		if keep {
+			hasCycle, err := s.WouldCreateCycle(replacement.ID, task.ID)
+			if err != nil {
+				return nil, err
+			}
+			keep = !hasCycle
+		}
+
+		if keep {
+			task.BlockedBy[idx] = replacement.ID
+			repointed = append(repointed, task.ID)
+		} else {
+			task.BlockedBy = append(task.BlockedBy[:idx], task.BlockedBy[idx+1:]...)
+		}
+		task.Updated = now
+		if err := s.SaveTask(task); err != nil {
+			return nil, err
+		}
+	}
+	return repointed, nil
+}
+
+// RemoveLink removes the links between two tasks, optionally only those of
+// linkType. Links stored on either task are removed. Closed tasks are not reopened.
+func (s *Storage) RemoveLink(aID, bID, linkType string) (int, error) {
+	removed := 0
+	err := s.Transaction(func(tx *Storage) error {
+		for _, pair := range [][2]string{{aID, bID}, {bID, aID}} {
+			task, err := tx.loadLinkTask(pair[0])
+			if err != nil {
+				return err
+			}
+			links := task.Links[:0]
+			for _, l := range task.Links {
+				if l.Target == pair[1] && (linkType == "" || l.Type == linkType) {
+					removed++
+					continue
+				}
+				links = append(links, l)
+			}
+			if len(links) == len(task.Links) {
+				continue
+			}
+			if len(links) == 0 {
+				links = nil
+			}
+			task.Links = links
+			task.Updated = time.Now()
+			if err := tx.SaveTask(task); err != nil {
+				return err
+			}
+		}
+		if removed == 0 {
+			return models.Errorf(models.CodeNotFound, "no link between %s and %s", aID, bID)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// RemoveLinksTo removes every link that targets taskID
+func (s *Storage) RemoveLinksTo(taskID string) error {
+	store, err := s.loadStore()
+	if err != nil {
+		return err
+	}
+
+	modified := false
+	for i := range store.Tasks {
+		var links []models.Link
+		for _, l := range store.Tasks[i].Links {
+			if l.Target != taskID {
+				links = append(links, l)
+			} else {
+				modified = true
+			}
+		}
+		store.Tasks[i].Links = links
+	}
+
+	if modified {
+		return s.saveStore(store)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddLink_RelatesTo(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+	require.NoError(t, store.SaveTask(newTestTask("aaaa")))
+	require.NoError(t, store.SaveTask(newTestTask("bbbb")))
+
+	result, err := store.AddLink("aaaa", models.LinkRelatesTo, "bbbb")
+	require.NoError(t, err)
+	assert.Empty(t, result.Closed)
+	assert.Equal(t, []models.Link{{Type: models.LinkRelatesTo, Target: "bbbb"}}, result.Task.Links)
+
+	b, err := store.LoadTask("bbbb")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusTodo, b.Status)
+
+	_, err = store.AddLink("aaaa", models.LinkRelatesTo, "bbbb")
+	assert.ErrorContains(t, err, "already")
+	_, err = store.AddLink("aaaa", "blocks", "bbbb")
+	assert.ErrorContains(t, err, "invalid link type")
+	_, err = store.AddLink("aaaa", models.LinkRelatesTo, "aaaa")
+	assert.ErrorContains(t, err, "itself")
+	_, err = store.AddLink("aaaa", models.LinkRelatesTo, "zzzz")
+	assert.ErrorContains(t, err, "task zzzz not found")
+}
+
+func TestAddLink_Duplicates(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	now := time.Now()
+	dup := newTestTask("aaaa")
+	dup.Notes = []models.Note{{Content: "seen on staging", Timestamp: now.Add(-time.Hour)}}
+	require.NoError(t, store.SaveTask(dup))
+	orig := newTestTask("bbbb")
+	orig.Notes = []models.Note{{Content: "root cause found", Timestamp: now}}
+	require.NoError(t, store.SaveTask(orig))
+	dependent := newTestTask("cccc")
+	dependent.BlockedBy = []string{"aaaa"}
+	require.NoError(t, store.SaveTask(dependent))
+
+	result, err := store.AddLink("aaaa", models.LinkDuplicates, "bbbb")
+	require.NoError(t, err)
+	assert.Equal(t, "aaaa", result.Closed)
+	assert.Equal(t, 1, result.MovedNotes)
+	assert.Equal(t, []string{"cccc"}, result.Repointed)
+
+	dup2, err := store.LoadTask("aaaa")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, dup2.Status)
+	assert.Equal(t, "Duplicate of bbbb", dup2.Outcome)
+	assert.Empty(t, dup2.Notes)
+
+	orig2, err := store.LoadTask("bbbb")
+	require.NoError(t, err)
+	require.Len(t, orig2.Notes, 2)
+	assert.Equal(t, "root cause found", orig2.Notes[0].Content)
+	assert.Equal(t, "(from aaaa) seen on staging", orig2.Notes[1].Content)
+	assert.Equal(t, 2, orig2.Notes[1].ID)
+
+	dep2, err := store.LoadTask("cccc")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bbbb"}, dep2.BlockedBy)
+}
+
+func TestAddLink_Supersedes(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	require.NoError(t, store.SaveTask(newTestTask("aaaa")))
+	old := newTestTask("bbbb")
+	old.Outcome = "kept"
+	require.NoError(t, store.SaveTask(old))
+
+	// Already waiting on the replacement: the old dependency is just dropped
+	both := newTestTask("cccc")
+	both.BlockedBy = []string{"bbbb", "aaaa"}
+	require.NoError(t, store.SaveTask(both))
+
+	// The replacement itself was blocked by the old task
+	a, err := store.LoadTask("aaaa")
+	require.NoError(t, err)
+	a.BlockedBy = []string{"bbbb"}
+	require.NoError(t, store.SaveTask(a))
+
+	result, err := store.AddLink("aaaa", models.LinkSupersedes, "bbbb")
+	require.NoError(t, err)
+	assert.Equal(t, "bbbb", result.Closed)
+	assert.Empty(t, result.Repointed)
+	assert.Empty(t, result.Task.BlockedBy)
+
+	b, err := store.LoadTask("bbbb")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, b.Status)
+	assert.Equal(t, "kept", b.Outcome)
+
+	c, err := store.LoadTask("cccc")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa"}, c.BlockedBy)
+}
+
+func TestAddLink_UndoneChildren(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	require.NoError(t, store.SaveTask(newTestTask("aaaa")))
+	require.NoError(t, store.SaveTask(newTestTask("bbbb")))
+	parent := "bbbb"
+	child := newTestTask("cccc")
+	child.Parent = &parent
+	require.NoError(t, store.SaveTask(child))
+
+	_, err := store.AddLink("aaaa", models.LinkSupersedes, "bbbb")
+	assert.ErrorContains(t, err, "has undone children")
+
+	a, err := store.LoadTask("aaaa")
+	require.NoError(t, err)
+	assert.Empty(t, a.Links, "failed link must not be saved")
+}
+
+func TestRemoveLink(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+	require.NoError(t, store.SaveTask(newTestTask("aaaa")))
+	require.NoError(t, store.SaveTask(newTestTask("bbbb")))
+
+	_, err := store.AddLink("aaaa", models.LinkRelatesTo, "bbbb")
+	require.NoError(t, err)
+	_, err = store.AddLink("aaaa", models.LinkFollowsUp, "bbbb")
+	require.NoError(t, err)
+
+	removed, err := store.RemoveLink("aaaa", "bbbb", models.LinkFollowsUp)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	// Either direction works
+	removed, err = store.RemoveLink("bbbb", "aaaa", "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	a, err := store.LoadTask("aaaa")
+	require.NoError(t, err)
+	assert.Nil(t, a.Links)
+
+	_, err = store.RemoveLink("aaaa", "bbbb", "")
+	assert.ErrorContains(t, err, "no link")
+}
+
+func TestRemoveLinksTo(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+	a := newTestTask("aaaa")
+	a.Links = []models.Link{{Type: models.LinkRelatesTo, Target: "bbbb"}, {Type: models.LinkRelatesTo, Target: "cccc"}}
+	require.NoError(t, store.SaveTask(a))
+
+	require.NoError(t, store.RemoveLinksTo("bbbb"))
+	a, err := store.LoadTask("aaaa")
+	require.NoError(t, err)
+	assert.Equal(t, []models.Link{{Type: models.LinkRelatesTo, Target: "cccc"}}, a.Links)
+}
+
+func TestAddLink_RecurringDuplicate(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	now := time.Now()
+	dup := newTestTask("aaaa")
+	rule, err := models.ParseRecurrence("1d", now)
+	require.NoError(t, err)
+	dup.Recurrence = rule
+	require.NoError(t, store.SaveTask(dup))
+	require.NoError(t, store.SaveTask(newTestTask("bbbb")))
+
+	_, err = store.AddLink("aaaa", models.LinkDuplicates, "bbbb")
+	require.NoError(t, err)
+	closed, err := store.LoadTask("aaaa")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, closed.Status)
+
+	// A cancelled duplicate never comes back as a new instance
+	spawned, err := store.RunRecurrences(now.Add(48 * time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, spawned)
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
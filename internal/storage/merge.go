@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+)
+
+// MergeResult reports the changes made by MergeTasks
+type MergeResult struct {
+	// Task is the destination task
+	Task *models.Task `json:"task"`
+	// Cancelled is the source task, now cancelled
+	Cancelled string `json:"cancelled"`
+	// MovedChildren lists the source's children now under the destination
+	MovedChildren []string `json:"movedChildren,omitempty"`
+	// MovedNotes counts the notes moved from the source
+	MovedNotes int `json:"movedNotes,omitempty"`
+	// Blockers lists the source's blockers the destination now waits on
+	Blockers []string `json:"blockers,omitempty"`
+	// Repointed lists tasks whose blockedBy moved from the source to the destination
+	Repointed []string `json:"repointed,omitempty"`
+}
+
+// MergeTasks folds srcID into dstID in one transaction. The source's
+// children, notes, blockers and dependents move to the destination, and the
+// source is cancelled with a duplicates link to the destination.
+func (s *Storage) MergeTasks(srcID, dstID string) (*MergeResult, error) {
+	if srcID == dstID {
+		return nil, models.Errorf(models.CodeInvalidArgument, "cannot merge a task into itself")
+	}
+
+	var result *MergeResult
+	err := s.Transaction(func(tx *Storage) error {
+		var err error
+		result, err = tx.mergeTasks(srcID, dstID, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Storage) mergeTasks(srcID, dstID string, now time.Time) (*MergeResult, error) {
+	src, err := s.loadLinkTask(srcID)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := s.loadLinkTask(dstID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range []*models.Task{src, dst} {
+		if models.IsClosed(t.Status) {
+			return nil, models.Errorf(models.CodeConflict, "cannot merge %s task %s", t.Status, t.ID).With("id", t.ID)
+		}
+	}
+
+	tasks, err := s.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	for p := dst.Parent; p != nil; {
+		if *p == srcID {
+			return nil, models.Errorf(models.CodeConflict, "cannot merge task %s into its descendant %s", srcID, dstID)
+		}
+		parent := findTask(tasks, *p)
+		if parent == nil {
+			break
+		}
+		p = parent.Parent
+	}
+
+	actor := CurrentActor()
+	result := &MergeResult{Cancelled: srcID}
+
+	// Children join the destination's children
+	for i := range tasks {
+		child := &tasks[i]
+		if child.Parent == nil || *child.Parent != srcID {
+			continue
+		}
+		child.SetParent(&dst.ID, actor, now)
+		child.Updated = now
+		if err := s.SaveTask(child); err != nil {
+			return nil, err
+		}
+		result.MovedChildren = append(result.MovedChildren, child.ID)
+	}
+
+	// Notes keep their timestamps and get new IDs on the destination
+	for _, note := range src.Notes {
+		note.Content = fmt.Sprintf("(from %s) %s", src.ID, note.Content)
+		dst.AddNote(note)
+	}
+	result.MovedNotes = len(src.Notes)
+	src.Notes = nil
+
+	// The destination waits on whatever the source waited on
+	for _, blockerID := range src.BlockedBy {
+		if blockerID == dst.ID || slices.Contains(dst.BlockedBy, blockerID) {
+			continue
+		}
+		blocker := findTask(tasks, blockerID)
+		if blocker == nil || models.IsClosed(blocker.Status) {
+			continue
+		}
+		hasCycle, err := s.WouldCreateCycle(blockerID, dst.ID)
+		if err != nil {
+			return nil, err
+		}
+		if hasCycle {
+			continue
+		}
+		dst.BlockedBy = append(dst.BlockedBy, blockerID)
+		result.Blockers = append(result.Blockers, blockerID)
+	}
+	dst.Updated = now
+	if err := s.SaveTask(dst); err != nil {
+		return nil, err
+	}
+
+	src.Links = append(src.Links, models.Link{Type: models.LinkDuplicates, Target: dst.ID})
+	src.SetStatus(models.StatusCancelled, actor, now)
+	if src.Outcome == "" {
+		src.Outcome = fmt.Sprintf("Merged into %s", dst.ID)
+	}
+	src.Updated = now
+	if err := s.SaveTask(src); err != nil {
+		return nil, err
+	}
+
+	// Dependents of the source wait on the destination instead
+	if result.Repointed, err = s.repointBlockers(src.ID, dst, now); err != nil {
+		return nil, err
+	}
+
+	if result.Task, err = s.LoadTask(dst.ID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeTasks(t *testing.T) {
+	store := NewStorageAt(t.TempDir())
+	require.NoError(t, store.Init())
+	saveSiblings(t, store, nil, "aaaa", "bbbb", "cccc", "dddd")
+	src := "aaaa"
+	saveSiblings(t, store, &src, "eeee")
+
+	task, err := store.LoadTask("aaaa")
+	require.NoError(t, err)
+	task.BlockedBy = []string{"cccc"}
+	task.AddNote(models.Note{Content: "source note"})
+	require.NoError(t, store.SaveTask(task))
+	dep, err := store.LoadTask("dddd")
+	require.NoError(t, err)
+	dep.BlockedBy = []string{"aaaa"}
+	require.NoError(t, store.SaveTask(dep))
+
+	result, err := store.MergeTasks("aaaa", "bbbb")
+	require.NoError(t, err)
+	assert.Equal(t, "aaaa", result.Cancelled)
+	assert.Equal(t, []string{"eeee"}, result.MovedChildren)
+	assert.Equal(t, 1, result.MovedNotes)
+	assert.Equal(t, []string{"cccc"}, result.Blockers)
+	assert.Equal(t, []string{"dddd"}, result.Repointed)
+
+	assert.Equal(t, []string{"cccc"}, result.Task.BlockedBy)
+	require.Len(t, result.Task.Notes, 1)
+	assert.Equal(t, "(from aaaa) source note", result.Task.Notes[0].Content)
+
+	child, err := store.LoadTask("eeee")
+	require.NoError(t, err)
+	assert.Equal(t, "bbbb", *child.Parent)
+	dep, err = store.LoadTask("dddd")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bbbb"}, dep.BlockedBy)
+
+	merged, err := store.LoadTask("aaaa")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, merged.Status)
+	assert.Equal(t, "Merged into bbbb", merged.Outcome)
+	assert.Equal(t, []models.Link{{Type: models.LinkDuplicates, Target: "bbbb"}}, merged.Links)
+	assert.Empty(t, merged.Notes)
+}
+
+func TestMergeTasks_Errors(t *testing.T) {
+	store := NewStorageAt(t.TempDir())
+	require.NoError(t, store.Init())
+	saveSiblings(t, store, nil, "aaaa", "bbbb")
+	parent := "aaaa"
+	saveSiblings(t, store, &parent, "cccc")
+
+	_, err := store.MergeTasks("aaaa", "aaaa")
+	assert.ErrorContains(t, err, "into itself")
+	_, err = store.MergeTasks("aaaa", "zzzz")
+	assert.ErrorContains(t, err, "task zzzz not found")
+	_, err = store.MergeTasks("aaaa", "cccc")
+	assert.ErrorContains(t, err, "into its descendant")
+
+	// A task can merge into its ancestor
+	_, err = store.MergeTasks("cccc", "aaaa")
+	require.NoError(t, err)
+	_, err = store.MergeTasks("bbbb", "cccc")
+	assert.ErrorContains(t, err, "cannot merge cancelled task cccc")
+}
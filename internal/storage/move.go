@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"slices"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+)
+
+// Positions for MoveTask
+const (
+	MoveTop    = "top"
+	MoveBottom = "bottom"
+	MoveBefore = "before"
+	MoveAfter  = "after"
+)
+
+// MoveTask places a task among its siblings: at the top or bottom, or before
+// or after the sibling anchorID. The task gets a rank between its new
+// neighbours; when they leave no room, all siblings are renumbered.
+func (s *Storage) MoveTask(id, position, anchorID string) (*models.Task, error) {
+	var moved *models.Task
+	err := s.Transaction(func(tx *Storage) error {
+		var err error
+		moved, err = tx.moveTask(id, position, anchorID, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
+
+func (s *Storage) moveTask(id, position, anchorID string, now time.Time) (*models.Task, error) {
+	store, err := s.loadStore()
+	if err != nil {
+		return nil, err
+	}
+	task := findTask(store.Tasks, id)
+	if task == nil {
+		return nil, models.Errorf(models.CodeNotFound, "task %s not found", id).With("id", id)
+	}
+
+	// Siblings other than the task, in their current order
+	var siblings []*models.Task
+	for i := range store.Tasks {
+		t := &store.Tasks[i]
+		if t.ID != id && derefParent(t) == derefParent(task) {
+			siblings = append(siblings, t)
+		}
+	}
+	slices.SortStableFunc(siblings, models.CompareRank)
+
+	var index int
+	switch position {
+	case MoveTop:
+		index = 0
+	case MoveBottom:
+		index = len(siblings)
+	case MoveBefore, MoveAfter:
+		if anchorID == id {
+			return nil, models.Errorf(models.CodeInvalidArgument, "cannot move task %s relative to itself", id)
+		}
+		index = slices.IndexFunc(siblings, func(t *models.Task) bool { return t.ID == anchorID })
+		if index < 0 {
+			if findTask(store.Tasks, anchorID) == nil {
+				return nil, models.Errorf(models.CodeNotFound, "task %s not found", anchorID).With("id", anchorID)
+			}
+			return nil, models.Errorf(models.CodeConflict, "task %s is not a sibling of %s", anchorID, id).With("id", anchorID)
+		}
+		if position == MoveAfter {
+			index++
+		}
+	default:
+		return nil, models.Errorf(models.CodeInvalidArgument, "invalid position %q. Must be one of: top, bottom, before, after", position)
+	}
+
+	var prev, next *models.Task
+	if index > 0 {
+		prev = siblings[index-1]
+	}
+	if index < len(siblings) {
+		next = siblings[index]
+	}
+	if rank, ok := models.RankBetween(prev, next); ok {
+		task.Rank = &rank
+	} else {
+		order := slices.Insert(siblings, index, task)
+		for i, t := range order {
+			rank := float64(i + 1)
+			t.Rank = &rank
+		}
+	}
+	task.Updated = now
+
+	if err := s.saveStore(store); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func derefParent(t *models.Task) string {
+	if t.Parent == nil {
+		return ""
+	}
+	return *t.Parent
+}
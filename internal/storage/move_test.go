@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// saveSiblings creates todo tasks under parent (nil for roots), oldest first
+func saveSiblings(t *testing.T, store *Storage, parent *string, ids ...string) {
+	t.Helper()
+	base := time.Now()
+	for i, id := range ids {
+		task := newTestTask(id)
+		task.Parent = parent
+		task.Created = base.Add(time.Duration(i) * time.Millisecond)
+		require.NoError(t, store.SaveTask(task))
+	}
+}
+
+// siblingOrder returns the IDs of the tasks under parent in rank order
+func siblingOrder(t *testing.T, store *Storage, parent string) []string {
+	t.Helper()
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	var siblings []models.Task
+	for i := range tasks {
+		if derefParent(&tasks[i]) == parent {
+			siblings = append(siblings, tasks[i])
+		}
+	}
+	models.SortByRank(siblings)
+	ids := make([]string, len(siblings))
+	for i := range siblings {
+		ids[i] = siblings[i].ID
+	}
+	return ids
+}
+
+func TestMoveTask(t *testing.T) {
+	store := NewStorageAt(t.TempDir())
+	require.NoError(t, store.Init())
+	saveSiblings(t, store, nil, "aaaa", "bbbb", "cccc", "dddd")
+
+	moved, err := store.MoveTask("dddd", MoveTop, "")
+	require.NoError(t, err)
+	require.NotNil(t, moved.Rank)
+	assert.Equal(t, []string{"dddd", "aaaa", "bbbb", "cccc"}, siblingOrder(t, store, ""))
+
+	_, err = store.MoveTask("aaaa", MoveBottom, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dddd", "bbbb", "cccc", "aaaa"}, siblingOrder(t, store, ""))
+
+	_, err = store.MoveTask("aaaa", MoveBefore, "bbbb")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dddd", "aaaa", "bbbb", "cccc"}, siblingOrder(t, store, ""))
+
+	_, err = store.MoveTask("dddd", MoveAfter, "cccc")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa", "bbbb", "cccc", "dddd"}, siblingOrder(t, store, ""))
+
+	// Repeated moves into the same gap eventually renumber the siblings
+	for range 60 {
+		_, err = store.MoveTask("dddd", MoveAfter, "aaaa")
+		require.NoError(t, err)
+		_, err = store.MoveTask("cccc", MoveAfter, "aaaa")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"aaaa", "cccc", "dddd", "bbbb"}, siblingOrder(t, store, ""))
+}
+
+func TestMoveTask_Children(t *testing.T) {
+	store := NewStorageAt(t.TempDir())
+	require.NoError(t, store.Init())
+	parent := "pppp"
+	require.NoError(t, store.SaveTask(newTestTask(parent)))
+	saveSiblings(t, store, &parent, "aaaa", "bbbb", "cccc")
+	saveSiblings(t, store, nil, "zzzz")
+
+	_, err := store.MoveTask("cccc", MoveBefore, "aaaa")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cccc", "aaaa", "bbbb"}, siblingOrder(t, store, parent))
+
+	// next picks the first todo child in rank order
+	parentTask, err := store.LoadTask(parent)
+	require.NoError(t, err)
+	parentTask.Status = models.StatusInProgress
+	require.NoError(t, store.SaveTask(parentTask))
+	next, err := store.GetNextTask()
+	require.NoError(t, err)
+	require.NotNil(t, next.Task)
+	assert.Equal(t, "cccc", next.Task.ID)
+
+	_, err = store.MoveTask("aaaa", MoveAfter, "zzzz")
+	assert.ErrorContains(t, err, "not a sibling")
+	_, err = store.MoveTask("aaaa", MoveAfter, "aaaa")
+	assert.ErrorContains(t, err, "relative to itself")
+	_, err = store.MoveTask("aaaa", MoveAfter, "yyyy")
+	assert.ErrorContains(t, err, "task yyyy not found")
+	_, err = store.MoveTask("yyyy", MoveTop, "")
+	assert.ErrorContains(t, err, "task yyyy not found")
+	_, err = store.MoveTask("aaaa", "middle", "")
+	assert.ErrorContains(t, err, "invalid position")
+}
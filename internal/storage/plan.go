@@ -0,0 +1,323 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// PlanNode describes a task to create together with its subtasks.
+// BlockedBy entries name the Key of another node in the same plan or the ID
+// of an existing task.
+type PlanNode struct {
+	Key         string         `json:"key,omitempty" yaml:"key,omitempty"`
+	Name        string         `json:"name" yaml:"name"`
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Action      string         `json:"action,omitempty" yaml:"action,omitempty"`
+	Verify      string         `json:"verify,omitempty" yaml:"verify,omitempty"`
+	Result      string         `json:"result,omitempty" yaml:"result,omitempty"`
+	VerifyCmd   string         `json:"verifyCmd,omitempty" yaml:"verifyCmd,omitempty"`
+	Fields      map[string]any `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Checklist   []string       `json:"checklist,omitempty" yaml:"checklist,omitempty"`
+	Owner       string         `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Notes       []PlanNote     `json:"notes,omitempty" yaml:"notes,omitempty"`
+	BlockedBy   []string       `json:"blockedBy,omitempty" yaml:"blockedBy,omitempty"`
+	Children    []PlanNode     `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// PlanNote is a note to add to a planned task. In YAML and JSON it may be
+// written as a plain string, which becomes an observation.
+type PlanNote struct {
+	Content string `json:"content" yaml:"content"`
+	Kind    string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Author  string `json:"author,omitempty" yaml:"author,omitempty"`
+}
+
+// planNoteFields avoids recursing into PlanNote's custom unmarshalers
+type planNoteFields PlanNote
+
+func (n *PlanNote) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&n.Content)
+	}
+	// Node.Decode does not inherit KnownFields, so check the keys here
+	if value.Kind == yaml.MappingNode {
+		for i := 0; i < len(value.Content); i += 2 {
+			switch key := value.Content[i].Value; key {
+			case "content", "kind", "author":
+			default:
+				return models.Errorf(models.CodeInvalidArgument, "line %d: field %s not found in type storage.PlanNote", value.Content[i].Line, key)
+			}
+		}
+	}
+	return value.Decode((*planNoteFields)(n))
+}
+
+func (n *PlanNote) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &n.Content)
+	}
+	return json.Unmarshal(data, (*planNoteFields)(n))
+}
+
+// ParsePlan reads a plan document: either a list of tasks, or a mapping whose
+// "tasks" key holds the list. JSON documents are accepted as YAML. Unknown keys
+// are errors.
+func ParsePlan(data []byte) ([]PlanNode, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, models.Errorf(models.CodeInvalidArgument, "failed to parse plan: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, models.Errorf(models.CodeInvalidArgument, "plan contains no tasks")
+	}
+
+	var nodes []PlanNode
+	var err error
+	if doc.Content[0].Kind == yaml.SequenceNode {
+		err = decodeKnown(data, &nodes)
+	} else {
+		var wrapped struct {
+			Tasks []PlanNode `yaml:"tasks"`
+		}
+		err = decodeKnown(data, &wrapped)
+		nodes = wrapped.Tasks
+	}
+	if err != nil {
+		return nil, models.Errorf(models.CodeInvalidArgument, "failed to parse plan: %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, models.Errorf(models.CodeInvalidArgument, "plan contains no tasks")
+	}
+	return nodes, nil
+}
+
+func decodeKnown(data []byte, v any) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	return dec.Decode(v)
+}
+
+// PlanResult reports the tasks created by CreatePlan
+type PlanResult struct {
+	// IDs maps each node Key to the ID of the task created for it
+	IDs map[string]string `json:"ids"`
+	// Created lists every new task ID in creation (depth-first) order
+	Created []string `json:"created"`
+}
+
+// CreatePlan creates the tasks described by nodes, nested under parentID when
+// it is non-nil, and wires up their dependencies. Every node needs a name,
+// action, verify and result. All tasks are created in one transaction: on any
+// error nothing is written.
+func (s *Storage) CreatePlan(nodes []PlanNode, parentID *string) (*PlanResult, error) {
+	var result *PlanResult
+	err := s.Transaction(func(tx *Storage) error {
+		var err error
+		result, err = tx.createPlan(nodes, parentID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type plannedTask struct {
+	node *PlanNode
+	task *models.Task
+}
+
+func (s *Storage) createPlan(nodes []PlanNode, parentID *string) (*PlanResult, error) {
+	if len(nodes) == 0 {
+		return nil, models.Errorf(models.CodeInvalidArgument, "plan contains no tasks")
+	}
+
+	if parentID != nil {
+		parent, err := s.LoadTask(*parentID)
+		if err != nil {
+			if err == ErrTaskNotFound {
+				return nil, models.Errorf(models.CodeNotFound, "parent task %s not found", *parentID).With("id", *parentID)
+			}
+			return nil, err
+		}
+		if models.IsClosed(parent.Status) {
+			return nil, models.Errorf(models.CodeConflict, "cannot add child to %s task", parent.Status)
+		}
+	}
+
+	cfg, err := s.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PlanResult{IDs: make(map[string]string)}
+	var planned []plannedTask
+	now := time.Now()
+	actor := CurrentActor()
+
+	// First pass: create every task so that keys can be resolved to IDs
+	var create func(nodes []PlanNode, parent *string, path string) error
+	create = func(nodes []PlanNode, parent *string, path string) error {
+		for i := range nodes {
+			node := &nodes[i]
+			nodePath := describeNode(node)
+			if path != "" {
+				nodePath = path + " / " + nodePath
+			}
+			if strings.TrimSpace(node.Name) == "" {
+				return models.Errorf(models.CodeInvalidArgument, "plan task %s has no name", describeNode(node))
+			}
+			// Same rule as add and batch: every task is structured
+			if node.Action == "" || node.Verify == "" || node.Result == "" {
+				return models.Errorf(models.CodeInvalidArgument, "plan task %s: action, verify and result are required", nodePath)
+			}
+			if node.Key != "" {
+				if _, dup := result.IDs[node.Key]; dup {
+					return models.Errorf(models.CodeInvalidArgument, "duplicate plan key %q", node.Key)
+				}
+			}
+			if err := cfg.Fields.Validate(node.Fields); err != nil {
+				return fmt.Errorf("plan task %s: %w", describeNode(node), err)
+			}
+			for _, note := range node.Notes {
+				if strings.TrimSpace(note.Content) == "" {
+					return models.Errorf(models.CodeInvalidArgument, "plan task %s: note has no content", describeNode(node))
+				}
+				if note.Kind != "" && !models.IsValidNoteKind(note.Kind) {
+					return models.Errorf(models.CodeInvalidArgument, "plan task %s: invalid note kind %q", describeNode(node), note.Kind)
+				}
+			}
+
+			id, err := s.GenerateTaskID()
+			if err != nil {
+				return err
+			}
+
+			// Space out timestamps so siblings keep their plan order
+			created := now.Add(time.Duration(len(planned)) * time.Microsecond)
+			task := &models.Task{
+				ID:          id,
+				Name:        node.Name,
+				Description: node.Description,
+				Action:      node.Action,
+				Verify:      node.Verify,
+				Result:      node.Result,
+				VerifyCmd:   node.VerifyCmd,
+				Parent:      parent,
+				Status:      models.StatusTodo,
+				Fields:      node.Fields,
+				Created:     created,
+				Updated:     created,
+			}
+			for _, item := range node.Checklist {
+				task.Checklist = append(task.Checklist, models.ChecklistItem{Text: item})
+			}
+			if node.Owner != "" {
+				owner := node.Owner
+				task.SetOwner(&owner, actor, created)
+			}
+			for _, note := range node.Notes {
+				kind, author := note.Kind, note.Author
+				if kind == "" {
+					kind = models.NoteObservation
+				}
+				if author == "" {
+					author = actor
+				}
+				task.AddNote(models.Note{Content: note.Content, Kind: kind, Author: author, Timestamp: created})
+			}
+			if err := s.SaveTask(task); err != nil {
+				return err
+			}
+
+			if node.Key != "" {
+				result.IDs[node.Key] = id
+			}
+			result.Created = append(result.Created, id)
+			planned = append(planned, plannedTask{node: node, task: task})
+
+			taskID := id
+			if err := create(node.Children, &taskID, nodePath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := create(nodes, parentID, ""); err != nil {
+		return nil, err
+	}
+
+	// Second pass: resolve dependencies, rejecting cycles like the block command
+	for _, p := range planned {
+		for _, ref := range p.node.BlockedBy {
+			blockerID, err := s.resolvePlanRef(ref, result.IDs)
+			if err != nil {
+				return nil, fmt.Errorf("plan task %s: %w", describeNode(p.node), err)
+			}
+			if err := s.addPlanDependency(blockerID, p.task.ID); err != nil {
+				return nil, fmt.Errorf("plan task %s: %w", describeNode(p.node), err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// resolvePlanRef turns a plan key or existing task ID into a task ID
+func (s *Storage) resolvePlanRef(ref string, ids map[string]string) (string, error) {
+	if id, ok := ids[ref]; ok {
+		return id, nil
+	}
+	id := models.NormalizeTaskID(ref)
+	if models.IsValidTaskID(id) {
+		if _, err := s.LoadTask(id); err == nil {
+			return id, nil
+		}
+	}
+	return "", models.Errorf(models.CodeInvalidArgument, "unknown dependency %q: not a plan key or existing task ID", ref)
+}
+
+func (s *Storage) addPlanDependency(blockerID, blockedID string) error {
+	if blockerID == blockedID {
+		return models.Errorf(models.CodeInvalidArgument, "a task cannot block itself")
+	}
+	blocker, err := s.LoadTask(blockerID)
+	if err != nil {
+		return err
+	}
+	if models.IsClosed(blocker.Status) {
+		return models.Errorf(models.CodeConflict, "cannot block on completed task %s", blockerID)
+	}
+	hasCycle, err := s.WouldCreateCycle(blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+	if hasCycle {
+		return models.Errorf(models.CodeConflict, "cannot add dependency on %s: would create a cycle", blockerID)
+	}
+
+	blocked, err := s.LoadTask(blockedID)
+	if err != nil {
+		return err
+	}
+	for _, id := range blocked.BlockedBy {
+		if id == blockerID {
+			return nil
+		}
+	}
+	blocked.BlockedBy = append(blocked.BlockedBy, blockerID)
+	return s.SaveTask(blocked)
+}
+
+func describeNode(node *PlanNode) string {
+	if node.Key != "" {
+		return fmt.Sprintf("%q", node.Key)
+	}
+	return fmt.Sprintf("%q", node.Name)
+}
@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// structurePlan fills in action, verify and result on every node
+func structurePlan(nodes []PlanNode) []PlanNode {
+	for i := range nodes {
+		nodes[i].Action = "Do " + nodes[i].Name
+		nodes[i].Verify = "Check " + nodes[i].Name
+		nodes[i].Result = nodes[i].Name + " done"
+		structurePlan(nodes[i].Children)
+	}
+	return nodes
+}
+
+func TestCreatePlan(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	nodes := []PlanNode{
+		{
+			Key:  "feature",
+			Name: "Feature",
+			Children: []PlanNode{
+				{Key: "design", Name: "Design", Checklist: []string{"API reviewed"}},
+				{Key: "impl", Name: "Implement", BlockedBy: []string{"design"}},
+				{Key: "test", Name: "Test", BlockedBy: []string{"impl"}, VerifyCmd: "go test ./..."},
+			},
+		},
+	}
+
+	result, err := store.CreatePlan(structurePlan(nodes), nil)
+	require.NoError(t, err)
+	require.Len(t, result.Created, 4)
+	assert.Equal(t, result.IDs["feature"], result.Created[0])
+
+	root, err := store.LoadTask(result.IDs["feature"])
+	require.NoError(t, err)
+	assert.Nil(t, root.Parent)
+
+	children, err := store.GetChildren(root.ID)
+	require.NoError(t, err)
+	require.Len(t, children, 3)
+	assert.Equal(t, "Design", children[0].Name)
+	assert.Equal(t, "Implement", children[1].Name)
+	assert.Equal(t, "Test", children[2].Name)
+
+	design, err := store.LoadTask(result.IDs["design"])
+	require.NoError(t, err)
+	require.Len(t, design.Checklist, 1)
+	assert.Equal(t, "API reviewed", design.Checklist[0].Text)
+
+	impl, err := store.LoadTask(result.IDs["impl"])
+	require.NoError(t, err)
+	assert.Equal(t, []string{design.ID}, impl.BlockedBy)
+
+	test, err := store.LoadTask(result.IDs["test"])
+	require.NoError(t, err)
+	assert.Equal(t, []string{impl.ID}, test.BlockedBy)
+	assert.Equal(t, "go test ./...", test.VerifyCmd)
+}
+
+func TestCreatePlan_UnderParentAndExistingBlocker(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	require.NoError(t, store.SaveTask(newTestTask("aaaa")))
+	require.NoError(t, store.SaveTask(newTestTask("bbbb")))
+
+	parent := "aaaa"
+	result, err := store.CreatePlan(structurePlan([]PlanNode{{Name: "Child", BlockedBy: []string{"BBBB"}}}), &parent)
+	require.NoError(t, err)
+
+	child, err := store.LoadTask(result.Created[0])
+	require.NoError(t, err)
+	require.NotNil(t, child.Parent)
+	assert.Equal(t, "aaaa", *child.Parent)
+	assert.Equal(t, []string{"bbbb"}, child.BlockedBy)
+}
+
+func TestCreatePlan_Errors(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	done := newTestTask("dddd")
+	done.Status = models.StatusDone
+	require.NoError(t, store.SaveTask(done))
+
+	missing := "zzzz"
+	tests := []struct {
+		name   string
+		nodes  []PlanNode
+		parent *string
+		errMsg string
+	}{
+		{"empty", nil, nil, "no tasks"},
+		{"no name", []PlanNode{{Key: "a"}}, nil, "has no name"},
+		{"duplicate key", []PlanNode{{Key: "a", Name: "A"}, {Key: "a", Name: "B"}}, nil, "duplicate plan key"},
+		{"unknown dependency", []PlanNode{{Name: "A", BlockedBy: []string{"nope"}}}, nil, "unknown dependency"},
+		{"cycle", []PlanNode{
+			{Key: "a", Name: "A", BlockedBy: []string{"b"}},
+			{Key: "b", Name: "B", BlockedBy: []string{"a"}},
+		}, nil, "cycle"},
+		{"done blocker", []PlanNode{{Name: "A", BlockedBy: []string{"dddd"}}}, nil, "completed task"},
+		{"missing parent", []PlanNode{{Name: "A"}}, &missing, "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.CreatePlan(structurePlan(tt.nodes), tt.parent)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+
+			// Nothing from a failed plan is written
+			tasks, err := store.LoadAll()
+			require.NoError(t, err)
+			assert.Len(t, tasks, 1)
+		})
+	}
+}
+
+func TestCreatePlan_RequiresStructuredFields(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	nodes := structurePlan([]PlanNode{{
+		Key:      "feature",
+		Name:     "Feature",
+		Children: []PlanNode{{Name: "Design"}, {Name: "Implement"}},
+	}})
+	nodes[0].Children[1].Verify = ""
+
+	_, err := store.CreatePlan(nodes, nil)
+	require.Error(t, err)
+	assert.Equal(t, models.CodeInvalidArgument, models.ErrorCodeOf(err))
+	assert.Contains(t, err.Error(), `plan task "feature" / "Implement": action, verify and result are required`)
+
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
+func TestCreatePlan_ValidatesFields(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+	writeConfig(t, dir, `fields:
+  points:
+    type: number
+`)
+
+	_, err := store.CreatePlan(structurePlan([]PlanNode{{Name: "A", Fields: map[string]any{"points": "many"}}}), nil)
+	assert.Error(t, err)
+
+	result, err := store.CreatePlan(structurePlan([]PlanNode{{Name: "A", Fields: map[string]any{"points": 3}}}), nil)
+	require.NoError(t, err)
+	task, err := store.LoadTask(result.Created[0])
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), task.Fields["points"])
+}
+
+func TestParsePlan(t *testing.T) {
+	yamlPlan := `
+tasks:
+  - key: api
+    name: Build API
+    owner: agent-1
+    notes:
+      - Started from the old handler
+      - content: Use JSON everywhere
+        kind: decision
+    children:
+      - name: Endpoints
+        blockedBy: [api-docs]
+  - key: api-docs
+    name: Document API
+`
+	nodes, err := ParsePlan([]byte(yamlPlan))
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+	assert.Equal(t, "agent-1", nodes[0].Owner)
+	assert.Equal(t, []PlanNote{
+		{Content: "Started from the old handler"},
+		{Content: "Use JSON everywhere", Kind: models.NoteDecision},
+	}, nodes[0].Notes)
+	assert.Equal(t, []string{"api-docs"}, nodes[0].Children[0].BlockedBy)
+
+	// A bare list, written as JSON
+	nodes, err = ParsePlan([]byte(`[{"name": "One", "notes": ["n"]}, {"name": "Two"}]`))
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+	assert.Equal(t, "n", nodes[0].Notes[0].Content)
+
+	_, err = ParsePlan([]byte(`[{"name": "One", "owner": "x", "title": "typo"}]`))
+	assert.ErrorContains(t, err, "field title not found")
+	_, err = ParsePlan([]byte(`[{"name": "One", "notes": [{"text": "typo"}]}]`))
+	assert.ErrorContains(t, err, "field text not found")
+	_, err = ParsePlan([]byte(`tasks: []`))
+	assert.ErrorContains(t, err, "no tasks")
+	_, err = ParsePlan([]byte(``))
+	assert.ErrorContains(t, err, "no tasks")
+}
+
+func TestCreatePlan_OwnersAndNotes(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+	t.Setenv(AgentEnv, "planner")
+
+	nodes := []PlanNode{{
+		Key:   "a",
+		Name:  "A",
+		Owner: "agent-1",
+		Notes: []PlanNote{{Content: "first"}, {Content: "second", Kind: models.NoteQuestion, Author: "human"}},
+	}}
+	result, err := store.CreatePlan(structurePlan(nodes), nil)
+	require.NoError(t, err)
+
+	task, err := store.LoadTask(result.IDs["a"])
+	require.NoError(t, err)
+	require.NotNil(t, task.Owner)
+	assert.Equal(t, "agent-1", *task.Owner)
+	require.Len(t, task.History, 1)
+	assert.Equal(t, "planner", task.History[0].Actor)
+	require.Len(t, task.Notes, 2)
+	assert.Equal(t, 1, task.Notes[0].ID)
+	assert.Equal(t, models.NoteObservation, task.Notes[0].Kind)
+	assert.Equal(t, "planner", task.Notes[0].Author)
+	assert.Equal(t, 2, task.Notes[1].ID)
+	assert.Equal(t, models.NoteQuestion, task.Notes[1].Kind)
+	assert.Equal(t, "human", task.Notes[1].Author)
+
+	nodes[0].Notes = []PlanNote{{Content: "x", Kind: "gossip"}}
+	_, err = store.CreatePlan(structurePlan(nodes), nil)
+	assert.ErrorContains(t, err, `invalid note kind "gossip"`)
+}
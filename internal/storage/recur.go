@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+)
+
+// RecurSpawn records one recurring task instance created from a completed task
+type RecurSpawn struct {
+	From string `json:"from"`
+	Task string `json:"task"`
+}
+
+// SpawnRecurrence creates the next instance of a completed recurring task and
+// marks the rule on task as spawned. The new task copies the definition of the
+// old one (name, description, action/verify/result, verifyCmd, custom fields
+// and an unticked checklist) and carries the recurrence rule forward.
+func (s *Storage) SpawnRecurrence(task *models.Task, now time.Time) (*models.Task, error) {
+	if !task.Recurrence.Pending() {
+		return nil, models.Errorf(models.CodeConflict, "task %s has no pending recurrence", task.ID)
+	}
+
+	rule, err := task.Recurrence.Following(now)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.GenerateTaskID()
+	if err != nil {
+		return nil, err
+	}
+
+	// Stay under the same parent while it is still open
+	var parent *string
+	if task.Parent != nil {
+		if p, err := s.LoadTask(*task.Parent); err == nil && !models.IsClosed(p.Status) {
+			parentID := p.ID
+			parent = &parentID
+		}
+	}
+
+	next := &models.Task{
+		ID:          id,
+		Name:        task.Name,
+		Description: task.Description,
+		Action:      task.Action,
+		Verify:      task.Verify,
+		Result:      task.Result,
+		VerifyCmd:   task.VerifyCmd,
+		Parent:      parent,
+		Status:      models.StatusTodo,
+		Recurrence:  rule,
+		Created:     now,
+		Updated:     now,
+	}
+	if len(task.Fields) > 0 {
+		next.Fields = make(map[string]any, len(task.Fields))
+		for k, v := range task.Fields {
+			next.Fields[k] = v
+		}
+	}
+	for _, item := range task.Checklist {
+		next.Checklist = append(next.Checklist, models.ChecklistItem{Text: item.Text})
+	}
+
+	task.Recurrence.Spawned = id
+	if err := s.SaveTask(task); err != nil {
+		return nil, err
+	}
+	if err := s.SaveTask(next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// RunRecurrences spawns the next instance of every completed recurring task
+// whose next occurrence is due at now. All instances are created in one transaction.
+func (s *Storage) RunRecurrences(now time.Time) ([]RecurSpawn, error) {
+	spawned := []RecurSpawn{}
+	err := s.Transaction(func(tx *Storage) error {
+		tasks, err := tx.LoadAll()
+		if err != nil {
+			return err
+		}
+		for i := range tasks {
+			task := &tasks[i]
+			if task.Status != models.StatusDone || !task.Recurrence.Due(now) {
+				continue
+			}
+			next, err := tx.SpawnRecurrence(task, now)
+			if err != nil {
+				return err
+			}
+			spawned = append(spawned, RecurSpawn{From: task.ID, Task: next.ID})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return spawned, nil
+}
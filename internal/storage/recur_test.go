@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpawnRecurrence(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	parent := newTestTask("pppp")
+	require.NoError(t, store.SaveTask(parent))
+
+	now := time.Now()
+	rule, err := models.ParseRecurrence("on-done", now)
+	require.NoError(t, err)
+	owner := "agent-1"
+	parentID := "pppp"
+	task := newTestTask("aaaa")
+	task.Action, task.Verify, task.Result, task.Outcome = "rotate", "check", "report", "rotated"
+	task.VerifyCmd = "true"
+	task.Parent = &parentID
+	task.Owner = &owner
+	task.Status = models.StatusDone
+	task.Fields = map[string]any{"tags": "chore"}
+	task.Checklist = []models.ChecklistItem{{Text: "old logs gone", Done: true, DoneAt: &now}}
+	task.Notes = []models.Note{{Content: "done once", Timestamp: now}}
+	task.Recurrence = rule
+	require.NoError(t, store.SaveTask(task))
+
+	next, err := store.SpawnRecurrence(task, now)
+	require.NoError(t, err)
+	assert.NotEqual(t, "aaaa", next.ID)
+	assert.Equal(t, models.StatusTodo, next.Status)
+	assert.Equal(t, "rotate", next.Action)
+	assert.Equal(t, "true", next.VerifyCmd)
+	assert.Empty(t, next.Outcome)
+	assert.Nil(t, next.Owner)
+	assert.Empty(t, next.Notes)
+	require.NotNil(t, next.Parent)
+	assert.Equal(t, "pppp", *next.Parent)
+	assert.Equal(t, map[string]any{"tags": "chore"}, next.Fields)
+	require.Len(t, next.Checklist, 1)
+	assert.False(t, next.Checklist[0].Done)
+	require.NotNil(t, next.Recurrence)
+	assert.True(t, next.Recurrence.Pending())
+
+	old, err := store.LoadTask("aaaa")
+	require.NoError(t, err)
+	assert.Equal(t, next.ID, old.Recurrence.Spawned)
+
+	_, err = store.SpawnRecurrence(old, now)
+	assert.Error(t, err)
+}
+
+func TestRunRecurrences(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	created := time.Now().Add(-48 * time.Hour)
+	daily, err := models.ParseRecurrence("1d", created)
+	require.NoError(t, err)
+	weekly, err := models.ParseRecurrence("1w", created)
+	require.NoError(t, err)
+	open, err := models.ParseRecurrence("1d", created)
+	require.NoError(t, err)
+
+	due := newTestTask("aaaa")
+	due.Status = models.StatusDone
+	due.Recurrence = daily
+	require.NoError(t, store.SaveTask(due))
+
+	notDue := newTestTask("bbbb")
+	notDue.Status = models.StatusDone
+	notDue.Recurrence = weekly
+	require.NoError(t, store.SaveTask(notDue))
+
+	// Still open: the current instance has not been finished
+	stillOpen := newTestTask("cccc")
+	stillOpen.Recurrence = open
+	require.NoError(t, store.SaveTask(stillOpen))
+
+	spawned, err := store.RunRecurrences(time.Now())
+	require.NoError(t, err)
+	require.Len(t, spawned, 1)
+	assert.Equal(t, "aaaa", spawned[0].From)
+
+	next, err := store.LoadTask(spawned[0].Task)
+	require.NoError(t, err)
+	require.NotNil(t, next.Recurrence.Next)
+	assert.True(t, next.Recurrence.Next.After(time.Now()))
+
+	// Running again creates nothing new
+	spawned, err = store.RunRecurrences(time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, spawned)
+
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 4)
+}
@@ -0,0 +1,282 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+)
+
+// SplitAll assigns a blocker or dependent to every part of a split
+const SplitAll = -1
+
+// SplitOptions describes how SplitTask divides a task. Parts are numbered
+// from 0 in the order of Names.
+type SplitOptions struct {
+	Names []string
+	// Siblings creates the parts next to the task and cancels it; otherwise
+	// the parts become children of the task
+	Siblings bool
+	// Blockers maps tasks the task waits on to the part that inherits the
+	// dependency. Blockers not listed are inherited by every part.
+	Blockers map[string]int
+	// Dependents maps tasks waiting on the task to the part they wait on
+	// instead. Dependents not listed keep waiting on the task, or on every
+	// part when the task is cancelled.
+	Dependents map[string]int
+	// Notes maps note IDs to the part the note moves to. Notes not listed stay.
+	Notes map[int]int
+}
+
+// SplitResult reports the changes made by SplitTask
+type SplitResult struct {
+	// Task is the task that was split
+	Task *models.Task `json:"task"`
+	// Parts are the new tasks, in the order they were named
+	Parts []models.Task `json:"parts"`
+	// Cancelled is set when the task was replaced by sibling parts
+	Cancelled bool `json:"cancelled,omitempty"`
+}
+
+// SplitTask divides a task into new tasks in one transaction. The parts copy
+// the task's custom fields and take its blockers, dependents and notes as
+// opts assigns them.
+func (s *Storage) SplitTask(id string, opts SplitOptions) (*SplitResult, error) {
+	var result *SplitResult
+	err := s.Transaction(func(tx *Storage) error {
+		var err error
+		result, err = tx.splitTask(id, opts, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Storage) splitTask(id string, opts SplitOptions, now time.Time) (*SplitResult, error) {
+	if len(opts.Names) < 2 {
+		return nil, models.Errorf(models.CodeInvalidArgument, "a task must be split into at least two parts")
+	}
+	for _, name := range opts.Names {
+		if strings.TrimSpace(name) == "" {
+			return nil, models.Errorf(models.CodeInvalidArgument, "split part names cannot be empty")
+		}
+	}
+
+	task, err := s.loadLinkTask(id)
+	if err != nil {
+		return nil, err
+	}
+	task.AssignNoteIDs()
+	if models.IsClosed(task.Status) {
+		return nil, models.Errorf(models.CodeConflict, "cannot split %s task %s", task.Status, id).With("id", id)
+	}
+	if opts.Siblings {
+		hasUndone, err := s.HasUndoneChildren(id)
+		if err != nil {
+			return nil, err
+		}
+		if hasUndone {
+			return nil, models.Errorf(models.CodeHasUndoneChildren, "cannot replace task %s with siblings: has undone children", id).With("id", id)
+		}
+	}
+
+	dependents, err := s.dependentsOf(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSplitAssignments(task, dependents, opts); err != nil {
+		return nil, err
+	}
+
+	// Create the parts
+	parent := task.Parent
+	if !opts.Siblings {
+		parent = &task.ID
+	}
+	actor := CurrentActor()
+	parts := make([]*models.Task, len(opts.Names))
+	for i, name := range opts.Names {
+		partID, err := s.GenerateTaskID()
+		if err != nil {
+			return nil, err
+		}
+		// Space out timestamps so the parts keep their order
+		created := now.Add(time.Duration(i) * time.Microsecond)
+		part := &models.Task{
+			ID:      partID,
+			Name:    name,
+			Parent:  parent,
+			Status:  models.StatusTodo,
+			Fields:  copyFields(task.Fields),
+			Created: created,
+			Updated: created,
+		}
+		for _, blockerID := range task.BlockedBy {
+			if to, ok := opts.Blockers[blockerID]; !ok || to == SplitAll || to == i {
+				part.BlockedBy = append(part.BlockedBy, blockerID)
+			}
+		}
+		if err := s.SaveTask(part); err != nil {
+			return nil, err
+		}
+		parts[i] = part
+	}
+
+	// Move the assigned notes, keeping their timestamps
+	var kept []models.Note
+	for _, note := range task.Notes {
+		to, ok := opts.Notes[note.ID]
+		if !ok {
+			kept = append(kept, note)
+			continue
+		}
+		parts[to].AddNote(note)
+	}
+	task.Notes = kept
+
+	// Dependents wait on their assigned parts instead of the task
+	for i := range dependents {
+		dep := &dependents[i]
+		to, ok := opts.Dependents[dep.ID]
+		if !ok && !opts.Siblings {
+			continue
+		}
+		if !ok {
+			to = SplitAll
+		}
+		var blockers []string
+		for _, blockerID := range dep.BlockedBy {
+			if blockerID != id {
+				blockers = append(blockers, blockerID)
+				continue
+			}
+			for j, part := range parts {
+				if to == SplitAll || to == j {
+					blockers = append(blockers, part.ID)
+				}
+			}
+		}
+		dep.BlockedBy = blockers
+		dep.Updated = now
+		if err := s.SaveTask(dep); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &SplitResult{Cancelled: opts.Siblings}
+	if opts.Siblings {
+		partIDs := make([]string, len(parts))
+		for i, part := range parts {
+			partIDs[i] = part.ID
+			part.Links = append(part.Links, models.Link{Type: models.LinkSupersedes, Target: id})
+		}
+		task.SetStatus(models.StatusCancelled, actor, now)
+		if task.Outcome == "" {
+			task.Outcome = fmt.Sprintf("Split into %s", strings.Join(partIDs, ", "))
+		}
+	}
+	task.Updated = now
+	if err := s.SaveTask(task); err != nil {
+		return nil, err
+	}
+	for _, part := range parts {
+		if err := s.SaveTask(part); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Siblings {
+		// Parts take the task's place among its siblings
+		after := id
+		for _, part := range parts {
+			if _, err := s.moveTask(part.ID, MoveAfter, after, now); err != nil {
+				return nil, err
+			}
+			after = part.ID
+		}
+		if err := s.RemoveFromAllBlockedBy(id); err != nil {
+			return nil, err
+		}
+	}
+
+	if result.Task, err = s.LoadTask(id); err != nil {
+		return nil, err
+	}
+	for _, part := range parts {
+		saved, err := s.LoadTask(part.ID)
+		if err != nil {
+			return nil, err
+		}
+		result.Parts = append(result.Parts, *saved)
+	}
+	return result, nil
+}
+
+// checkSplitAssignments rejects assignments to parts that do not exist, or of
+// blockers, dependents and notes the task does not have
+func checkSplitAssignments(task *models.Task, dependents []models.Task, opts SplitOptions) error {
+	validPart := func(what string, to int) error {
+		if to != SplitAll && (to < 0 || to >= len(opts.Names)) {
+			return models.Errorf(models.CodeInvalidArgument, "%s assigned to part %d, but there are only %d parts", what, to+1, len(opts.Names))
+		}
+		return nil
+	}
+	for blockerID, to := range opts.Blockers {
+		if !slices.Contains(task.BlockedBy, blockerID) {
+			return models.Errorf(models.CodeInvalidArgument, "task %s is not blocked by %s", task.ID, blockerID)
+		}
+		if err := validPart("blocker "+blockerID, to); err != nil {
+			return err
+		}
+	}
+	for depID, to := range opts.Dependents {
+		if !slices.ContainsFunc(dependents, func(t models.Task) bool { return t.ID == depID }) {
+			return models.Errorf(models.CodeInvalidArgument, "task %s is not blocked by %s", depID, task.ID)
+		}
+		if err := validPart("dependent "+depID, to); err != nil {
+			return err
+		}
+	}
+	for noteID, to := range opts.Notes {
+		if task.FindNote(noteID) == nil {
+			return models.Errorf(models.CodeNotFound, "task %s has no note %d", task.ID, noteID)
+		}
+		if to == SplitAll {
+			return models.Errorf(models.CodeInvalidArgument, "note %d must be moved to a single part", noteID)
+		}
+		if err := validPart(fmt.Sprintf("note %d", noteID), to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependentsOf returns the tasks blocked by id
+func (s *Storage) dependentsOf(id string) ([]models.Task, error) {
+	tasks, err := s.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	var dependents []models.Task
+	for i := range tasks {
+		if slices.Contains(tasks[i].BlockedBy, id) {
+			dependents = append(dependents, tasks[i])
+		}
+	}
+	return dependents, nil
+}
+
+func copyFields(fields map[string]any) map[string]any {
+	if len(fields) == 0 {
+		return nil
+	}
+	copied := make(map[string]any, len(fields))
+	for k, v := range fields {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSplitStore creates aaaa blocked by bbbb and cccc, with dddd waiting on
+// aaaa and two notes on aaaa
+func newSplitStore(t *testing.T) *Storage {
+	t.Helper()
+	store := NewStorageAt(t.TempDir())
+	require.NoError(t, store.Init())
+	saveSiblings(t, store, nil, "aaaa", "bbbb", "cccc", "dddd")
+
+	task, err := store.LoadTask("aaaa")
+	require.NoError(t, err)
+	task.BlockedBy = []string{"bbbb", "cccc"}
+	task.Fields = map[string]any{"tag": "api"}
+	task.AddNote(models.Note{Content: "first"})
+	task.AddNote(models.Note{Content: "second"})
+	require.NoError(t, store.SaveTask(task))
+
+	dep, err := store.LoadTask("dddd")
+	require.NoError(t, err)
+	dep.BlockedBy = []string{"aaaa"}
+	require.NoError(t, store.SaveTask(dep))
+	return store
+}
+
+func TestSplitTask_Children(t *testing.T) {
+	store := newSplitStore(t)
+
+	result, err := store.SplitTask("aaaa", SplitOptions{
+		Names:    []string{"One", "Two"},
+		Blockers: map[string]int{"cccc": 1},
+		Notes:    map[int]int{2: 0},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Cancelled)
+	require.Len(t, result.Parts, 2)
+
+	one, two := result.Parts[0], result.Parts[1]
+	assert.Equal(t, "One", one.Name)
+	assert.Equal(t, "aaaa", *one.Parent)
+	assert.Equal(t, "aaaa", *two.Parent)
+	assert.Equal(t, map[string]any{"tag": "api"}, one.Fields)
+	// bbbb goes to every part, cccc only to the second
+	assert.Equal(t, []string{"bbbb"}, one.BlockedBy)
+	assert.Equal(t, []string{"bbbb", "cccc"}, two.BlockedBy)
+	require.Len(t, one.Notes, 1)
+	assert.Equal(t, "second", one.Notes[0].Content)
+
+	// The task stays open and keeps its dependents and other notes
+	assert.Equal(t, models.StatusTodo, result.Task.Status)
+	require.Len(t, result.Task.Notes, 1)
+	assert.Equal(t, "first", result.Task.Notes[0].Content)
+	dep, err := store.LoadTask("dddd")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa"}, dep.BlockedBy)
+}
+
+func TestSplitTask_Siblings(t *testing.T) {
+	store := newSplitStore(t)
+
+	result, err := store.SplitTask("aaaa", SplitOptions{
+		Names:    []string{"One", "Two"},
+		Siblings: true,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Cancelled)
+	one, two := result.Parts[0], result.Parts[1]
+	assert.Nil(t, one.Parent)
+	assert.Equal(t, []models.Link{{Type: models.LinkSupersedes, Target: "aaaa"}}, one.Links)
+
+	assert.Equal(t, models.StatusCancelled, result.Task.Status)
+	assert.Equal(t, "Split into "+one.ID+", "+two.ID, result.Task.Outcome)
+
+	// Dependents wait on every part, and the parts take the task's place
+	dep, err := store.LoadTask("dddd")
+	require.NoError(t, err)
+	assert.Equal(t, []string{one.ID, two.ID}, dep.BlockedBy)
+	assert.Equal(t, []string{"aaaa", one.ID, two.ID, "bbbb", "cccc", "dddd"}, siblingOrder(t, store, ""))
+}
+
+func TestSplitTask_Dependents(t *testing.T) {
+	store := newSplitStore(t)
+
+	result, err := store.SplitTask("aaaa", SplitOptions{
+		Names:      []string{"One", "Two"},
+		Dependents: map[string]int{"dddd": 1},
+	})
+	require.NoError(t, err)
+	dep, err := store.LoadTask("dddd")
+	require.NoError(t, err)
+	assert.Equal(t, []string{result.Parts[1].ID}, dep.BlockedBy)
+}
+
+func TestSplitTask_Errors(t *testing.T) {
+	store := newSplitStore(t)
+
+	tests := []struct {
+		name string
+		opts SplitOptions
+		err  string
+	}{
+		{"one part", SplitOptions{Names: []string{"One"}}, "at least two parts"},
+		{"empty name", SplitOptions{Names: []string{"One", " "}}, "cannot be empty"},
+		{"unknown blocker", SplitOptions{Names: []string{"One", "Two"}, Blockers: map[string]int{"dddd": 0}}, "not blocked by dddd"},
+		{"unknown dependent", SplitOptions{Names: []string{"One", "Two"}, Dependents: map[string]int{"bbbb": 0}}, "task bbbb is not blocked by aaaa"},
+		{"missing part", SplitOptions{Names: []string{"One", "Two"}, Blockers: map[string]int{"bbbb": 2}}, "part 3, but there are only 2 parts"},
+		{"unknown note", SplitOptions{Names: []string{"One", "Two"}, Notes: map[int]int{9: 0}}, "has no note 9"},
+		{"note to all", SplitOptions{Names: []string{"One", "Two"}, Notes: map[int]int{1: SplitAll}}, "single part"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.SplitTask("aaaa", tt.opts)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	// Nothing was created by the failed splits
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 4)
+
+	_, err = store.SplitTask("zzzz", SplitOptions{Names: []string{"One", "Two"}})
+	assert.ErrorContains(t, err, "task zzzz not found")
+
+	// Sibling parts cannot replace a task with open children
+	child := newTestTask("eeee")
+	parent := "aaaa"
+	child.Parent = &parent
+	require.NoError(t, store.SaveTask(child))
+	_, err = store.SplitTask("aaaa", SplitOptions{Names: []string{"One", "Two"}, Siblings: true})
+	assert.Equal(t, models.CodeHasUndoneChildren, models.ErrorCodeOf(err))
+
+	task, err := store.LoadTask("bbbb")
+	require.NoError(t, err)
+	task.Status = models.StatusDone
+	require.NoError(t, store.SaveTask(task))
+	_, err = store.SplitTask("bbbb", SplitOptions{Names: []string{"One", "Two"}})
+	assert.ErrorContains(t, err, "cannot split done task")
+}
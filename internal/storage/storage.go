@@ -3,11 +3,9 @@ package storage
 import (
 	"crypto/rand"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 	"time"
 
 	"github.com/simonspoon/clipm/internal/models"
@@ -21,8 +19,8 @@ const (
 
 // Storage errors.
 var (
-	ErrNotInProject = errors.New("not in a clipm project. Run 'clipm init' first")
-	ErrTaskNotFound = errors.New("task not found")
+	ErrNotInProject error = models.Errorf(models.CodeNotInProject, "not in a clipm project. Run 'clipm init' first")
+	ErrTaskNotFound error = models.Errorf(models.CodeNotFound, "task not found")
 )
 
 // TaskStore is the root structure for the tasks.json file
@@ -34,6 +32,9 @@ type TaskStore struct {
 // Storage handles all file operations for clipm
 type Storage struct {
 	rootDir string
+
+	// tx holds the uncommitted store while running inside Transaction or DryRun
+	tx *txState
 }
 
 // NewStorage creates a new storage instance
@@ -77,7 +78,7 @@ func (s *Storage) Init() error {
 
 	// Check if already exists
 	if _, err := os.Stat(clipmPath); err == nil {
-		return fmt.Errorf(".clipm directory already exists")
+		return models.Errorf(models.CodeConflict, ".clipm directory already exists")
 	}
 
 	// Create .clipm directory
@@ -223,6 +224,17 @@ func (s *Storage) GetNextTask() (*NextResult, error) {
 // GetNextTaskFiltered returns the next task with optional ownership filter.
 // When unclaimedOnly is true, tasks with an owner are skipped.
 func (s *Storage) GetNextTaskFiltered(unclaimedOnly bool) (*NextResult, error) {
+	var keep func(*models.Task) bool
+	if unclaimedOnly {
+		keep = func(t *models.Task) bool { return t.Owner == nil }
+	}
+	return s.GetNextTaskMatching(keep)
+}
+
+// GetNextTaskMatching returns the next task, skipping candidates for which keep
+// returns false. The in-progress task used as context is not filtered. A nil
+// keep skips nothing.
+func (s *Storage) GetNextTaskMatching(keep func(*models.Task) bool) (*NextResult, error) {
 	store, err := s.loadStore()
 	if err != nil {
 		return nil, err
@@ -232,9 +244,7 @@ func (s *Storage) GetNextTaskFiltered(unclaimedOnly bool) (*NextResult, error) {
 	if deepest == nil {
 		// No in-progress context - return root-level todos as candidates
 		candidates := getRootTodos(store.Tasks, true)
-		if unclaimedOnly {
-			candidates = filterUnclaimed(candidates)
-		}
+		candidates = filterKeep(candidates, keep)
 		result := &NextResult{Candidates: candidates}
 		if len(candidates) == 0 {
 			result.BlockedCount = countBlockedTodos(store.Tasks)
@@ -247,18 +257,14 @@ func (s *Storage) GetNextTaskFiltered(unclaimedOnly bool) (*NextResult, error) {
 	for {
 		// First, check for todo children of current task
 		children := getTodoChildren(store.Tasks, current.ID, true)
-		if unclaimedOnly {
-			children = filterUnclaimed(children)
-		}
+		children = filterKeep(children, keep)
 		if len(children) > 0 {
 			return &NextResult{Task: &children[0]}, nil
 		}
 
 		// Then, check for todo siblings
 		siblings := getTodoSiblings(store.Tasks, current.ID, true)
-		if unclaimedOnly {
-			siblings = filterUnclaimed(siblings)
-		}
+		siblings = filterKeep(siblings, keep)
 		if len(siblings) > 0 {
 			return &NextResult{Task: &siblings[0]}, nil
 		}
@@ -276,11 +282,14 @@ func (s *Storage) GetNextTaskFiltered(unclaimedOnly bool) (*NextResult, error) {
 	return &NextResult{BlockedCount: countBlockedTodos(store.Tasks)}, nil
 }
 
-// filterUnclaimed removes tasks that have an owner
-func filterUnclaimed(tasks []models.Task) []models.Task {
+// filterKeep returns the tasks for which keep returns true, or all tasks when keep is nil
+func filterKeep(tasks []models.Task, keep func(*models.Task) bool) []models.Task {
+	if keep == nil {
+		return tasks
+	}
 	var result []models.Task
 	for i := range tasks {
-		if tasks[i].Owner == nil {
+		if keep(&tasks[i]) {
 			result = append(result, tasks[i])
 		}
 	}
@@ -309,7 +318,7 @@ func getDeepestInProgress(tasks []models.Task) *models.Task {
 	return deepest
 }
 
-// getTodoChildren returns todo tasks that are children of the given task, sorted by created time
+// getTodoChildren returns todo tasks that are children of the given task, in rank order
 func getTodoChildren(tasks []models.Task, parentID string, skipBlocked bool) []models.Task {
 	var children []models.Task
 	for i := range tasks {
@@ -320,13 +329,11 @@ func getTodoChildren(tasks []models.Task, parentID string, skipBlocked bool) []m
 			children = append(children, tasks[i])
 		}
 	}
-	sort.Slice(children, func(i, j int) bool {
-		return children[i].Created.Before(children[j].Created)
-	})
+	models.SortByRank(children)
 	return children
 }
 
-// getTodoSiblings returns todo tasks with the same parent as the given task, sorted by created time
+// getTodoSiblings returns todo tasks with the same parent as the given task, in rank order
 func getTodoSiblings(tasks []models.Task, taskID string, skipBlocked bool) []models.Task {
 	// Find the task to get its parent
 	var targetParent *string
@@ -354,15 +361,12 @@ func getTodoSiblings(tasks []models.Task, taskID string, skipBlocked bool) []mod
 		}
 	}
 
-	// Sort by created time (oldest first)
-	sort.Slice(siblings, func(i, j int) bool {
-		return siblings[i].Created.Before(siblings[j].Created)
-	})
+	models.SortByRank(siblings)
 
 	return siblings
 }
 
-// getRootTodos returns all todo tasks with no parent, sorted by created time
+// getRootTodos returns all todo tasks with no parent, in rank order
 func getRootTodos(tasks []models.Task, skipBlocked bool) []models.Task {
 	var roots []models.Task
 	for i := range tasks {
@@ -373,9 +377,7 @@ func getRootTodos(tasks []models.Task, skipBlocked bool) []models.Task {
 			roots = append(roots, tasks[i])
 		}
 	}
-	sort.Slice(roots, func(i, j int) bool {
-		return roots[i].Created.Before(roots[j].Created)
-	})
+	models.SortByRank(roots)
 	return roots
 }
 
@@ -390,14 +392,14 @@ func countBlockedTodos(tasks []models.Task) int {
 	return count
 }
 
-// isTaskBlocked checks if any task in BlockedBy is not done
+// isTaskBlocked checks if any task in BlockedBy is not closed
 func isTaskBlocked(task *models.Task, allTasks []models.Task) bool {
 	if len(task.BlockedBy) == 0 {
 		return false
 	}
 	for _, blockerID := range task.BlockedBy {
 		blocker := findTask(allTasks, blockerID)
-		if blocker != nil && blocker.Status != models.StatusDone {
+		if blocker != nil && !models.IsClosed(blocker.Status) {
 			return true
 		}
 	}
@@ -414,7 +416,8 @@ func findTask(tasks []models.Task, id string) *models.Task {
 	return nil
 }
 
-// HasUndoneChildren checks recursively if a task has any descendants that are not done
+// HasUndoneChildren checks recursively if a task has any descendants that are
+// not closed (done or cancelled)
 func (s *Storage) HasUndoneChildren(parentID string) (bool, error) {
 	children, err := s.GetChildren(parentID)
 	if err != nil {
@@ -422,7 +425,7 @@ func (s *Storage) HasUndoneChildren(parentID string) (bool, error) {
 	}
 
 	for i := range children {
-		if children[i].Status != models.StatusDone {
+		if !models.IsClosed(children[i].Status) {
 			return true, nil
 		}
 		// Check grandchildren recursively
@@ -444,9 +447,11 @@ func (s *Storage) OrphanChildren(parentID string) error {
 		return err
 	}
 
+	now := time.Now()
 	for i := range store.Tasks {
 		if store.Tasks[i].Parent != nil && *store.Tasks[i].Parent == parentID {
-			store.Tasks[i].Parent = nil
+			store.Tasks[i].SetParent(nil, CurrentActor(), now)
+			store.Tasks[i].Updated = now
 		}
 	}
 
@@ -475,9 +480,7 @@ type LegacyTaskStore struct {
 
 // loadStore reads the tasks.json file
 func (s *Storage) loadStore() (*TaskStore, error) {
-	storePath := filepath.Join(s.rootDir, ClipmDir, TasksFile)
-
-	data, err := os.ReadFile(storePath)
+	data, err := s.readStoreData()
 	if err != nil {
 		if os.IsNotExist(err) {
 			return &TaskStore{Version: "4.0.0", Tasks: []models.Task{}}, nil
@@ -493,22 +496,30 @@ func (s *Storage) loadStore() (*TaskStore, error) {
 		return nil, fmt.Errorf("failed to parse tasks file: %w", err)
 	}
 
-	// If v2.0.0, migrate to v4.0.0 (skip v3)
-	if versionCheck.Version == "2.0.0" {
-		return s.migrateFromV2(data)
+	var store *TaskStore
+	switch versionCheck.Version {
+	case "2.0.0":
+		// Migrate to v4.0.0 (skip v3)
+		store, err = s.migrateFromV2(data)
+	case "3.0.0":
+		// Migrate to v4.0.0
+		store, err = s.migrateFromV3(data)
+	default:
+		store = &TaskStore{}
+		if err = json.Unmarshal(data, store); err != nil {
+			err = fmt.Errorf("failed to parse tasks file: %w", err)
+		}
 	}
-
-	// If v3.0.0, migrate to v4.0.0
-	if versionCheck.Version == "3.0.0" {
-		return s.migrateFromV3(data)
+	if err != nil {
+		return nil, err
 	}
 
-	var store TaskStore
-	if err := json.Unmarshal(data, &store); err != nil {
-		return nil, fmt.Errorf("failed to parse tasks file: %w", err)
+	// Notes written before note IDs existed get IDs on first read
+	for i := range store.Tasks {
+		store.Tasks[i].AssignNoteIDs()
 	}
 
-	return &store, nil
+	return store, nil
 }
 
 // migrateFromV2 migrates from v2.0.0 (int64 IDs) to v3.0.0 (string IDs)
@@ -616,18 +627,12 @@ func (s *Storage) migrateFromV3(data []byte) (*TaskStore, error) {
 
 // saveStore writes the tasks.json file
 func (s *Storage) saveStore(store *TaskStore) error {
-	storePath := filepath.Join(s.rootDir, ClipmDir, TasksFile)
-
 	data, err := json.MarshalIndent(store, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal tasks: %w", err)
 	}
 
-	if err := os.WriteFile(storePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write tasks file: %w", err)
-	}
-
-	return nil
+	return s.writeStoreData(data)
 }
 
 // GetRootDir returns the project root directory
@@ -648,7 +653,7 @@ func (s *Storage) IsBlocked(task *models.Task) (bool, error) {
 
 	for _, blockerID := range task.BlockedBy {
 		blocker := findTask(store.Tasks, blockerID)
-		if blocker != nil && blocker.Status != models.StatusDone {
+		if blocker != nil && !models.IsClosed(blocker.Status) {
 			return true, nil
 		}
 	}
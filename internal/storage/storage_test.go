@@ -906,3 +906,32 @@ func TestGenerateTaskID_Collision(t *testing.T) {
 	// All IDs should be unique
 	assert.Len(t, generated, 100)
 }
+
+func TestGetNextTaskMatching(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "clipm-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	store := NewStorageAt(tmpDir)
+	require.NoError(t, store.Init())
+
+	now := time.Now()
+	root := "aaaa"
+	require.NoError(t, store.SaveTask(&models.Task{ID: "aaaa", Name: "Root", Status: models.StatusInProgress, Created: now, Updated: now}))
+	require.NoError(t, store.SaveTask(&models.Task{ID: "aaab", Name: "First", Status: models.StatusTodo, Parent: &root, Created: now.Add(time.Second), Updated: now}))
+	require.NoError(t, store.SaveTask(&models.Task{ID: "aaac", Name: "Second", Status: models.StatusTodo, Parent: &root, Created: now.Add(2 * time.Second), Updated: now}))
+
+	next, err := store.GetNextTaskMatching(nil)
+	require.NoError(t, err)
+	require.NotNil(t, next.Task)
+	assert.Equal(t, "aaab", next.Task.ID)
+
+	next, err = store.GetNextTaskMatching(func(t *models.Task) bool { return t.Name == "Second" })
+	require.NoError(t, err)
+	require.NotNil(t, next.Task)
+	assert.Equal(t, "aaac", next.Task.ID)
+
+	next, err = store.GetNextTaskMatching(func(*models.Task) bool { return false })
+	require.NoError(t, err)
+	assert.Nil(t, next.Task)
+}
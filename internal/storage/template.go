@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// TemplatesDir holds task templates (*.yaml) inside the .clipm directory
+const TemplatesDir = "templates"
+
+// ErrTemplateNotFound is returned when no template file matches the requested name
+var ErrTemplateNotFound error = models.Errorf(models.CodeNotFound, "template not found")
+
+// Template is a reusable task subtree read from .clipm/templates/<name>.yaml.
+// String values in Tasks may reference variables as {{.var}}.
+type Template struct {
+	Name        string        `json:"name" yaml:"-"`
+	Description string        `json:"description,omitempty" yaml:"description,omitempty"`
+	Vars        []TemplateVar `json:"vars,omitempty" yaml:"vars,omitempty"`
+	Tasks       []PlanNode    `json:"tasks" yaml:"tasks"`
+}
+
+// TemplateVar declares a variable a template accepts
+type TemplateVar struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Default     string `json:"default,omitempty" yaml:"default,omitempty"`
+	Required    bool   `json:"required,omitempty" yaml:"required,omitempty"`
+}
+
+// ListTemplates loads every template in .clipm/templates, sorted by name
+func (s *Storage) ListTemplates() ([]Template, error) {
+	dir := filepath.Join(s.rootDir, ClipmDir, TemplatesDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Template{}, nil
+		}
+		return nil, fmt.Errorf("failed to read templates directory: %w", err)
+	}
+
+	templates := []Template{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		tmpl, err := s.LoadTemplate(strings.TrimSuffix(entry.Name(), ext))
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *tmpl)
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// LoadTemplate reads and parses the named template
+func (s *Storage) LoadTemplate(name string) (*Template, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return nil, models.Errorf(models.CodeInvalidArgument, "invalid template name: %q", name)
+	}
+
+	dir := filepath.Join(s.rootDir, ClipmDir, TemplatesDir)
+	var data []byte
+	var err error
+	for _, ext := range []string{".yaml", ".yml"} {
+		data, err = os.ReadFile(filepath.Join(dir, name+ext))
+		if err == nil || !os.IsNotExist(err) {
+			break
+		}
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+
+	var tmpl Template
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&tmpl); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	tmpl.Name = name
+
+	if len(tmpl.Tasks) == 0 {
+		return nil, models.Errorf(models.CodeInvalidArgument, "template %s defines no tasks", name)
+	}
+	seen := make(map[string]bool)
+	for _, v := range tmpl.Vars {
+		if v.Name == "" {
+			return nil, models.Errorf(models.CodeInvalidArgument, "template %s: variable with no name", name)
+		}
+		if seen[v.Name] {
+			return nil, models.Errorf(models.CodeInvalidArgument, "template %s: duplicate variable %q", name, v.Name)
+		}
+		seen[v.Name] = true
+	}
+
+	return &tmpl, nil
+}
+
+// Expand substitutes vars into the template and returns the resulting plan.
+// Unknown variables, missing required variables and references to undeclared
+// variables are errors.
+func (t *Template) Expand(vars map[string]string) ([]PlanNode, error) {
+	values := make(map[string]string)
+	declared := make(map[string]bool)
+	for _, v := range t.Vars {
+		declared[v.Name] = true
+		if val, ok := vars[v.Name]; ok {
+			values[v.Name] = val
+		} else if v.Required {
+			return nil, models.Errorf(models.CodeInvalidArgument, "template %s requires variable %q (use --var %s=...)", t.Name, v.Name, v.Name)
+		} else {
+			values[v.Name] = v.Default
+		}
+	}
+	for name := range vars {
+		if !declared[name] {
+			return nil, models.Errorf(models.CodeInvalidArgument, "template %s has no variable %q", t.Name, name)
+		}
+	}
+
+	e := &expander{name: t.Name, values: values}
+	nodes := e.nodes(t.Tasks)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return nodes, nil
+}
+
+// expander renders template strings, remembering the first error
+type expander struct {
+	name   string
+	values map[string]string
+	err    error
+}
+
+func (e *expander) nodes(in []PlanNode) []PlanNode {
+	if in == nil {
+		return nil
+	}
+	out := make([]PlanNode, len(in))
+	for i, n := range in {
+		out[i] = PlanNode{
+			Key:         e.str(n.Key),
+			Name:        e.str(n.Name),
+			Description: e.str(n.Description),
+			Action:      e.str(n.Action),
+			Verify:      e.str(n.Verify),
+			Result:      e.str(n.Result),
+			VerifyCmd:   e.str(n.VerifyCmd),
+			Checklist:   e.strs(n.Checklist),
+			Owner:       e.str(n.Owner),
+			BlockedBy:   e.strs(n.BlockedBy),
+			Children:    e.nodes(n.Children),
+		}
+		if n.Fields != nil {
+			out[i].Fields = make(map[string]any, len(n.Fields))
+			for k, v := range n.Fields {
+				if s, ok := v.(string); ok {
+					v = e.str(s)
+				}
+				out[i].Fields[k] = v
+			}
+		}
+		for _, note := range n.Notes {
+			out[i].Notes = append(out[i].Notes, PlanNote{
+				Content: e.str(note.Content),
+				Kind:    note.Kind,
+				Author:  e.str(note.Author),
+			})
+		}
+	}
+	return out
+}
+
+func (e *expander) strs(in []string) []string {
+	if in == nil {
+		return nil
+	}
+	out := make([]string, len(in))
+	for i, s := range in {
+		out[i] = e.str(s)
+	}
+	return out
+}
+
+func (e *expander) str(s string) string {
+	if e.err != nil || !strings.Contains(s, "{{") {
+		return s
+	}
+	tmpl, err := template.New(e.name).Option("missingkey=error").Parse(s)
+	if err != nil {
+		e.err = fmt.Errorf("template %s: %w", e.name, err)
+		return s
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, e.values); err != nil {
+		e.err = fmt.Errorf("template %s: %w", e.name, err)
+		return s
+	}
+	return buf.String()
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const featureTemplate = `description: Standard feature breakdown
+vars:
+  - name: name
+    required: true
+  - name: owner
+    default: nobody
+tasks:
+  - key: feature
+    name: "Feature: {{.name}}"
+    children:
+      - key: design
+        name: Design {{.name}}
+        checklist: ["{{.name}} API agreed"]
+      - key: impl
+        name: Implement {{.name}}
+        blockedBy: [design]
+        fields:
+          assignee: "{{.owner}}"
+`
+
+func writeTemplate(t *testing.T, dir, name, content string) {
+	t.Helper()
+	tmplDir := filepath.Join(dir, ClipmDir, TemplatesDir)
+	require.NoError(t, os.MkdirAll(tmplDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmplDir, name), []byte(content), 0644))
+}
+
+func TestListTemplates(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	templates, err := store.ListTemplates()
+	require.NoError(t, err)
+	assert.Empty(t, templates)
+
+	writeTemplate(t, dir, "feature.yaml", featureTemplate)
+	writeTemplate(t, dir, "bug.yml", "tasks:\n  - name: Fix it\n")
+	writeTemplate(t, dir, "README.md", "not a template")
+
+	templates, err = store.ListTemplates()
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	assert.Equal(t, "bug", templates[0].Name)
+	assert.Equal(t, "feature", templates[1].Name)
+	assert.Equal(t, "Standard feature breakdown", templates[1].Description)
+}
+
+func TestLoadTemplate_Errors(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	_, err := store.LoadTemplate("missing")
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+
+	_, err = store.LoadTemplate("../tasks")
+	assert.Error(t, err)
+
+	writeTemplate(t, dir, "empty.yaml", "description: nothing\n")
+	_, err = store.LoadTemplate("empty")
+	assert.ErrorContains(t, err, "defines no tasks")
+
+	writeTemplate(t, dir, "typo.yaml", "taks:\n  - name: A\n")
+	_, err = store.LoadTemplate("typo")
+	assert.ErrorContains(t, err, "failed to parse")
+}
+
+func TestTemplateExpand(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+	writeTemplate(t, dir, "feature.yaml", featureTemplate)
+
+	tmpl, err := store.LoadTemplate("feature")
+	require.NoError(t, err)
+
+	nodes, err := tmpl.Expand(map[string]string{"name": "login"})
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	assert.Equal(t, "Feature: login", nodes[0].Name)
+	require.Len(t, nodes[0].Children, 2)
+	assert.Equal(t, "Design login", nodes[0].Children[0].Name)
+	assert.Equal(t, []string{"login API agreed"}, nodes[0].Children[0].Checklist)
+	assert.Equal(t, []string{"design"}, nodes[0].Children[1].BlockedBy)
+	assert.Equal(t, "nobody", nodes[0].Children[1].Fields["assignee"])
+
+	// The template itself is left untouched
+	assert.Equal(t, "Feature: {{.name}}", tmpl.Tasks[0].Name)
+
+	_, err = tmpl.Expand(map[string]string{})
+	assert.ErrorContains(t, err, `requires variable "name"`)
+
+	_, err = tmpl.Expand(map[string]string{"name": "x", "colour": "red"})
+	assert.ErrorContains(t, err, `no variable "colour"`)
+}
+
+func TestTemplateExpand_UndeclaredReference(t *testing.T) {
+	tmpl := &Template{Name: "t", Tasks: []PlanNode{{Name: "Do {{.thing}}"}}}
+	_, err := tmpl.Expand(nil)
+	assert.Error(t, err)
+}
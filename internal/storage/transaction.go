@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+)
+
+// LockFile guards tasks.json while a transaction is being committed
+const LockFile = "tasks.lock"
+
+// Lock timing: how long to wait for another process, and when a leftover lock is considered stale.
+const (
+	lockTimeout    = 5 * time.Second
+	lockRetry      = 50 * time.Millisecond
+	lockStaleAfter = 30 * time.Second
+)
+
+// ErrLocked is returned when another clipm process holds the store lock for too long
+var ErrLocked error = models.Errorf(models.CodeLocked, "task store is locked by another clipm process")
+
+// txState is the in-memory copy of tasks.json used inside a transaction
+type txState struct {
+	data    []byte
+	exists  bool
+	changed bool
+}
+
+// Transaction runs fn against an in-memory copy of the store and writes the
+// result to tasks.json in a single write if fn succeeds. Every Storage method
+// works on tx as usual; nothing reaches disk when fn returns an error.
+// The store is locked for the duration so concurrent transactions serialize.
+func (s *Storage) Transaction(fn func(tx *Storage) error) error {
+	if s.tx != nil {
+		// Already inside a transaction: join it
+		return fn(s)
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tx, err := s.begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if !tx.tx.changed {
+		return nil
+	}
+	return s.writeStoreData(tx.tx.data)
+}
+
+// DryRun runs fn like Transaction but always discards the changes
+func (s *Storage) DryRun(fn func(tx *Storage) error) error {
+	tx, err := s.begin()
+	if err != nil {
+		return err
+	}
+	return fn(tx)
+}
+
+func (s *Storage) begin() (*Storage, error) {
+	data, err := s.readStoreData()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read tasks file: %w", err)
+	}
+	state := &txState{data: data, exists: err == nil}
+	return &Storage{rootDir: s.rootDir, tx: state}, nil
+}
+
+// readStoreData returns the raw store, from the transaction if one is active
+func (s *Storage) readStoreData() ([]byte, error) {
+	if s.tx != nil {
+		if !s.tx.exists {
+			return nil, os.ErrNotExist
+		}
+		return s.tx.data, nil
+	}
+	return os.ReadFile(filepath.Join(s.rootDir, ClipmDir, TasksFile))
+}
+
+// writeStoreData replaces the raw store, in the transaction if one is active
+func (s *Storage) writeStoreData(data []byte) error {
+	if s.tx != nil {
+		s.tx.data = data
+		s.tx.exists = true
+		s.tx.changed = true
+		return nil
+	}
+	storePath := filepath.Join(s.rootDir, ClipmDir, TasksFile)
+	if err := os.WriteFile(storePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write tasks file: %w", err)
+	}
+	return nil
+}
+
+// lock creates the lock file, waiting for other holders and clearing stale locks
+func (s *Storage) lock() (func(), error) {
+	lockPath := filepath.Join(s.rootDir, ClipmDir, LockFile)
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+			_ = f.Close()
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock task store: %w", err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrLocked
+		}
+		time.Sleep(lockRetry)
+	}
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/simonspoon/clipm/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTask(id string) *models.Task {
+	now := time.Now()
+	return &models.Task{ID: id, Name: "Task " + id, Status: models.StatusTodo, Created: now, Updated: now}
+}
+
+func TestTransaction_Commit(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	err := store.Transaction(func(tx *Storage) error {
+		require.NoError(t, tx.SaveTask(newTestTask("aaaa")))
+		require.NoError(t, tx.SaveTask(newTestTask("bbbb")))
+
+		// Changes are visible inside the transaction but not outside it yet
+		_, err := tx.LoadTask("aaaa")
+		require.NoError(t, err)
+		_, err = store.LoadTask("aaaa")
+		assert.ErrorIs(t, err, ErrTaskNotFound)
+		return nil
+	})
+	require.NoError(t, err)
+
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+
+	_, err = os.Stat(filepath.Join(dir, ClipmDir, LockFile))
+	assert.True(t, os.IsNotExist(err), "lock file should be removed")
+}
+
+func TestTransaction_Rollback(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	boom := errors.New("boom")
+	err := store.Transaction(func(tx *Storage) error {
+		require.NoError(t, tx.SaveTask(newTestTask("aaaa")))
+		return boom
+	})
+	assert.ErrorIs(t, err, boom)
+
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
+func TestTransaction_Nested(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	err := store.Transaction(func(tx *Storage) error {
+		require.NoError(t, tx.SaveTask(newTestTask("aaaa")))
+		return tx.Transaction(func(inner *Storage) error {
+			_, err := inner.LoadTask("aaaa")
+			require.NoError(t, err)
+			return inner.SaveTask(newTestTask("bbbb"))
+		})
+	})
+	require.NoError(t, err)
+
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
+
+func TestDryRun(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	err := store.DryRun(func(tx *Storage) error {
+		require.NoError(t, tx.SaveTask(newTestTask("aaaa")))
+		tasks, err := tx.LoadAll()
+		require.NoError(t, err)
+		assert.Len(t, tasks, 1)
+		return nil
+	})
+	require.NoError(t, err)
+
+	tasks, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
+func TestTransaction_Locked(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStorageAt(dir)
+	require.NoError(t, store.Init())
+
+	lockPath := filepath.Join(dir, ClipmDir, LockFile)
+	require.NoError(t, os.WriteFile(lockPath, []byte("1\n"), 0644))
+
+	// A fresh lock held by someone else times out
+	start := time.Now()
+	err := store.Transaction(func(tx *Storage) error { return nil })
+	assert.ErrorIs(t, err, ErrLocked)
+	assert.GreaterOrEqual(t, time.Since(start), lockTimeout)
+
+	// A stale lock is cleared
+	old := time.Now().Add(-2 * lockStaleAfter)
+	require.NoError(t, os.Chtimes(lockPath, old, old))
+	err = store.Transaction(func(tx *Storage) error { return nil })
+	assert.NoError(t, err)
+}